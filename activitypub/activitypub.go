@@ -0,0 +1,204 @@
+// Package activitypub converts tweets into ActivityStreams objects compatible with Mastodon
+// and other Fediverse servers, for bridges mirroring X accounts
+package activitypub
+
+import (
+	"html"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// ActivityStreams constants
+const (
+	Context = "https://www.w3.org/ns/activitystreams"
+	Public  = "https://www.w3.org/ns/activitystreams#Public"
+)
+
+// Note is an ActivityStreams Note object
+type Note struct {
+	Context      string            `json:"@context,omitempty"`
+	ID           string            `json:"id"`
+	Type         string            `json:"type"`
+	AttributedTo string            `json:"attributedTo"`
+	Content      string            `json:"content"`
+	ContentMap   map[string]string `json:"contentMap,omitempty"`
+	Published    string            `json:"published,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	To           []string          `json:"to"`
+	Cc           []string          `json:"cc,omitempty"`
+	InReplyTo    string            `json:"inReplyTo,omitempty"`
+	Attachment   []Attachment      `json:"attachment,omitempty"`
+	Tag          []Tag             `json:"tag,omitempty"`
+}
+
+// Attachment is a media attachment of Note
+type Attachment struct {
+	Type      string `json:"type"`
+	MediaType string `json:"mediaType,omitempty"`
+	URL       string `json:"url"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Tag is a Hashtag or Mention of Note
+type Tag struct {
+	Type string `json:"type"`
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+// Create is an ActivityStreams Create activity delivering Note to followers
+type Create struct {
+	Context   string   `json:"@context"`
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Actor     string   `json:"actor"`
+	Published string   `json:"published,omitempty"`
+	To        []string `json:"to"`
+	Cc        []string `json:"cc,omitempty"`
+	Object    *Note    `json:"object"`
+}
+
+// Bridge maps X accounts to actors of a bridge server with Mastodon-like URL layout:
+// actors at <BaseURL>/users/<username>, notes at <BaseURL>/users/<username>/statuses/<tweet ID>
+// and hashtags at <BaseURL>/tags/<tag>
+type Bridge struct {
+	BaseURL string // Base URL of the bridge server, e.g. "https://bridge.example"
+}
+
+// ActorURL returns ID of actor mirroring X user
+func (b Bridge) ActorURL(username string) string {
+	return strings.TrimSuffix(b.BaseURL, "/") + "/users/" + url.PathEscape(strings.ToLower(username))
+}
+
+// NoteURL returns ID of note mirroring tweet
+func (b Bridge) NoteURL(username, tweetID string) string {
+	return b.ActorURL(username) + "/statuses/" + url.PathEscape(tweetID)
+}
+
+// Note converts tweet into public Note attributed to the actor of its author.
+// Retweets are converted into notes of the original author.
+func (b Bridge) Note(tweet *twittertimeline.Tweet) *Note {
+	actor := b.ActorURL(tweet.Username)
+	note := &Note{
+		Context:      Context,
+		ID:           b.NoteURL(tweet.Username, tweet.ID),
+		Type:         "Note",
+		AttributedTo: actor,
+		Content:      noteContent(tweet),
+		URL:          tweet.PermanentURL,
+		To:           []string{Public},
+		Cc:           []string{actor + "/followers"},
+	}
+
+	if createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt); err == nil {
+		note.Published = createdAt.UTC().Format(time.RFC3339)
+	}
+	if lang := tweetLang(tweet); lang != "" {
+		note.ContentMap = map[string]string{lang: note.Content}
+	}
+	if tweet.InReplyToID != "" && tweet.InReplyToUsername != "" {
+		note.InReplyTo = b.NoteURL(tweet.InReplyToUsername, tweet.InReplyToID)
+	}
+
+	for _, media := range tweet.Media {
+		note.Attachment = append(note.Attachment, Attachment{
+			Type:      "Document",
+			MediaType: mediaType(media),
+			URL:       media.URL,
+		})
+	}
+	// Tweets from fallback backends may have images without media details
+	if len(tweet.Media) == 0 {
+		for _, image := range tweet.Images {
+			note.Attachment = append(note.Attachment, Attachment{
+				Type:      "Document",
+				MediaType: mediaType(twittertimeline.Media{Type: twittertimeline.MediaPhoto, URL: image}),
+				URL:       image,
+			})
+		}
+	}
+
+	for _, hashtag := range tweet.Hashtags {
+		note.Tag = append(note.Tag, Tag{
+			Type: "Hashtag",
+			Href: strings.TrimSuffix(b.BaseURL, "/") + "/tags/" + url.PathEscape(strings.ToLower(hashtag)),
+			Name: "#" + hashtag,
+		})
+	}
+	for _, mention := range tweet.Mentions {
+		note.Tag = append(note.Tag, Tag{
+			Type: "Mention",
+			Href: b.ActorURL(mention),
+			Name: "@" + mention,
+		})
+	}
+
+	return note
+}
+
+// Create wraps Note of tweet into Create activity
+func (b Bridge) Create(tweet *twittertimeline.Tweet) *Create {
+	note := b.Note(tweet)
+	note.Context = ""
+	return &Create{
+		Context:   Context,
+		ID:        note.ID + "/activity",
+		Type:      "Create",
+		Actor:     note.AttributedTo,
+		Published: note.Published,
+		To:        note.To,
+		Cc:        note.Cc,
+		Object:    note,
+	}
+}
+
+// noteContent returns HTML content of note with a link to the quoted tweet
+func noteContent(tweet *twittertimeline.Tweet) string {
+	content := tweet.HTML
+	if content == "" {
+		content = strings.ReplaceAll(html.EscapeString(tweet.Text), "\n", "<br>")
+	}
+	if !strings.HasPrefix(content, "<p>") {
+		content = "<p>" + content + "</p>"
+	}
+
+	if quoted := tweet.QuotedTweet; quoted != nil && quoted.PermanentURL != "" {
+		link := html.EscapeString(quoted.PermanentURL)
+		content += `<p>RE: <a href="` + link + `">` + link + `</a></p>`
+	}
+	return content
+}
+
+// tweetLang returns language of tweet or empty string if it is undetermined
+func tweetLang(tweet *twittertimeline.Tweet) string {
+	lang := tweet.Lang
+	if lang == "" || lang == "und" {
+		lang = tweet.DetectedLang
+	}
+	if lang == "und" {
+		return ""
+	}
+	return lang
+}
+
+// mediaType returns MIME type of media by file extension and media type
+func mediaType(media twittertimeline.Media) string {
+	if u, err := url.Parse(media.URL); err == nil {
+		if mimeType := mime.TypeByExtension(path.Ext(u.Path)); mimeType != "" {
+			return mimeType
+		}
+	}
+	switch media.Type {
+	case twittertimeline.MediaVideo, twittertimeline.MediaAnimatedGIF:
+		return "video/mp4"
+	case twittertimeline.MediaAudio:
+		return "audio/mp4"
+	default:
+		return "image/jpeg"
+	}
+}
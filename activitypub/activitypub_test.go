@@ -0,0 +1,106 @@
+package activitypub
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+func TestNote(t *testing.T) {
+	bridge := Bridge{BaseURL: "https://bridge.example/"}
+	tweet := &twittertimeline.Tweet{
+		ID:                "2",
+		Text:              "Hello @Bob #Go",
+		HTML:              `Hello <a href="https://x.com/Bob">@Bob</a> <a href="https://x.com/hashtag/Go">#Go</a>`,
+		Lang:              "und",
+		DetectedLang:      "en",
+		CreatedAt:         "Mon Jan 01 09:30:00 +0300 2024",
+		PermanentURL:      "https://x.com/Alice/status/2",
+		Username:          "Alice",
+		InReplyToID:       "1",
+		InReplyToUsername: "bob",
+		Hashtags:          []string{"Go"},
+		Mentions:          []string{"Bob"},
+		Media: []twittertimeline.Media{
+			{Type: twittertimeline.MediaPhoto, URL: "https://pbs.twimg.com/media/a.png"},
+			{Type: twittertimeline.MediaVideo, URL: "https://video.twimg.com/v/1?tag=12"},
+		},
+		QuotedTweet: &twittertimeline.QuotedTweet{Tweet: twittertimeline.Tweet{PermanentURL: "https://x.com/carol/status/3"}},
+	}
+
+	note := bridge.Note(tweet)
+	expected := &Note{
+		Context:      Context,
+		ID:           "https://bridge.example/users/alice/statuses/2",
+		Type:         "Note",
+		AttributedTo: "https://bridge.example/users/alice",
+		Content: `<p>Hello <a href="https://x.com/Bob">@Bob</a> <a href="https://x.com/hashtag/Go">#Go</a></p>` +
+			`<p>RE: <a href="https://x.com/carol/status/3">https://x.com/carol/status/3</a></p>`,
+		Published: "2024-01-01T06:30:00Z",
+		URL:       "https://x.com/Alice/status/2",
+		To:        []string{Public},
+		Cc:        []string{"https://bridge.example/users/alice/followers"},
+		InReplyTo: "https://bridge.example/users/bob/statuses/1",
+		Attachment: []Attachment{
+			{Type: "Document", MediaType: "image/png", URL: "https://pbs.twimg.com/media/a.png"},
+			{Type: "Document", MediaType: "video/mp4", URL: "https://video.twimg.com/v/1?tag=12"},
+		},
+		Tag: []Tag{
+			{Type: "Hashtag", Href: "https://bridge.example/tags/go", Name: "#Go"},
+			{Type: "Mention", Href: "https://bridge.example/users/bob", Name: "@Bob"},
+		},
+	}
+	expected.ContentMap = map[string]string{"en": expected.Content}
+	if !reflect.DeepEqual(note, expected) {
+		got, _ := json.MarshalIndent(note, "", "  ")
+		t.Errorf("Unexpected note:\n%s", got)
+	}
+}
+
+func TestNoteFallbacks(t *testing.T) {
+	bridge := Bridge{BaseURL: "https://bridge.example"}
+	note := bridge.Note(&twittertimeline.Tweet{
+		ID:        "1",
+		Text:      "a < b\nc",
+		Lang:      "und",
+		CreatedAt: "invalid",
+		Username:  "alice",
+		Images:    []string{"https://pbs.twimg.com/media/a?format=jpg&name=large"},
+	})
+
+	if note.Content != "<p>a &lt; b<br>c</p>" {
+		t.Errorf("Unexpected content: %s", note.Content)
+	}
+	if note.Published != "" || note.ContentMap != nil || note.InReplyTo != "" {
+		t.Errorf("Expected no published time, language and reply, got %+v", note)
+	}
+	if len(note.Attachment) != 1 || note.Attachment[0].MediaType != "image/jpeg" {
+		t.Errorf("Expected image attachment, got %+v", note.Attachment)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	bridge := Bridge{BaseURL: "https://bridge.example"}
+	tweet := &twittertimeline.Tweet{ID: "1", Text: "Hi", Username: "alice", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RubyDate)}
+
+	activity := bridge.Create(tweet)
+	if activity.Type != "Create" || activity.ID != "https://bridge.example/users/alice/statuses/1/activity" ||
+		activity.Actor != "https://bridge.example/users/alice" || activity.Published != "2024-01-01T00:00:00Z" {
+		t.Errorf("Unexpected activity: %+v", activity)
+	}
+	if activity.Object.Context != "" || activity.Object.ID != "https://bridge.example/users/alice/statuses/1" {
+		t.Errorf("Unexpected object: %+v", activity.Object)
+	}
+	data, err := json.Marshal(activity)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded map[string]any
+	json.Unmarshal(data, &decoded)
+	if decoded["@context"] != Context || decoded["object"].(map[string]any)["@context"] != nil {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
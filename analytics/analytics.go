@@ -0,0 +1,96 @@
+// Package analytics computes engagement and posting cadence summaries of fetched tweets
+package analytics
+
+import (
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Summary is an engagement and posting cadence summary of account tweets
+type Summary struct {
+	Tweets int // Number of summarized tweets, retweets are not counted
+
+	// Totals
+	Likes    int
+	Retweets int
+	Replies  int
+	Views    int
+
+	// Averages per tweet
+	AvgLikes    float64
+	AvgRetweets float64
+	AvgReplies  float64
+	AvgViews    float64
+
+	// Average likes, retweets and replies per tweet divided by followers count,
+	// zero if followers count is unknown
+	EngagementRate float64
+
+	// Posting cadence
+	ByHour    [24]int // Tweets per hour of day
+	ByWeekday [7]int  // Tweets per day of week, indexed by time.Weekday
+	First     time.Time
+	Last      time.Time
+}
+
+// Summarize computes summary of tweets with cadence histograms in UTC.
+// Followers is the account followers count, e.g. User.Followers, used for engagement rate.
+func Summarize(tweets []twittertimeline.Tweet, followers int) Summary {
+	return SummarizeIn(tweets, followers, time.UTC)
+}
+
+// SummarizeIn computes summary of tweets with cadence histograms in the given location.
+// Retweets are skipped, since their counters and timestamps belong to the original tweet.
+func SummarizeIn(tweets []twittertimeline.Tweet, followers int, loc *time.Location) Summary {
+	var s Summary
+	for i := range tweets {
+		tweet := &tweets[i]
+		if tweet.IsRetweet {
+			continue
+		}
+
+		s.Tweets++
+		s.Likes += tweet.Likes
+		s.Retweets += tweet.Retweets
+		s.Replies += tweet.Replies
+		s.Views += tweet.Views
+
+		createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt)
+		if err != nil {
+			continue
+		}
+		createdAt = createdAt.In(loc)
+		s.ByHour[createdAt.Hour()]++
+		s.ByWeekday[createdAt.Weekday()]++
+		if s.First.IsZero() || createdAt.Before(s.First) {
+			s.First = createdAt
+		}
+		if createdAt.After(s.Last) {
+			s.Last = createdAt
+		}
+	}
+
+	if s.Tweets == 0 {
+		return s
+	}
+	n := float64(s.Tweets)
+	s.AvgLikes = float64(s.Likes) / n
+	s.AvgRetweets = float64(s.Retweets) / n
+	s.AvgReplies = float64(s.Replies) / n
+	s.AvgViews = float64(s.Views) / n
+	if followers > 0 {
+		s.EngagementRate = (s.AvgLikes + s.AvgRetweets + s.AvgReplies) / float64(followers)
+	}
+
+	return s
+}
+
+// TweetsPerDay returns average number of tweets per day between the first and the last tweet
+func (s Summary) TweetsPerDay() float64 {
+	days := s.Last.Sub(s.First).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	return float64(s.Tweets) / days
+}
@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+func TestSummarize(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{ID: "1", CreatedAt: "Mon Jan 01 09:30:00 +0000 2024", Likes: 10, Retweets: 2, Replies: 1, Views: 100},
+		{ID: "2", CreatedAt: "Mon Jan 01 09:45:00 +0000 2024", Likes: 20, Retweets: 4, Replies: 3, Views: 300},
+		{ID: "3", CreatedAt: "Wed Jan 03 23:10:00 +0000 2024", Likes: 0, Retweets: 0, Replies: 2, Views: 200},
+		{ID: "4", CreatedAt: "Thu Jan 04 12:00:00 +0000 2024", Likes: 1000, IsRetweet: true},
+	}
+
+	s := Summarize(tweets, 100)
+	if s.Tweets != 3 || s.Likes != 30 || s.Retweets != 6 || s.Replies != 6 || s.Views != 600 {
+		t.Errorf("Unexpected totals: %+v", s)
+	}
+	if s.AvgLikes != 10 || s.AvgRetweets != 2 || s.AvgReplies != 2 || s.AvgViews != 200 {
+		t.Errorf("Unexpected averages: %+v", s)
+	}
+	if math.Abs(s.EngagementRate-0.14) > 1e-9 {
+		t.Errorf("Expected engagement rate 0.14, got %v", s.EngagementRate)
+	}
+	if s.ByHour[9] != 2 || s.ByHour[23] != 1 || s.ByWeekday[time.Monday] != 2 || s.ByWeekday[time.Wednesday] != 1 {
+		t.Errorf("Unexpected histograms: %v %v", s.ByHour, s.ByWeekday)
+	}
+	if s.First.Day() != 1 || s.Last.Day() != 3 {
+		t.Errorf("Unexpected range: %v - %v", s.First, s.Last)
+	}
+
+	// Late evening in UTC is the next morning in Tokyo
+	tokyo := time.FixedZone("JST", 9*60*60)
+	s = SummarizeIn(tweets, 0, tokyo)
+	if s.ByHour[8] != 1 || s.ByWeekday[time.Thursday] != 1 || s.EngagementRate != 0 {
+		t.Errorf("Unexpected summary in location: %v %v %v", s.ByHour, s.ByWeekday, s.EngagementRate)
+	}
+
+	if s := Summarize(nil, 100); s.Tweets != 0 || s.AvgLikes != 0 || s.TweetsPerDay() != 0 {
+		t.Errorf("Unexpected summary of no tweets: %+v", s)
+	}
+}
+
+func TestTopTweets(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{ID: "1", Likes: 5, Retweets: 9, Replies: 0, Views: 10},
+		{ID: "2", Likes: 10, Retweets: 1, Replies: 1, Views: 30},
+		{ID: "3", Likes: 5, Retweets: 0, Replies: 20, Views: 20},
+		{ID: "4", Likes: 100, Retweets: 100, Views: 1000, IsRetweet: true},
+	}
+
+	tests := []struct {
+		metric   Metric
+		n        int
+		expected []string
+	}{
+		{ByLikes, 2, []string{"2", "1"}},
+		{ByLikes, 0, []string{"2", "1", "3"}},
+		{ByRetweets, 1, []string{"1"}},
+		{ByViews, 10, []string{"2", "3", "1"}},
+		{ByEngagement, 3, []string{"3", "1", "2"}},
+	}
+	for _, tt := range tests {
+		var ids []string
+		for _, tweet := range TopTweets(tweets, tt.n, tt.metric) {
+			ids = append(ids, tweet.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("TopTweets(%d, %d) = %v, want %v", tt.n, tt.metric, ids, tt.expected)
+		}
+	}
+
+	if tweets[0].ID != "1" || tweets[1].ID != "2" {
+		t.Error("TopTweets modified input slice")
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{Hashtags: []string{"Go", "golang", "go"}, Mentions: []string{"Alice"},
+			URLs: []twittertimeline.URL{{Expanded: "https://www.github.com/a"}, {Expanded: "https://go.dev/"}}},
+		{Hashtags: []string{"golang", "Go"}, Mentions: []string{"alice", "bob"},
+			URLs: []twittertimeline.URL{{Expanded: "https://github.com/b"}}},
+		{Hashtags: []string{"rust"}, URLs: []twittertimeline.URL{{Expanded: "not a url"}}},
+	}
+
+	if got, want := Hashtags(tweets), []Count{{"go", 2}, {"golang", 2}, {"rust", 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Hashtags() = %v, want %v", got, want)
+	}
+	if got, want := Mentions(tweets), []Count{{"alice", 2}, {"bob", 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Mentions() = %v, want %v", got, want)
+	}
+	if got, want := Domains(tweets), []Count{{"github.com", 2}, {"go.dev", 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Domains() = %v, want %v", got, want)
+	}
+	if got, want := HashtagPairs(tweets), []Pair{{"go", "golang", 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HashtagPairs() = %v, want %v", got, want)
+	}
+	if tweets[0].Hashtags[0] != "Go" || len(tweets[0].Hashtags) != 3 {
+		t.Errorf("Input tweets modified: %v", tweets[0].Hashtags)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{ID: "1", Text: "GIVEAWAY! Follow and retweet to win 1000$ https://t.co/aaa"},
+		{ID: "2", Text: "Just shipped a new release"},
+		{ID: "3", Text: "giveaway follow and retweet to win 1000 https://t.co/bbb @friend"},
+		{ID: "4", Text: "Giveaway: follow, like and retweet to win 1000$"},
+		{ID: "5", Text: "GIVEAWAY! Follow and retweet to win 1000$", IsRetweet: true},
+		{ID: "6", Text: "Just shipped a new release!!!"},
+		{ID: "7", Text: "https://t.co/ccc"},
+		{ID: "8", Text: "https://t.co/ddd"},
+	}
+
+	groupIDs := func(groups [][]twittertimeline.Tweet) [][]string {
+		var ids [][]string
+		for _, group := range groups {
+			var groupIDs []string
+			for _, tweet := range group {
+				groupIDs = append(groupIDs, tweet.ID)
+			}
+			ids = append(ids, groupIDs)
+		}
+		return ids
+	}
+
+	if got, want := groupIDs(Duplicates(tweets, 1)), [][]string{{"1", "3"}, {"2", "6"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Duplicates(1) = %v, want %v", got, want)
+	}
+	if got, want := groupIDs(Duplicates(tweets, 0.8)), [][]string{{"1", "3", "4"}, {"2", "6"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Duplicates(0.8) = %v, want %v", got, want)
+	}
+}
+
+func TestDomainsCited(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{URLs: []twittertimeline.URL{{Expanded: "https://www.bbc.co.uk/news"}, {Expanded: "https://news.bbc.co.uk/sport"}}},
+		{URLs: []twittertimeline.URL{{Expanded: "https://blog.golang.org/"}, {Expanded: "https://bbc.co.uk/"}}},
+	}
+	if got, want := DomainsCited(tweets), []Count{{"bbc.co.uk", 2}, {"golang.org", 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DomainsCited() = %v, want %v", got, want)
+	}
+}
+
+func TestPostingHeatmap(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{CreatedAt: "Mon Jan 01 09:30:00 +0000 2024"},
+		{CreatedAt: "Mon Jan 08 09:10:00 +0000 2024"},
+		{CreatedAt: "Mon Jan 15 09:50:00 +0000 2024"},
+		{CreatedAt: "Mon Jan 22 09:00:00 +0000 2024"},
+		{CreatedAt: "Sun Jan 07 23:30:00 +0000 2024"},
+		{CreatedAt: "Sun Jan 07 12:00:00 +0000 2024", IsRetweet: true},
+		{CreatedAt: "invalid"},
+	}
+
+	heatmap := PostingHeatmap(tweets, nil)
+	if heatmap[time.Monday][9] != 4 || heatmap[time.Sunday][23] != 1 || heatmap[time.Sunday][12] != 0 || heatmap.Max() != 4 {
+		t.Errorf("Unexpected heatmap: %v", heatmap)
+	}
+
+	// Sunday late evening in UTC is Monday morning in Tokyo
+	tokyo := PostingHeatmap(tweets, time.FixedZone("JST", 9*60*60))
+	if tokyo[time.Monday][8] != 1 || tokyo[time.Monday][18] != 4 {
+		t.Errorf("Unexpected heatmap in location: %v", tokyo)
+	}
+
+	lines := strings.Split(heatmap.String(), "\n")
+	if len(lines) != 9 || !strings.HasPrefix(lines[1], "Mon ") || !strings.HasPrefix(lines[7], "Sun ") {
+		t.Fatalf("Unexpected heatmap rendering:\n%s", heatmap)
+	}
+	if monday := []rune(lines[1]); monday[4+9] != '█' || monday[4+8] != ' ' {
+		t.Errorf("Unexpected Monday row: %q", lines[1])
+	}
+	if sunday := []rune(lines[7]); sunday[4+23] != '░' {
+		t.Errorf("Unexpected Sunday row: %q", lines[7])
+	}
+}
+
+func TestReportHTML(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{ID: "1", Text: "Hello #go", HTML: `Hello <a href="https://x.com/hashtag/go" target="_blank">#go</a>`, Hashtags: []string{"go"},
+			CreatedAt: "Mon Jan 01 09:30:00 +0000 2024", Likes: 10, PermanentURL: "https://x.com/test/status/1",
+			URLs: []twittertimeline.URL{{Expanded: "https://go.dev/blog"}}},
+		{ID: "2", Text: "a < b", CreatedAt: "Tue Jan 02 10:00:00 +0000 2024", Likes: 1},
+	}
+
+	report := NewReport("Report <@test>", tweets, 100, nil)
+	if len(report.TopTweets) != 2 || report.TopTweets[0].ID != "1" || report.Summary.Tweets != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteHTML(&buf); err != nil {
+		t.Fatalf("WriteHTML() failed: %v", err)
+	}
+	page := buf.String()
+	for _, expected := range []string{
+		"<title>Report &lt;@test&gt;</title>",
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		`Hello <a href="https://x.com/hashtag/go" target="_blank">#go</a>`,
+		"a &lt; b",
+		"<td>#go</td><td>1</td>",
+		"<td>go.dev</td>",
+		"Engagement rate",
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("Report doesn't contain %q", expected)
+		}
+	}
+	if strings.Count(page, "<svg") != 3 {
+		t.Errorf("Expected 3 charts, got %d", strings.Count(page, "<svg"))
+	}
+}
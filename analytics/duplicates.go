@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// noiseRegex matches links and mentions, which vary between reposts of the same text
+var noiseRegex = regexp.MustCompile(`https?://\S+|@\w+`)
+
+// Duplicates groups tweets with identical or near-identical texts, e.g. reposted spam or giveaway messages.
+// Texts are compared after normalization (case, links, mentions, punctuation and spacing are ignored)
+// by Jaccard similarity of their word sets; threshold 1 finds only identical normalized texts.
+// Groups of at least two tweets are returned in order of their first tweets. Retweets are skipped.
+func Duplicates(tweets []twittertimeline.Tweet, threshold float64) [][]twittertimeline.Tweet {
+	type candidate struct {
+		index int
+		text  string
+		words map[string]struct{}
+	}
+
+	var candidates []candidate
+	for i := range tweets {
+		if tweets[i].IsRetweet {
+			continue
+		}
+		text := normalizeText(tweets[i].Text)
+		if text == "" {
+			continue
+		}
+		words := make(map[string]struct{})
+		for _, word := range strings.Fields(text) {
+			words[word] = struct{}{}
+		}
+		candidates = append(candidates, candidate{index: i, text: text, words: words})
+	}
+
+	// Group each tweet with the first earlier tweet it is similar to
+	group := make([]int, len(candidates))
+	var groups [][]int
+	for i := range candidates {
+		group[i] = -1
+		for j := 0; j < i; j++ {
+			if candidates[i].text == candidates[j].text ||
+				(threshold < 1 && jaccard(candidates[i].words, candidates[j].words) >= threshold) {
+				group[i] = group[j]
+				break
+			}
+		}
+		if group[i] < 0 {
+			group[i] = len(groups)
+			groups = append(groups, nil)
+		}
+		groups[group[i]] = append(groups[group[i]], candidates[i].index)
+	}
+
+	var duplicates [][]twittertimeline.Tweet
+	for _, indexes := range groups {
+		if len(indexes) < 2 {
+			continue
+		}
+		dup := make([]twittertimeline.Tweet, len(indexes))
+		for k, index := range indexes {
+			dup[k] = tweets[index]
+		}
+		duplicates = append(duplicates, dup)
+	}
+	return duplicates
+}
+
+// normalizeText lowercases text and drops links, mentions and punctuation, leaving words separated by spaces
+func normalizeText(text string) string {
+	text = noiseRegex.ReplaceAllString(strings.ToLower(text), " ")
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// jaccard returns size of intersection divided by size of union of word sets
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for word := range a {
+		if _, ok := b[word]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
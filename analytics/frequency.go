@@ -0,0 +1,139 @@
+package analytics
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Count is a value with number of its occurrences
+type Count struct {
+	Value string
+	Count int
+}
+
+// Pair is a pair of values occurring in the same tweet, A < B
+type Pair struct {
+	A, B  string
+	Count int
+}
+
+// Hashtags returns frequency table of hashtags (lowercased, without #) ordered by count.
+// Tags repeated within a tweet are counted once.
+func Hashtags(tweets []twittertimeline.Tweet) []Count {
+	return frequencies(tweets, func(tweet *twittertimeline.Tweet) []string {
+		return lowerAll(tweet.Hashtags)
+	})
+}
+
+// Mentions returns frequency table of mentioned usernames (lowercased, without @) ordered by count
+func Mentions(tweets []twittertimeline.Tweet) []Count {
+	return frequencies(tweets, func(tweet *twittertimeline.Tweet) []string {
+		return lowerAll(tweet.Mentions)
+	})
+}
+
+// Domains returns frequency table of host names of expanded links ordered by count, "www." is stripped.
+// See DomainsCited for counting by registrable domain.
+func Domains(tweets []twittertimeline.Tweet) []Count {
+	return frequencies(tweets, func(tweet *twittertimeline.Tweet) []string {
+		var domains []string
+		for _, link := range tweet.URLs {
+			u, err := url.Parse(link.Expanded)
+			if err != nil || u.Hostname() == "" {
+				continue
+			}
+			domains = append(domains, strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+		}
+		return domains
+	})
+}
+
+// DomainsCited returns frequency table of registrable domains of expanded links ordered by count,
+// so that subdomains are counted together, e.g. "news.bbc.co.uk" and "www.bbc.co.uk" as "bbc.co.uk"
+func DomainsCited(tweets []twittertimeline.Tweet) []Count {
+	return frequencies(tweets, func(tweet *twittertimeline.Tweet) []string {
+		return tweet.LinkDomains()
+	})
+}
+
+// HashtagPairs returns co-occurrence table of hashtags used together in the same tweet ordered by count
+func HashtagPairs(tweets []twittertimeline.Tweet) []Pair {
+	counts := make(map[[2]string]int)
+	for i := range tweets {
+		tags := unique(lowerAll(tweets[i].Hashtags))
+		sort.Strings(tags)
+		for a := 0; a < len(tags); a++ {
+			for b := a + 1; b < len(tags); b++ {
+				counts[[2]string{tags[a], tags[b]}]++
+			}
+		}
+	}
+
+	pairs := make([]Pair, 0, len(counts))
+	for key, count := range counts {
+		pairs = append(pairs, Pair{A: key[0], B: key[1], Count: count})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// frequencies counts values extracted from tweets, each value once per tweet,
+// and returns them ordered by count and then alphabetically
+func frequencies(tweets []twittertimeline.Tweet, values func(*twittertimeline.Tweet) []string) []Count {
+	counts := make(map[string]int)
+	for i := range tweets {
+		for _, value := range unique(values(&tweets[i])) {
+			counts[value]++
+		}
+	}
+
+	table := make([]Count, 0, len(counts))
+	for value, count := range counts {
+		table = append(table, Count{Value: value, Count: count})
+	}
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Count != table[j].Count {
+			return table[i].Count > table[j].Count
+		}
+		return table[i].Value < table[j].Value
+	})
+	return table
+}
+
+// lowerAll returns lowercased copy of values
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
+// unique removes repeated values in place keeping the first occurrence
+func unique(values []string) []string {
+	result := values[:0]
+	for _, value := range values {
+		seen := false
+		for _, prev := range result {
+			if prev == value {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			result = append(result, value)
+		}
+	}
+	return result
+}
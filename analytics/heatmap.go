@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// heatmapShades are cells of ASCII heatmap from no posts to the maximum
+var heatmapShades = []rune{' ', '░', '▒', '▓', '█'}
+
+// Heatmap is a matrix of post counts by day of week (indexed by time.Weekday) and hour of day
+type Heatmap [7][24]int
+
+// PostingHeatmap counts tweets by day of week and hour in the given location (UTC if nil).
+// Retweets are skipped, since their timestamps belong to the original tweet.
+func PostingHeatmap(tweets []twittertimeline.Tweet, loc *time.Location) Heatmap {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var heatmap Heatmap
+	for i := range tweets {
+		if tweets[i].IsRetweet {
+			continue
+		}
+		createdAt, err := time.Parse(time.RubyDate, tweets[i].CreatedAt)
+		if err != nil {
+			continue
+		}
+		createdAt = createdAt.In(loc)
+		heatmap[createdAt.Weekday()][createdAt.Hour()]++
+	}
+	return heatmap
+}
+
+// Max returns the highest post count of heatmap cells
+func (h *Heatmap) Max() int {
+	max := 0
+	for day := range h {
+		for _, count := range h[day] {
+			if count > max {
+				max = count
+			}
+		}
+	}
+	return max
+}
+
+// String renders heatmap as ASCII art with rows Monday to Sunday and columns by hour,
+// darker cells meaning more posts
+func (h Heatmap) String() string {
+	var b strings.Builder
+	b.WriteString("    ")
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&b, "%-3d", hour)
+	}
+	b.WriteString("\n")
+
+	max := h.Max()
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		b.WriteString(day.String()[:3])
+		b.WriteString(" ")
+		for _, count := range h[day] {
+			// Any posts are visible, the maximum is the darkest
+			levels := len(heatmapShades) - 1
+			shade := 0
+			if count > 0 {
+				shade = (count*levels + max - 1) / max
+			}
+			b.WriteRune(heatmapShades[shade])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,201 @@
+package analytics
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Report is an analytics summary of account tweets rendered as a standalone HTML page
+type Report struct {
+	Title     string
+	Generated time.Time
+	Summary   Summary
+	Heatmap   Heatmap
+	TopTweets []twittertimeline.Tweet // Top tweets by engagement
+	Hashtags  []Count                 // Most used hashtags
+	Domains   []Count                 // Most cited domains
+}
+
+// reportTopCount is the number of top tweets, hashtags and domains included in report
+const reportTopCount = 10
+
+// NewReport computes report of tweets with posting times in the given location (UTC if nil).
+// Followers is the account followers count used for engagement rate.
+func NewReport(title string, tweets []twittertimeline.Tweet, followers int, loc *time.Location) *Report {
+	if loc == nil {
+		loc = time.UTC
+	}
+	report := &Report{
+		Title:     title,
+		Generated: time.Now().In(loc),
+		Summary:   SummarizeIn(tweets, followers, loc),
+		Heatmap:   PostingHeatmap(tweets, loc),
+		TopTweets: TopTweets(tweets, reportTopCount, ByEngagement),
+		Hashtags:  Hashtags(tweets),
+		Domains:   DomainsCited(tweets),
+	}
+	if len(report.Hashtags) > reportTopCount {
+		report.Hashtags = report.Hashtags[:reportTopCount]
+	}
+	if len(report.Domains) > reportTopCount {
+		report.Domains = report.Domains[:reportTopCount]
+	}
+	return report
+}
+
+// WriteHTML writes report as a standalone HTML page with charts as inline SVG
+// and top tweets embedded with their HTML content
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// hourChart renders posts by hour as SVG bar chart
+func (r *Report) hourChart() template.HTML {
+	labels := make([]string, 24)
+	for hour := range labels {
+		if hour%3 == 0 {
+			labels[hour] = fmt.Sprint(hour)
+		}
+	}
+	return barChart(r.Summary.ByHour[:], labels)
+}
+
+// weekdayChart renders posts by day of week as SVG bar chart, Monday first
+func (r *Report) weekdayChart() template.HTML {
+	values := make([]int, 7)
+	labels := make([]string, 7)
+	for i := range values {
+		day := time.Weekday((i + 1) % 7)
+		values[i] = r.Summary.ByWeekday[day]
+		labels[i] = day.String()[:3]
+	}
+	return barChart(values, labels)
+}
+
+// heatmapChart renders posting heatmap as SVG grid, Monday first
+func (r *Report) heatmapChart() template.HTML {
+	const cell, left, top = 20, 40, 20
+	max := r.Heatmap.Max()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-size="11">`, left+24*cell, top+7*cell)
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&b, `<text x="%d" y="14">%d</text>`, left+hour*cell, hour)
+	}
+	for i := 0; i < 7; i++ {
+		day := time.Weekday((i + 1) % 7)
+		y := top + i*cell
+		fmt.Fprintf(&b, `<text x="0" y="%d">%s</text>`, y+14, day.String()[:3])
+		for hour, count := range r.Heatmap[day] {
+			opacity := 0.0
+			if max > 0 {
+				opacity = float64(count) / float64(max)
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#1d9bf0" fill-opacity="%.2f" stroke="#e1e8ed"><title>%s %02d:00 — %d</title></rect>`,
+				left+hour*cell, y, cell, cell, opacity, day, hour, count)
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// barChart renders values as SVG bar chart with optional labels under bars
+func barChart(values []int, labels []string) template.HTML {
+	const width, height, bottom = 24, 120, 16
+	max := 0
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-size="11">`, len(values)*width, height+bottom)
+	for i, value := range values {
+		barHeight := 0
+		if max > 0 {
+			barHeight = value * height / max
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#1d9bf0"><title>%d</title></rect>`,
+			i*width+2, height-barHeight, width-4, barHeight, value)
+		if labels[i] != "" {
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, i*width+2, height+bottom-2, template.HTMLEscapeString(labels[i]))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// reportTemplate is the HTML page of report. Tweet HTML is produced by the client renderer
+// with text escaped, so it is embedded as is.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"tweetHTML": func(tweet twittertimeline.Tweet) template.HTML {
+		if tweet.HTML == "" {
+			return template.HTML(template.HTMLEscapeString(tweet.Text))
+		}
+		return template.HTML(tweet.HTML)
+	},
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.2f%%", value*100)
+	},
+	"hourChart":    (*Report).hourChart,
+	"weekdayChart": (*Report).weekdayChart,
+	"heatmapChart": (*Report).heatmapChart,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; color: #0f1419; }
+table { border-collapse: collapse; }
+td, th { padding: 4px 12px; border-bottom: 1px solid #e1e8ed; text-align: left; }
+.tweet { border: 1px solid #e1e8ed; border-radius: 12px; padding: 12px; margin: 12px 0; }
+.stats { color: #536471; font-size: 0.9em; }
+.charts { display: flex; flex-wrap: wrap; gap: 32px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="stats">Generated {{.Generated.Format "2006-01-02 15:04 MST"}}{{if not .Summary.First.IsZero}},
+tweets from {{.Summary.First.Format "2006-01-02"}} to {{.Summary.Last.Format "2006-01-02"}}{{end}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th></th><th>Total</th><th>Average</th></tr>
+<tr><td>Tweets</td><td>{{.Summary.Tweets}}</td><td>{{printf "%.1f" .Summary.TweetsPerDay}} per day</td></tr>
+<tr><td>Likes</td><td>{{.Summary.Likes}}</td><td>{{printf "%.1f" .Summary.AvgLikes}}</td></tr>
+<tr><td>Retweets</td><td>{{.Summary.Retweets}}</td><td>{{printf "%.1f" .Summary.AvgRetweets}}</td></tr>
+<tr><td>Replies</td><td>{{.Summary.Replies}}</td><td>{{printf "%.1f" .Summary.AvgReplies}}</td></tr>
+<tr><td>Views</td><td>{{.Summary.Views}}</td><td>{{printf "%.1f" .Summary.AvgViews}}</td></tr>
+{{if .Summary.EngagementRate}}<tr><td>Engagement rate</td><td></td><td>{{percent .Summary.EngagementRate}}</td></tr>{{end}}
+</table>
+
+<h2>Posting times</h2>
+<div class="charts">
+<div><h3>By hour</h3>{{hourChart .}}</div>
+<div><h3>By weekday</h3>{{weekdayChart .}}</div>
+</div>
+<h3>Heatmap</h3>
+{{heatmapChart .}}
+
+{{if .TopTweets}}<h2>Top tweets</h2>
+{{range .TopTweets}}<div class="tweet">
+<div>{{tweetHTML .}}</div>
+<p class="stats">{{.Likes}} likes · {{.Retweets}} retweets · {{.Replies}} replies{{if .Views}} · {{.Views}} views{{end}}{{if .PermanentURL}} · <a href="{{.PermanentURL}}">{{.CreatedAt}}</a>{{end}}</p>
+</div>
+{{end}}{{end}}
+{{if .Hashtags}}<h2>Hashtags</h2>
+<table>{{range .Hashtags}}<tr><td>#{{.Value}}</td><td>{{.Count}}</td></tr>{{end}}</table>
+{{end}}
+{{if .Domains}}<h2>Cited domains</h2>
+<table>{{range .Domains}}<tr><td>{{.Value}}</td><td>{{.Count}}</td></tr>{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"sort"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Metric is a tweet counter used for ranking
+type Metric int
+
+// Ranking metrics
+const (
+	ByLikes      Metric = iota // Like count
+	ByRetweets                 // Retweet count
+	ByViews                    // View count
+	ByEngagement               // Sum of likes, retweets and replies
+)
+
+// Value returns the metric value of tweet
+func (m Metric) Value(tweet *twittertimeline.Tweet) int {
+	switch m {
+	case ByRetweets:
+		return tweet.Retweets
+	case ByViews:
+		return tweet.Views
+	case ByEngagement:
+		return tweet.Likes + tweet.Retweets + tweet.Replies
+	default:
+		return tweet.Likes
+	}
+}
+
+// TopTweets returns up to n tweets ranked by metric in descending order (all tweets if n <= 0).
+// Tweets with equal values keep their original order. Retweets are skipped, since their
+// counters belong to the original tweet. The tweets slice is not modified.
+func TopTweets(tweets []twittertimeline.Tweet, n int, metric Metric) []twittertimeline.Tweet {
+	ranked := make([]twittertimeline.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if !tweet.IsRetweet {
+			ranked = append(ranked, tweet)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return metric.Value(&ranked[i]) > metric.Value(&ranked[j])
+	})
+
+	if n > 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
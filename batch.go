@@ -0,0 +1,42 @@
+package twittertimeline
+
+import "sync"
+
+// GetTimelines fetches timelines of several users using a pool of concurrency workers.
+// It returns tweets and errors keyed by user ID; every user ID is present in exactly one of the maps.
+func (c *Client) GetTimelines(userIDs []string, concurrency int) (map[string][]Tweet, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make(map[string][]Tweet)
+	errs := make(map[string]error)
+	var mu sync.Mutex
+
+	ids := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range ids {
+				tweets, err := c.GetUserTweets(userID)
+				mu.Lock()
+				if err != nil {
+					errs[userID] = err
+				} else {
+					results[userID] = tweets
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, userID := range userIDs {
+		ids <- userID
+	}
+	close(ids)
+	wg.Wait()
+
+	return results, errs
+}
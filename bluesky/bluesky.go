@@ -0,0 +1,265 @@
+// Package bluesky converts tweets into Bluesky (AT Protocol) app.bsky.feed.post records
+// for cross-posting bridges
+package bluesky
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Record and embed types
+const (
+	PostType          = "app.bsky.feed.post"
+	EmbedImagesType   = "app.bsky.embed.images"
+	EmbedVideoType    = "app.bsky.embed.video"
+	EmbedExternalType = "app.bsky.embed.external"
+
+	FacetLinkType    = "app.bsky.richtext.facet#link"
+	FacetMentionType = "app.bsky.richtext.facet#mention"
+	FacetTagType     = "app.bsky.richtext.facet#tag"
+)
+
+// Limits of post record
+const (
+	MaxTextLength = 300 // Maximum text length in graphemes, counted as runes
+	MaxImages     = 4   // Maximum number of images in post
+)
+
+// Post is an app.bsky.feed.post record
+type Post struct {
+	Type      string   `json:"$type"`
+	Text      string   `json:"text"`
+	CreatedAt string   `json:"createdAt"`
+	Langs     []string `json:"langs,omitempty"`
+	Facets    []Facet  `json:"facets,omitempty"`
+	Embed     *Embed   `json:"embed,omitempty"`
+}
+
+// Facet annotates a byte range of post text with a link, mention or tag
+type Facet struct {
+	Index    ByteSlice `json:"index"`
+	Features []Feature `json:"features"`
+}
+
+// ByteSlice is a range of UTF-8 bytes of post text, ByteEnd is exclusive
+type ByteSlice struct {
+	ByteStart int `json:"byteStart"`
+	ByteEnd   int `json:"byteEnd"`
+}
+
+// Feature is a link, mention or tag of facet
+type Feature struct {
+	Type string `json:"$type"`
+	URI  string `json:"uri,omitempty"` // Link URL
+	DID  string `json:"did,omitempty"` // Mentioned account
+	Tag  string `json:"tag,omitempty"` // Hashtag without #
+}
+
+// Embed is images, video or external link card embedded into post.
+// Media must be uploaded as blobs before the record is created: SourceURL holds the original media URL
+// and Image or Video blob is to be set from the upload response.
+type Embed struct {
+	Type     string    `json:"$type"`
+	Images   []Image   `json:"images,omitempty"`
+	Video    *Blob     `json:"video,omitempty"`
+	External *External `json:"external,omitempty"`
+
+	SourceURL string `json:"-"` // Original video URL
+}
+
+// Image is an image of images embed
+type Image struct {
+	Alt   string `json:"alt"`
+	Image *Blob  `json:"image"`
+
+	SourceURL string `json:"-"` // Original image URL
+}
+
+// Blob is a reference to uploaded blob as returned by com.atproto.repo.uploadBlob
+type Blob struct {
+	Type string `json:"$type"`
+	Ref  struct {
+		Link string `json:"$link"`
+	} `json:"ref"`
+	MimeType string `json:"mimeType"`
+	Size     int    `json:"size"`
+}
+
+// External is a link card of external embed
+type External struct {
+	URI         string `json:"uri"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Bridge converts tweets into post records
+type Bridge struct {
+	// ResolveDID returns DID of Bluesky account for X username, or empty string if there is none.
+	// Mentions without DID are linked to X profiles.
+	ResolveDID func(username string) string
+}
+
+// Post converts tweet into post record. Links are shown by their display text with link facets
+// to expanded URLs, media links are removed from text and media are described in Embed.
+// Text longer than MaxTextLength is truncated. Replies are not linked, since parent records are unknown.
+func (b Bridge) Post(tweet *twittertimeline.Tweet) *Post {
+	post := &Post{Type: PostType}
+	post.Text, post.Facets = b.richText(tweet)
+	post.Text, post.Facets = truncate(post.Text, post.Facets)
+
+	createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt)
+	if err != nil {
+		createdAt = time.Now()
+	}
+	post.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+	lang := tweet.Lang
+	if lang == "" || lang == "und" {
+		lang = tweet.DetectedLang
+	}
+	if lang != "" && lang != "und" {
+		post.Langs = []string{lang}
+	}
+
+	post.Embed = tweetEmbed(tweet)
+	return post
+}
+
+// richText builds post text and facets from tweet text and its entities.
+// Entity offsets point into tweet text as is, so only text between entities is unescaped.
+func (b Bridge) richText(tweet *twittertimeline.Tweet) (string, []Facet) {
+	text := []rune(tweet.Text)
+	entities := append([]twittertimeline.Entity(nil), tweet.Entities...)
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Start < entities[j].Start })
+
+	var sb strings.Builder
+	var facets []Facet
+	pos := 0
+	for _, entity := range entities {
+		if entity.Start < pos || entity.End > len(text) || entity.Start >= entity.End {
+			continue
+		}
+		sb.WriteString(html.UnescapeString(string(text[pos:entity.Start])))
+		pos = entity.End
+
+		original := string(text[entity.Start:entity.End])
+		display := original
+		var feature Feature
+		switch entity.Type {
+		case twittertimeline.EntityMedia:
+			// Media are embedded instead of linked
+			continue
+		case twittertimeline.EntityURL:
+			display = urlDisplay(tweet, entity.Value, original)
+			feature = Feature{Type: FacetLinkType, URI: entity.Value}
+		case twittertimeline.EntityHashtag:
+			feature = Feature{Type: FacetTagType, Tag: entity.Value}
+		case twittertimeline.EntityMention:
+			if did := b.resolveDID(entity.Value); did != "" {
+				feature = Feature{Type: FacetMentionType, DID: did}
+			} else {
+				feature = Feature{Type: FacetLinkType, URI: "https://x.com/" + entity.Value}
+			}
+		default:
+			sb.WriteString(html.UnescapeString(original))
+			continue
+		}
+
+		start := sb.Len()
+		sb.WriteString(display)
+		facets = append(facets, Facet{
+			Index:    ByteSlice{ByteStart: start, ByteEnd: sb.Len()},
+			Features: []Feature{feature},
+		})
+	}
+	if pos < len(text) {
+		sb.WriteString(html.UnescapeString(string(text[pos:])))
+	}
+
+	// Removed media links leave trailing spaces
+	return strings.TrimRightFunc(sb.String(), unicode.IsSpace), facets
+}
+
+// resolveDID returns DID for username or empty string without resolver
+func (b Bridge) resolveDID(username string) string {
+	if b.ResolveDID == nil {
+		return ""
+	}
+	return b.ResolveDID(username)
+}
+
+// urlDisplay returns display text of link with the expanded URL
+func urlDisplay(tweet *twittertimeline.Tweet, expanded, fallback string) string {
+	for _, u := range tweet.URLs {
+		if u.Expanded == expanded && u.Display != "" {
+			return u.Display
+		}
+	}
+	if expanded != "" {
+		return expanded
+	}
+	return fallback
+}
+
+// truncate cuts text to MaxTextLength runes and drops facets beyond it
+func truncate(text string, facets []Facet) (string, []Facet) {
+	if utf8.RuneCountInString(text) <= MaxTextLength {
+		return text, facets
+	}
+
+	cut := 0
+	for i := 0; i < MaxTextLength-1; i++ {
+		_, size := utf8.DecodeRuneInString(text[cut:])
+		cut += size
+	}
+	kept := facets[:0]
+	for _, facet := range facets {
+		if facet.Index.ByteEnd <= cut {
+			kept = append(kept, facet)
+		}
+	}
+	return text[:cut] + "…", kept
+}
+
+// tweetEmbed describes photos, video or quoted tweet of tweet as post embed
+func tweetEmbed(tweet *twittertimeline.Tweet) *Embed {
+	var images []Image
+	for _, media := range tweet.Media {
+		switch media.Type {
+		case twittertimeline.MediaPhoto:
+			if len(images) < MaxImages {
+				images = append(images, Image{SourceURL: media.URL})
+			}
+		case twittertimeline.MediaVideo, twittertimeline.MediaAnimatedGIF:
+			// Post may embed either images or a single video
+			if len(images) == 0 {
+				return &Embed{Type: EmbedVideoType, SourceURL: media.URL}
+			}
+		}
+	}
+	if len(tweet.Media) == 0 {
+		for _, image := range tweet.Images {
+			if len(images) < MaxImages {
+				images = append(images, Image{SourceURL: image})
+			}
+		}
+	}
+	if len(images) > 0 {
+		return &Embed{Type: EmbedImagesType, Images: images}
+	}
+
+	if quoted := tweet.QuotedTweet; quoted != nil && quoted.PermanentURL != "" {
+		return &Embed{Type: EmbedExternalType, External: &External{
+			URI:         quoted.PermanentURL,
+			Title:       "@" + quoted.Username,
+			Description: html.UnescapeString(quoted.Text),
+		}}
+	}
+	return nil
+}
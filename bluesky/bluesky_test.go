@@ -0,0 +1,137 @@
+package bluesky
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+func TestPost(t *testing.T) {
+	text := "Привет @alice &amp; @bob #go https://t.co/abc https://t.co/media"
+	tweet := &twittertimeline.Tweet{
+		Text:      text,
+		Lang:      "ru",
+		CreatedAt: "Mon Jan 01 09:30:00 +0300 2024",
+		URLs:      []twittertimeline.URL{{Short: "https://t.co/abc", Expanded: "https://go.dev/doc/", Display: "go.dev/doc/"}},
+		Entities: []twittertimeline.Entity{
+			{Type: twittertimeline.EntityMention, Value: "alice", Start: 7, End: 13},
+			{Type: twittertimeline.EntityMention, Value: "bob", Start: 20, End: 24},
+			{Type: twittertimeline.EntityHashtag, Value: "go", Start: 25, End: 28},
+			{Type: twittertimeline.EntityURL, Value: "https://go.dev/doc/", Start: 29, End: 45},
+			{Type: twittertimeline.EntityMedia, Value: "https://pbs.twimg.com/media/a.jpg", Start: 46, End: 64},
+		},
+		Media: []twittertimeline.Media{
+			{Type: twittertimeline.MediaPhoto, URL: "https://pbs.twimg.com/media/a.jpg"},
+			{Type: twittertimeline.MediaPhoto, URL: "https://pbs.twimg.com/media/b.jpg"},
+		},
+	}
+	bridge := Bridge{ResolveDID: func(username string) string {
+		if username == "alice" {
+			return "did:plc:alice"
+		}
+		return ""
+	}}
+
+	post := bridge.Post(tweet)
+	if post.Type != PostType || post.Text != "Привет @alice & @bob #go go.dev/doc/" {
+		t.Errorf("Unexpected post text: %q", post.Text)
+	}
+	if post.CreatedAt != "2024-01-01T06:30:00Z" || !reflect.DeepEqual(post.Langs, []string{"ru"}) {
+		t.Errorf("Unexpected post metadata: %s, %v", post.CreatedAt, post.Langs)
+	}
+
+	expected := []struct {
+		text    string
+		feature Feature
+	}{
+		{"@alice", Feature{Type: FacetMentionType, DID: "did:plc:alice"}},
+		{"@bob", Feature{Type: FacetLinkType, URI: "https://x.com/bob"}},
+		{"#go", Feature{Type: FacetTagType, Tag: "go"}},
+		{"go.dev/doc/", Feature{Type: FacetLinkType, URI: "https://go.dev/doc/"}},
+	}
+	if len(post.Facets) != len(expected) {
+		t.Fatalf("Expected %d facets, got %+v", len(expected), post.Facets)
+	}
+	for i, facet := range post.Facets {
+		if got := post.Text[facet.Index.ByteStart:facet.Index.ByteEnd]; got != expected[i].text {
+			t.Errorf("Facet %d covers %q, want %q", i, got, expected[i].text)
+		}
+		if !reflect.DeepEqual(facet.Features, []Feature{expected[i].feature}) {
+			t.Errorf("Facet %d has features %+v, want %+v", i, facet.Features, expected[i].feature)
+		}
+	}
+
+	if post.Embed == nil || post.Embed.Type != EmbedImagesType || len(post.Embed.Images) != 2 ||
+		post.Embed.Images[1].SourceURL != "https://pbs.twimg.com/media/b.jpg" {
+		t.Errorf("Unexpected embed: %+v", post.Embed)
+	}
+}
+
+func TestPostEscapedText(t *testing.T) {
+	// Offsets count "&amp;" of escaped text as 5 runes
+	tweet := &twittertimeline.Tweet{
+		Text:      "Tom &amp; Jerry https://t.co/abc see &lt;3",
+		CreatedAt: "Mon Jan 01 00:00:00 +0000 2024",
+		URLs:      []twittertimeline.URL{{Short: "https://t.co/abc", Expanded: "https://example.com/x", Display: "example.com/x"}},
+		Entities: []twittertimeline.Entity{
+			{Type: twittertimeline.EntityURL, Value: "https://example.com/x", Start: 16, End: 32},
+		},
+	}
+
+	post := Bridge{}.Post(tweet)
+	if post.Text != "Tom & Jerry example.com/x see <3" {
+		t.Errorf("Unexpected post text: %q", post.Text)
+	}
+	if len(post.Facets) != 1 || post.Text[post.Facets[0].Index.ByteStart:post.Facets[0].Index.ByteEnd] != "example.com/x" {
+		t.Errorf("Unexpected facets: %+v", post.Facets)
+	}
+}
+
+func TestPostEmbeds(t *testing.T) {
+	video := Bridge{}.Post(&twittertimeline.Tweet{
+		Text:  "Video",
+		Media: []twittertimeline.Media{{Type: twittertimeline.MediaVideo, URL: "https://video.twimg.com/1.mp4"}},
+	})
+	if video.Embed == nil || video.Embed.Type != EmbedVideoType || video.Embed.SourceURL != "https://video.twimg.com/1.mp4" {
+		t.Errorf("Unexpected video embed: %+v", video.Embed)
+	}
+
+	quote := Bridge{}.Post(&twittertimeline.Tweet{
+		Text: "Quote",
+		QuotedTweet: &twittertimeline.QuotedTweet{Tweet: twittertimeline.Tweet{
+			Username: "carol", Text: "a &amp; b", PermanentURL: "https://x.com/carol/status/3",
+		}},
+	})
+	if quote.Embed == nil || quote.Embed.Type != EmbedExternalType ||
+		*quote.Embed.External != (External{URI: "https://x.com/carol/status/3", Title: "@carol", Description: "a & b"}) {
+		t.Errorf("Unexpected quote embed: %+v", quote.Embed)
+	}
+
+	if plain := (Bridge{}).Post(&twittertimeline.Tweet{Text: "Plain", Lang: "und"}); plain.Embed != nil || plain.Langs != nil {
+		t.Errorf("Expected no embed and languages, got %+v", plain)
+	}
+}
+
+func TestPostTruncate(t *testing.T) {
+	text := strings.Repeat("ж", 290) + " #tag"
+	tweet := &twittertimeline.Tweet{
+		Text:     text + " " + strings.Repeat("x", 20),
+		Entities: []twittertimeline.Entity{{Type: twittertimeline.EntityHashtag, Value: "tag", Start: 291, End: 295}},
+	}
+
+	post := Bridge{}.Post(tweet)
+	if n := len([]rune(post.Text)); n != MaxTextLength || !strings.HasSuffix(post.Text, "…") {
+		t.Errorf("Expected text truncated to %d runes, got %d", MaxTextLength, n)
+	}
+	if len(post.Facets) != 1 {
+		t.Errorf("Expected facet within truncated text to be kept, got %+v", post.Facets)
+	}
+
+	tweet.Entities[0].Start, tweet.Entities[0].End = 300, 305
+	tweet.Text = strings.Repeat("ж", 300) + " #tag"
+	if post := (Bridge{}).Post(tweet); len(post.Facets) != 0 {
+		t.Errorf("Expected facet beyond truncated text to be dropped, got %+v", post.Facets)
+	}
+}
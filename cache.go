@@ -0,0 +1,123 @@
+package twittertimeline
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// responseCache is an LRU cache of successful API responses bounded by number of entries and TTL.
+// Concurrent misses of the same key share a single upstream request.
+type responseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	lru        *list.List // Most recently used entries first
+	inflight   map[string]*cacheCall
+}
+
+// cacheEntry is a cached response
+type cacheEntry struct {
+	key     string
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// cacheCall is an upstream request shared by concurrent misses
+type cacheCall struct {
+	done  chan struct{}
+	entry *cacheEntry
+	err   error
+}
+
+// newResponseCache creates an empty cache
+func newResponseCache(maxEntries int, ttl time.Duration) *responseCache {
+	return &responseCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		inflight:   make(map[string]*cacheCall),
+	}
+}
+
+// cacheKey returns cache key of API call
+func cacheKey(endpoint string, variables map[string]any) string {
+	variablesJSON, _ := json.Marshal(variables)
+	return endpoint + "?" + string(variablesJSON)
+}
+
+// get returns cached response for key or calls fetch and caches its successful response
+func (rc *responseCache) get(key string, now time.Time, fetch func() (*http.Response, error)) (*http.Response, error) {
+	rc.mu.Lock()
+	if el, ok := rc.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		if now.Before(entry.expires) {
+			rc.lru.MoveToFront(el)
+			rc.mu.Unlock()
+			return entry.response(), nil
+		}
+		rc.lru.Remove(el)
+		delete(rc.entries, key)
+	}
+	if call, ok := rc.inflight[key]; ok {
+		rc.mu.Unlock()
+		<-call.done
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.entry.response(), nil
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	rc.inflight[key] = call
+	rc.mu.Unlock()
+
+	call.entry, call.err = fetchEntry(key, now.Add(rc.ttl), fetch)
+
+	rc.mu.Lock()
+	delete(rc.inflight, key)
+	if call.err == nil {
+		rc.entries[key] = rc.lru.PushFront(call.entry)
+		for rc.lru.Len() > rc.maxEntries {
+			oldest := rc.lru.Back()
+			rc.lru.Remove(oldest)
+			delete(rc.entries, oldest.Value.(*cacheEntry).key)
+		}
+	}
+	rc.mu.Unlock()
+	close(call.done)
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return call.entry.response(), nil
+}
+
+// fetchEntry calls fetch and reads its response into cache entry
+func fetchEntry(key string, expires time.Time, fetch func() (*http.Response, error)) (*cacheEntry, error) {
+	resp, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &cacheEntry{key: key, header: resp.Header, body: body, expires: expires}, nil
+}
+
+// response returns a new response with cached body
+func (e *cacheEntry) response() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     e.header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(e.body)),
+	}
+}
@@ -0,0 +1,29 @@
+package twittertimeline
+
+import "time"
+
+// Clock is a source of time used for cache expiration, so tests can control time instead of sleeping
+type Clock interface {
+	Now() time.Time
+	Ticker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks of Clock at intervals
+type Ticker interface {
+	Chan() <-chan time.Time
+	Stop()
+}
+
+// realClock is Clock backed by system time
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Ticker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+// realTicker is Ticker backed by time.Ticker
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) Chan() <-chan time.Time { return t.C }
@@ -3,35 +3,56 @@ package main
 import (
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 
 	twittertimeline "github.com/n0madic/twitter-timeline"
+	"github.com/n0madic/twitter-timeline/jsonschema"
 )
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: twitter-timeline <user_id_or_username>")
+		fmt.Println("Usage: twitter-timeline <user_id_username_or_profile_url>")
+		fmt.Println("       twitter-timeline --print-schema [tweet|user]")
 		fmt.Println("Examples:")
 		fmt.Println("  twitter-timeline 1624051836033421317     # Poe platform (User ID)")
 		fmt.Println("  twitter-timeline elonmusk                # Elon Musk (Username)")
+		fmt.Println("  twitter-timeline https://x.com/elonmusk  # Elon Musk (Profile URL)")
 		os.Exit(1)
 	}
 
-	userID := os.Args[1]
-	client := twittertimeline.NewClient()
-
-	// Resolve User ID from input parameter
-	IsUserID, _ := regexp.MatchString(`^\d{1,19}$`, userID)
-	if !IsUserID {
-		// Otherwise consider it username and try to get User ID
-		resolvedUserID, err := client.GetUserID(userID)
-		if err != nil {
-			fmt.Printf("failed to find user '%s': %v\n", userID, err)
+	// Print JSON Schema of Tweet or User
+	if os.Args[1] == "--print-schema" {
+		kind := "tweet"
+		if len(os.Args) > 2 {
+			kind = os.Args[2]
+		}
+		var schema []byte
+		switch {
+		case len(os.Args) > 3:
+		case kind == "tweet":
+			schema = jsonschema.Tweet()
+		case kind == "user":
+			schema = jsonschema.User()
+		}
+		if schema == nil {
+			fmt.Fprintf(os.Stderr, "unknown schema %q\n", strings.Join(os.Args[2:], " "))
+			fmt.Fprintln(os.Stderr, "Usage: twitter-timeline --print-schema [tweet|user]")
 			os.Exit(1)
 		}
-		userID = resolvedUserID
+		fmt.Println(string(schema))
+		return
+	}
+
+	client := twittertimeline.NewClient()
+	defer client.Close()
+
+	// Resolve User ID from ID, username or profile URL
+	user, err := client.ResolveUser(os.Args[1])
+	if err != nil {
+		fmt.Printf("failed to find user '%s': %v\n", os.Args[1], err)
+		os.Exit(1)
 	}
+	userID := user.ID
 
 	fmt.Printf("Loading timeline for user %s...\n", userID)
 
@@ -87,6 +108,14 @@ func main() {
 				fmt.Println()
 			}
 
+			if len(tweet.Cashtags) > 0 {
+				fmt.Print("Cashtags: ")
+				for _, cashtag := range tweet.Cashtags {
+					fmt.Printf("$%s ", cashtag)
+				}
+				fmt.Println()
+			}
+
 			if len(tweet.URLs) > 0 {
 				fmt.Println("URLs:")
 				for _, url := range tweet.URLs {
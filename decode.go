@@ -0,0 +1,233 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// timelineInstructionsPath is the path to instructions array in timeline response
+var timelineInstructionsPath = []string{"data", "user", "result", "timeline", "timeline", "instructions"}
+
+// decodeTimeline incrementally decodes timeline response and calls fn for every instruction entry,
+// so neither the full response body nor its generic JSON tree is buffered. Entries passed to fn
+// stay referenced if fn keeps them, e.g. timelineCollector holds every collected TweetResult.
+func decodeTimeline(r io.Reader, fn func(instructionType string, entry *TimelineEntry)) error {
+	return decodeTimelineEntries(r, false, fn)
+}
+
+// decodeTimelineRaw is decodeTimeline which also keeps original JSON of tweet results in TweetResult.Raw
+func decodeTimelineRaw(r io.Reader, fn func(instructionType string, entry *TimelineEntry)) error {
+	return decodeTimelineEntries(r, true, fn)
+}
+
+// decodeTimelineEntries decodes timeline response entries, keeping original JSON of tweet results if raw is set
+func decodeTimelineEntries(r io.Reader, raw bool, fn func(instructionType string, entry *TimelineEntry)) error {
+	dec := json.NewDecoder(r)
+
+	found, err := seekPath(dec, timelineInstructionsPath)
+	if err != nil || !found {
+		return err
+	}
+
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := decodeInstruction(dec, raw, fn); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token() // closing ]
+	return err
+}
+
+// decodeInstruction decodes a single timeline instruction object
+func decodeInstruction(dec *json.Decoder, raw bool, fn func(instructionType string, entry *TimelineEntry)) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	var instructionType string
+	var singleEntry *TimelineEntry
+	for dec.More() {
+		key, err := decodeKey(dec)
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "type":
+			if err := dec.Decode(&instructionType); err != nil {
+				return err
+			}
+		case "entries":
+			// Only TimelineAddEntries instructions carry entries list
+			if instructionType == "" {
+				instructionType = "TimelineAddEntries"
+			}
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for dec.More() {
+				var entry TimelineEntry
+				if err := decodeEntry(dec, raw, &entry); err != nil {
+					return err
+				}
+				fn(instructionType, &entry)
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+		case "entry":
+			// Type may follow the entry, so dispatch it at the end of the instruction
+			singleEntry = new(TimelineEntry)
+			if err := decodeEntry(dec, raw, singleEntry); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+		}
+	}
+
+	if singleEntry != nil {
+		fn(instructionType, singleEntry)
+	}
+
+	_, err := dec.Token() // closing }
+	return err
+}
+
+// rawTimelineEntry holds original JSON of tweet results of timeline entry
+type rawTimelineEntry struct {
+	Content struct {
+		ItemContent *struct {
+			TweetResults struct {
+				Result json.RawMessage `json:"result"`
+			} `json:"tweet_results"`
+		} `json:"itemContent"`
+		Items []struct {
+			Item struct {
+				ItemContent struct {
+					TweetResults struct {
+						Result json.RawMessage `json:"result"`
+					} `json:"tweet_results"`
+				} `json:"itemContent"`
+			} `json:"item"`
+		} `json:"items"`
+	} `json:"content"`
+}
+
+// decodeEntry decodes the next timeline entry, attaching original JSON to its tweet results if raw is set
+func decodeEntry(dec *json.Decoder, raw bool, entry *TimelineEntry) error {
+	if !raw {
+		return dec.Decode(entry)
+	}
+
+	var data json.RawMessage
+	if err := dec.Decode(&data); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return err
+	}
+	var rawEntry rawTimelineEntry
+	if err := json.Unmarshal(data, &rawEntry); err != nil {
+		return err
+	}
+
+	if entry.Content.ItemContent != nil && rawEntry.Content.ItemContent != nil {
+		entry.Content.ItemContent.TweetResults.Result.Raw = rawEntry.Content.ItemContent.TweetResults.Result
+	}
+	if entry.Content.Items != nil {
+		items := *entry.Content.Items
+		for i := range items {
+			if i < len(rawEntry.Content.Items) {
+				items[i].Item.ItemContent.TweetResults.Result.Raw = rawEntry.Content.Items[i].Item.ItemContent.TweetResults.Result
+			}
+		}
+	}
+	return nil
+}
+
+// seekPath descends into nested objects by keys and stops before the value of the last key.
+// It returns false if the path is not present in the document.
+func seekPath(dec *json.Decoder, path []string) (bool, error) {
+	for _, want := range path {
+		token, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '{' {
+			return false, nil
+		}
+
+		found := false
+		for dec.More() {
+			key, err := decodeKey(dec)
+			if err != nil {
+				return false, err
+			}
+			if key == want {
+				found = true
+				break
+			}
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// decodeKey reads object key
+func decodeKey(dec *json.Decoder) (string, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token %v, expected object key", token)
+	}
+	return key, nil
+}
+
+// expectDelim reads the next token and checks it is the expected delimiter
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("unexpected token %v, expected %v", token, want)
+	}
+	return nil
+}
+
+// skipValue skips the next value without decoding it into memory
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
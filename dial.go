@@ -0,0 +1,242 @@
+package twittertimeline
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DialFunc connects to address on named network, see net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// newDialer creates dialer with the same settings as http.DefaultTransport
+func newDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+}
+
+// defaultFallbackDelay is the delay before racing the other address family, same as in net.Dialer
+const defaultFallbackDelay = 300 * time.Millisecond
+
+// dialContext connects to address with client dialer, reordering request headers
+// written to the connection if it is enabled
+func (c *Client) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := c.dialAddress(ctx, network, address)
+	if err != nil || !c.headerOrder {
+		return conn, err
+	}
+	return &orderedConn{Conn: conn}, nil
+}
+
+// dialAddress connects to address with client dialer, resolving host via DoH if it is enabled
+// and trying IPv6 first if it is preferred
+func (c *Client) dialAddress(ctx context.Context, network, address string) (net.Conn, error) {
+	// Dialer orders addresses of system resolver itself, custom dialer resolves host on its own
+	if c.resolver == nil && (!c.preferIPv6 || network != "tcp" || c.customDial != nil) {
+		return c.dial(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return c.dial(ctx, network, address)
+	}
+
+	var addrs []string
+	if c.resolver != nil {
+		addrs, err = c.resolver.lookup(ctx, host)
+	} else {
+		addrs, err = c.systemResolver().LookupHost(ctx, host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.dialAddrs(ctx, network, port, orderAddrs(addrs, c.preferIPv6))
+}
+
+// systemResolver returns resolver of the client dialer
+func (c *Client) systemResolver() *net.Resolver {
+	if c.dialer.Resolver != nil {
+		return c.dialer.Resolver
+	}
+	return net.DefaultResolver
+}
+
+// dialAddrs connects to the first reachable of resolved addresses. Addresses of the family
+// of the first one are tried in order, addresses of the other family are raced against them
+// after fallback delay of the dialer or as soon as the first family fails ("Happy Eyeballs").
+func (c *Client) dialAddrs(ctx context.Context, network, port string, addrs []string) (net.Conn, error) {
+	if c.dialer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.dialer.Timeout)
+		defer cancel()
+	}
+
+	primaries, fallbacks := partitionAddrs(addrs)
+	delay := c.dialer.FallbackDelay
+	if delay == 0 {
+		delay = defaultFallbackDelay
+	}
+	if len(fallbacks) == 0 || delay < 0 {
+		return c.dialSerial(ctx, network, port, addrs)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan dialResult)
+	returned := make(chan struct{})
+	defer close(returned)
+
+	race := func(primary bool, addrs []string) {
+		conn, err := c.dialSerial(ctx, network, port, addrs)
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary}:
+		case <-returned:
+			// Connection of the losing family is not needed anymore
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}
+
+	go race(true, primaries)
+	fallbackTimer := time.NewTimer(delay)
+	defer fallbackTimer.Stop()
+
+	var primaryErr, fallbackErr error
+	fallbackStarted := false
+	for {
+		select {
+		case <-fallbackTimer.C:
+			if !fallbackStarted {
+				fallbackStarted = true
+				go race(false, fallbacks)
+			}
+		case res := <-results:
+			if res.err == nil {
+				return res.conn, nil
+			}
+			if res.primary {
+				primaryErr = res.err
+			} else {
+				fallbackErr = res.err
+			}
+			if primaryErr != nil && fallbackErr != nil {
+				return nil, primaryErr
+			}
+			if res.primary && !fallbackStarted {
+				fallbackStarted = true
+				go race(false, fallbacks)
+			}
+		}
+	}
+}
+
+// dialSerial connects to the first reachable of addresses tried in order.
+// Time left until deadline is split between the addresses like net.Dialer does.
+func (c *Client) dialSerial(ctx context.Context, network, port string, addrs []string) (net.Conn, error) {
+	var lastErr error
+	for i, addr := range addrs {
+		dialCtx := ctx
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout := time.Until(deadline) / time.Duration(len(addrs)-i)
+			if timeout < 2*time.Second {
+				timeout = 2 * time.Second
+			}
+			var cancel context.CancelFunc
+			dialCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		conn, err := c.dial(dialCtx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// orderAddrs interleaves IPv4 and IPv6 addresses starting with the preferred family
+func orderAddrs(addrs []string, preferIPv6 bool) []string {
+	var preferred, other []string
+	for _, addr := range addrs {
+		if isIPv6(addr) == preferIPv6 {
+			preferred = append(preferred, addr)
+		} else {
+			other = append(other, addr)
+		}
+	}
+
+	ordered := make([]string, 0, len(addrs))
+	for i := 0; i < len(preferred) || i < len(other); i++ {
+		if i < len(preferred) {
+			ordered = append(ordered, preferred[i])
+		}
+		if i < len(other) {
+			ordered = append(ordered, other[i])
+		}
+	}
+	return ordered
+}
+
+// partitionAddrs splits addresses into those of the same family as the first one and the rest
+func partitionAddrs(addrs []string) (primaries, fallbacks []string) {
+	for _, addr := range addrs {
+		if isIPv6(addr) == isIPv6(addrs[0]) {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	return primaries, fallbacks
+}
+
+// isIPv6 reports whether address is an IPv6 one
+func isIPv6(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.To4() == nil
+}
+
+// dial connects to resolved address with custom dial function or client dialer
+func (c *Client) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if c.customDial != nil {
+		return c.customDial(ctx, network, address)
+	}
+	return c.dialer.DialContext(ctx, network, address)
+}
+
+// localTCPAddr returns local address for IP address or the first non link-local address
+// of network interface with given name, nil if there is no such address
+func localTCPAddr(addr string) *net.TCPAddr {
+	if ip := net.ParseIP(addr); ip != nil {
+		return &net.TCPAddr{IP: ip}
+	}
+	iface, err := net.InterfaceByName(addr)
+	if err != nil {
+		return nil
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			return &net.TCPAddr{IP: ipNet.IP}
+		}
+	}
+	return nil
+}
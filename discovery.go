@@ -0,0 +1,126 @@
+package twittertimeline
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebURL is the address of Twitter/X web application used for query ID discovery
+const WebURL = "https://x.com"
+
+// Regexes for parsing web application bundle
+var (
+	mainScriptRegex = regexp.MustCompile(`https://abs\.twimg\.com/responsive-web/client-web[^"]*/main\.[0-9a-zA-Z]+\.js`)
+	operationRegex  = regexp.MustCompile(`queryId:"([^"]+)",operationName:"([^"]+)",operationType:"[^"]*",metadata:\{featureSwitches:\[([^\]]*)\]`)
+)
+
+// graphQLOperation describes GraphQL operation discovered in web application bundle
+type graphQLOperation struct {
+	QueryID  string   // Current query ID (hash)
+	Features []string // Feature switches required by the operation
+}
+
+// operationDiscovery keeps GraphQL operations discovered from web application bundle
+type operationDiscovery struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	operations map[string]graphQLOperation
+	fetchedAt  time.Time
+}
+
+// operation returns discovered operation by name, refreshing the cache when it is expired
+func (d *operationDiscovery) operation(c *Client, name string) (graphQLOperation, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := c.clock.Now()
+	if d.fetchedAt.IsZero() || now.Sub(d.fetchedAt) > d.ttl {
+		// Failed discovery keeps previous results and is retried after TTL,
+		// so every attempt, successful or not, resets the fetch time
+		d.fetchedAt = now
+		if operations, err := c.discoverOperations(); err == nil {
+			d.operations = operations
+		}
+	}
+
+	op, ok := d.operations[name]
+	return op, ok
+}
+
+// discoverOperations fetches web application bundle and extracts GraphQL operations from it
+func (c *Client) discoverOperations() (map[string]graphQLOperation, error) {
+	var scriptURL string
+	err := c.withBody(WebURL, func(page []byte) error {
+		scriptURL = string(mainScriptRegex.Find(page))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching web application: %w", err)
+	}
+	if scriptURL == "" {
+		return nil, fmt.Errorf("main script not found in web application")
+	}
+
+	var operations map[string]graphQLOperation
+	err = c.withBody(scriptURL, func(script []byte) error {
+		operations = parseOperations(script)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching main script: %w", err)
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("no GraphQL operations found in main script")
+	}
+
+	return operations, nil
+}
+
+// parseOperations extracts GraphQL query IDs and feature switches from web application script
+func parseOperations(script []byte) map[string]graphQLOperation {
+	operations := make(map[string]graphQLOperation)
+	for _, match := range operationRegex.FindAllSubmatch(script, -1) {
+		var features []string
+		for _, feature := range strings.Split(string(match[3]), ",") {
+			feature = strings.Trim(feature, `"`)
+			if feature != "" {
+				features = append(features, feature)
+			}
+		}
+		operations[string(match[2])] = graphQLOperation{
+			QueryID:  string(match[1]),
+			Features: features,
+		}
+	}
+	return operations
+}
+
+// resolveOperation returns GraphQL endpoint with discovered query ID and features completed
+// with switches required by the operation. Without discovery the arguments are returned unchanged.
+func (c *Client) resolveOperation(endpoint string, features map[string]any) (string, map[string]any) {
+	if c.discovery == nil {
+		return endpoint, features
+	}
+
+	name := path.Base(endpoint)
+	op, ok := c.discovery.operation(c, name)
+	if !ok {
+		return endpoint, features
+	}
+
+	resolved := make(map[string]any, len(features))
+	for key, value := range features {
+		resolved[key] = value
+	}
+	for _, feature := range op.Features {
+		if _, ok := resolved[feature]; !ok {
+			resolved[feature] = false
+		}
+	}
+
+	return "/graphql/" + op.QueryID + "/" + name, resolved
+}
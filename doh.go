@@ -0,0 +1,146 @@
+package twittertimeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Well-known DNS-over-HTTPS providers with JSON API.
+// IP addresses are used so that resolver itself does not depend on system DNS.
+var dohProviders = map[string]string{
+	"cloudflare": "https://1.1.1.1/dns-query",
+	"google":     "https://8.8.8.8/resolve",
+	"quad9":      "https://9.9.9.9:5053/dns-query",
+}
+
+// DNS record types used in DoH queries
+const (
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+)
+
+// dohResponse represents DNS JSON API response
+type dohResponse struct {
+	Status int `json:"Status"`
+	Answer []struct {
+		Type int    `json:"type"`
+		TTL  int    `json:"TTL"`
+		Data string `json:"data"`
+	} `json:"Answer"`
+}
+
+// dohCacheEntry represents cached resolved addresses
+type dohCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// dohResolver resolves host names using DNS-over-HTTPS JSON API
+type dohResolver struct {
+	endpoint   string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]dohCacheEntry
+}
+
+// newDoHResolver creates resolver for provider name ("cloudflare", "google", "quad9") or DoH endpoint URL
+func newDoHResolver(provider string) *dohResolver {
+	endpoint := provider
+	if known, ok := dohProviders[strings.ToLower(provider)]; ok {
+		endpoint = known
+	}
+
+	return &dohResolver{
+		endpoint: endpoint,
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		},
+		cache: make(map[string]dohCacheEntry),
+	}
+}
+
+// lookup returns IPv4 and IPv6 addresses of the host. Lookup fails only if both
+// address families fail to resolve or the host has no addresses at all.
+func (r *dohResolver) lookup(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	entry, ok := r.cache[host]
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs4, ttl4, err4 := r.query(ctx, host, dnsTypeA)
+	addrs6, ttl6, err6 := r.query(ctx, host, dnsTypeAAAA)
+	if err4 != nil && err6 != nil {
+		return nil, fmt.Errorf("DoH lookup of %s failed: %w", host, err4)
+	}
+	addrs := append(addrs4, addrs6...)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("DoH lookup of %s failed: no addresses", host)
+	}
+
+	ttl := ttl4
+	if err4 != nil || (err6 == nil && ttl6 < ttl) {
+		ttl = ttl6
+	}
+
+	r.mu.Lock()
+	r.cache[host] = dohCacheEntry{addrs: addrs, expires: time.Now().Add(ttl)}
+	r.mu.Unlock()
+
+	return addrs, nil
+}
+
+// query requests records of given type and returns addresses with minimal TTL
+func (r *dohResolver) query(ctx context.Context, host string, recordType int) ([]string, time.Duration, error) {
+	params := url.Values{}
+	params.Add("name", host)
+	params.Add("type", fmt.Sprint(recordType))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", r.endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/dns-json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+
+	var dnsResp dohResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dnsResp); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %w", err)
+	}
+	if dnsResp.Status != 0 {
+		return nil, 0, fmt.Errorf("DNS error status: %d", dnsResp.Status)
+	}
+
+	var addrs []string
+	ttl := time.Hour
+	for _, answer := range dnsResp.Answer {
+		if answer.Type != recordType || net.ParseIP(answer.Data) == nil {
+			continue
+		}
+		addrs = append(addrs, answer.Data)
+		if answerTTL := time.Duration(answer.TTL) * time.Second; answerTTL < ttl {
+			ttl = answerTTL
+		}
+	}
+
+	return addrs, ttl, nil
+}
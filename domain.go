@@ -0,0 +1,75 @@
+package twittertimeline
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// secondLevelSuffixes are common public suffixes of two labels under which domains are registered,
+// e.g. example.co.uk. It is a compact subset of the Public Suffix List covering popular countries.
+var secondLevelSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true, "me.uk": true, "ltd.uk": true, "plc.uk": true,
+	"com.au": true, "net.au": true, "org.au": true, "edu.au": true, "gov.au": true,
+	"co.nz": true, "org.nz": true, "govt.nz": true, "ac.nz": true,
+	"co.jp": true, "ne.jp": true, "or.jp": true, "ac.jp": true, "go.jp": true,
+	"co.kr": true, "or.kr": true, "go.kr": true, "ac.kr": true,
+	"com.br": true, "net.br": true, "org.br": true, "gov.br": true,
+	"com.cn": true, "net.cn": true, "org.cn": true, "gov.cn": true,
+	"com.hk": true, "com.tw": true, "com.sg": true, "com.my": true,
+	"co.in": true, "net.in": true, "org.in": true, "gov.in": true, "ac.in": true,
+	"com.mx": true, "com.ar": true, "com.co": true, "com.pe": true, "com.tr": true, "gov.tr": true,
+	"co.za": true, "org.za": true, "gov.za": true, "com.ng": true, "co.ke": true,
+	"com.ua": true, "org.ua": true, "gov.ua": true, "com.ru": true, "org.ru": true,
+	"co.il": true, "org.il": true, "ac.il": true, "gov.il": true,
+	"com.pl": true, "com.es": true, "co.id": true, "or.id": true, "go.id": true, "co.th": true, "ac.th": true,
+	"com.vn": true, "com.ph": true, "com.pk": true, "com.sa": true, "com.eg": true,
+	"github.io": true, "blogspot.com": true, "substack.com": true,
+}
+
+// LinkDomains returns registrable domains of expanded links in tweet, e.g. "example.co.uk"
+// for "https://blog.example.co.uk/post", each domain once in order of appearance
+func (t *Tweet) LinkDomains() []string {
+	var domains []string
+	for _, link := range t.URLs {
+		domain := RegistrableDomain(link.Expanded)
+		if domain == "" {
+			continue
+		}
+		seen := false
+		for _, prev := range domains {
+			if prev == domain {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// RegistrableDomain returns domain under which link host is registered (eTLD+1), e.g. "example.com"
+// for "https://www.blog.example.com/post". IP addresses are returned as is.
+// It returns empty string for invalid links.
+func RegistrableDomain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	n := 2
+	if secondLevelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
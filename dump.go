@@ -0,0 +1,158 @@
+package twittertimeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// redactedHeaders are headers whose values are redacted in dumps
+var redactedHeaders = []string{"Authorization", "X-Guest-Token", "Cookie", "Set-Cookie", "X-Csrf-Token"}
+
+// unsafeNameRegex matches characters not allowed in dump directory names
+var unsafeNameRegex = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// requestDumper writes every request and response of the client into a directory per call
+type requestDumper struct {
+	dir    string
+	seq    atomic.Int64
+	logger *slog.Logger
+}
+
+// requestDump is the request part of a call dump
+type requestDump struct {
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	Query           map[string]any      `json:"query,omitempty"` // JSON parameters are decoded
+	Header          map[string][]string `json:"header"`
+	Status          int                 `json:"status,omitempty"`
+	ResponseHeader  map[string][]string `json:"response_header,omitempty"`
+	Error           string              `json:"error,omitempty"`
+	ResponseBodyRef string              `json:"response_body,omitempty"` // File name of response body
+}
+
+// dumpTransport dumps every call made through next transport
+type dumpTransport struct {
+	next   http.RoundTripper
+	dumper *requestDumper
+}
+
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	dump := newRequestDump(req)
+	if err != nil {
+		dump.Error = err.Error()
+		t.dumper.write(req, dump, nil)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		dump.Error = err.Error()
+		t.dumper.write(req, dump, nil)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	dump.Status = resp.StatusCode
+	dump.ResponseHeader = redactHeader(resp.Header)
+	dump.ResponseBodyRef = "response" + bodyExtension(resp.Header.Get("Content-Type"))
+	t.dumper.write(req, dump, body)
+	return resp, nil
+}
+
+// write saves call dump into a new directory. Failures are logged and do not affect the request.
+func (d *requestDumper) write(req *http.Request, dump *requestDump, body []byte) {
+	name := strings.TrimSuffix(path.Base(req.URL.Path), path.Ext(req.URL.Path))
+	name = unsafeNameRegex.ReplaceAllString(name, "_")
+	dir := filepath.Join(d.dir, fmt.Sprintf("%04d-%s", d.seq.Add(1), name))
+
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		var data []byte
+		data, err = json.MarshalIndent(dump, "", "  ")
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, "request.json"), data, 0o644)
+		}
+	}
+	if err == nil && dump.ResponseBodyRef != "" {
+		err = os.WriteFile(filepath.Join(dir, dump.ResponseBodyRef), body, 0o644)
+	}
+	if err != nil {
+		d.logger.Warn("failed to dump request", "dir", dir, "error", err)
+	}
+}
+
+// newRequestDump creates dump of request with decoded query and redacted credentials
+func newRequestDump(req *http.Request) *requestDump {
+	dump := &requestDump{
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+		Header: redactHeader(req.Header),
+	}
+
+	query := req.URL.Query()
+	if len(query) > 0 {
+		dump.Query = make(map[string]any, len(query))
+		for key := range query {
+			// Only objects and arrays are decoded to keep numeric IDs intact
+			value := query.Get(key)
+			var decoded any
+			if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+				if json.Unmarshal([]byte(value), &decoded) == nil {
+					dump.Query[key] = decoded
+					continue
+				}
+			}
+			dump.Query[key] = value
+		}
+		for _, param := range sensitiveParams {
+			if value := query.Get(param); value != "" {
+				dump.Query[param] = redactToken(value)
+			}
+		}
+	}
+	return dump
+}
+
+// redactHeader returns copy of header with credentials redacted
+func redactHeader(header http.Header) map[string][]string {
+	redacted := make(map[string][]string, len(header))
+	for key, values := range header {
+		redacted[key] = values
+	}
+	for _, key := range redactedHeaders {
+		values := header.Values(key)
+		if len(values) == 0 {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i] = redactToken(value)
+		}
+		redacted[key] = masked
+	}
+	return redacted
+}
+
+// bodyExtension returns file extension for response body with the content type
+func bodyExtension(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return ".json"
+	case strings.Contains(contentType, "html"):
+		return ".html"
+	default:
+		return ".txt"
+	}
+}
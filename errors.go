@@ -0,0 +1,161 @@
+package twittertimeline
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrChallenge is returned (wrapped in ChallengeError) when an anti-bot challenge page is received instead of API response
+var ErrChallenge = errors.New("anti-bot challenge received")
+
+// ErrRateLimited is returned (wrapped in RateLimitError) when API rate limit is exceeded
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ErrNotFound is returned (wrapped in NotFoundError) when requested user, tweet or list does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrNotModified is returned by GetUserTweetsIfChanged when timeline entries match the given ETag
+var ErrNotModified = errors.New("timeline not modified")
+
+// challengeSnippetLength limits size of response snippet stored in ChallengeError
+const challengeSnippetLength = 512
+
+// StatusError is returned when Twitter API responds with an unexpected HTTP status
+type StatusError struct {
+	StatusCode int    // HTTP status code
+	Body       string // Response body
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// ChallengeError is returned when an HTML challenge page (Cloudflare, etc.) is received instead of JSON
+type ChallengeError struct {
+	StatusCode int    // HTTP status code
+	URL        string // Requested URL
+	Snippet    string // Beginning of the challenge page
+}
+
+func (e *ChallengeError) Error() string {
+	return fmt.Sprintf("%v (status %d): %s", ErrChallenge, e.StatusCode, e.Snippet)
+}
+
+// Unwrap allows to match ChallengeError with errors.Is(err, ErrChallenge)
+func (e *ChallengeError) Unwrap() error {
+	return ErrChallenge
+}
+
+// RateLimitError is returned when API rate limit is exceeded
+type RateLimitError struct {
+	Reset time.Time // Time when the limit resets, zero if unknown
+}
+
+// NewRateLimitError creates RateLimitError resetting at the given time, e.g. to simulate rate limits in tests
+func NewRateLimitError(reset time.Time) *RateLimitError {
+	return &RateLimitError{Reset: reset}
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return "rate limit exceeded. Please wait and try again later"
+	}
+	return fmt.Sprintf("rate limit exceeded. Please wait until %s and try again", e.Reset.Format(time.RFC3339))
+}
+
+// Unwrap allows to match RateLimitError with errors.Is(err, ErrRateLimited)
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// newRateLimitError creates RateLimitError with reset time from X-Rate-Limit-Reset header of the response
+func newRateLimitError(resp *http.Response) *RateLimitError {
+	rateLimitErr := &RateLimitError{}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		rateLimitErr.Reset = time.Unix(reset, 0)
+	}
+	return rateLimitErr
+}
+
+// NotFoundError is returned when requested object does not exist or is not available to guests
+type NotFoundError struct {
+	Kind   string // Kind of object, e.g. "user" or "tweet"
+	ID     string // Requested ID, username or URL
+	Reason string // Explanation of unavailability given by API, e.g. tombstone text, empty if unknown
+}
+
+// NewNotFoundError creates NotFoundError for object of the kind, e.g. to simulate missing users in tests
+func NewNotFoundError(kind, id string) *NotFoundError {
+	return &NotFoundError{Kind: kind, ID: id}
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s not found: %s (%s)", e.Kind, e.ID, e.Reason)
+	}
+	return fmt.Sprintf("%s not found: %s", e.Kind, e.ID)
+}
+
+// Unwrap allows to match NotFoundError with errors.Is(err, ErrNotFound)
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// IsRateLimited reports whether the error is caused by exceeded rate limit
+// and returns the time when the limit resets, zero if unknown
+func IsRateLimited(err error) (time.Time, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.Reset, true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests {
+		return time.Time{}, true
+	}
+	return time.Time{}, false
+}
+
+// IsNotFound reports whether the error means that requested object does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// newChallengeError creates ChallengeError for the response with the given body
+func newChallengeError(resp *http.Response, body []byte) *ChallengeError {
+	snippet := string(body)
+	if len(snippet) > challengeSnippetLength {
+		snippet = snippet[:challengeSnippetLength]
+	}
+	challengeErr := &ChallengeError{
+		StatusCode: resp.StatusCode,
+		Snippet:    snippet,
+	}
+	if resp.Request != nil {
+		challengeErr.URL = resp.Request.URL.String()
+	}
+	return challengeErr
+}
+
+// isHTMLResponse reports whether API response is an HTML page instead of JSON
+func isHTMLResponse(resp *http.Response) bool {
+	return strings.Contains(resp.Header.Get("Content-Type"), "text/html")
+}
+
+// IsAccessBlocked reports whether the error means that guest access to the API is denied,
+// either by anti-bot challenge or by 401/403 status
+func IsAccessBlocked(err error) bool {
+	if errors.Is(err, ErrChallenge) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Regexes for feature flag errors returned by GraphQL API
+var (
+	missingFeaturesRegex  = regexp.MustCompile(`features cannot be null: ([\w, ]+)`)
+	obsoleteFeaturesRegex = regexp.MustCompile(`features are not (?:recognized|supported): ([\w, ]+)`)
+)
+
+// learnFeatures parses API error response and remembers required and obsolete features.
+// It returns true if new features were learned and the request should be retried.
+func (c *Client) learnFeatures(body []byte) bool {
+	var errResp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return false
+	}
+
+	c.featuresMu.Lock()
+	defer c.featuresMu.Unlock()
+
+	learned := false
+	for _, apiErr := range errResp.Errors {
+		if match := missingFeaturesRegex.FindStringSubmatch(apiErr.Message); match != nil {
+			for _, feature := range splitFeatures(match[1]) {
+				if !c.requiredFeatures[feature] {
+					if c.requiredFeatures == nil {
+						c.requiredFeatures = make(map[string]bool)
+					}
+					c.requiredFeatures[feature] = true
+					delete(c.obsoleteFeatures, feature)
+					learned = true
+				}
+			}
+		}
+		if match := obsoleteFeaturesRegex.FindStringSubmatch(apiErr.Message); match != nil {
+			for _, feature := range splitFeatures(match[1]) {
+				if !c.obsoleteFeatures[feature] {
+					if c.obsoleteFeatures == nil {
+						c.obsoleteFeatures = make(map[string]bool)
+					}
+					c.obsoleteFeatures[feature] = true
+					delete(c.requiredFeatures, feature)
+					learned = true
+				}
+			}
+		}
+	}
+
+	return learned
+}
+
+// adjustFeatures returns feature map with learned required features added (disabled) and obsolete removed
+func (c *Client) adjustFeatures(features map[string]any) map[string]any {
+	c.featuresMu.Lock()
+	defer c.featuresMu.Unlock()
+
+	if len(c.requiredFeatures) == 0 && len(c.obsoleteFeatures) == 0 {
+		return features
+	}
+
+	adjusted := make(map[string]any, len(features)+len(c.requiredFeatures))
+	for key, value := range features {
+		if !c.obsoleteFeatures[key] {
+			adjusted[key] = value
+		}
+	}
+	for feature := range c.requiredFeatures {
+		if _, ok := adjusted[feature]; !ok {
+			adjusted[feature] = false
+		}
+	}
+
+	return adjusted
+}
+
+// splitFeatures splits comma-separated list of feature names
+func splitFeatures(list string) []string {
+	var features []string
+	for _, feature := range strings.Split(list, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
@@ -0,0 +1,247 @@
+// Package feed renders tweets as RSS 2.0 feeds with media attachments as enclosures
+// and Media RSS elements for podcast-style and media-aware readers
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Namespaces of feed extensions
+const (
+	MediaNamespace = "http://search.yahoo.com/mrss/"
+	DCNamespace    = "http://purl.org/dc/elements/1.1/"
+)
+
+// TitleLength is the maximum number of characters in item title
+const TitleLength = 100
+
+// Channel describes the feed
+type Channel struct {
+	Title       string
+	Link        string
+	Description string
+	ImageURL    string
+}
+
+// ChannelFromUser returns channel describing timeline of user
+func ChannelFromUser(user *twittertimeline.User) Channel {
+	return Channel{
+		Title:       fmt.Sprintf("%s (@%s)", user.Name, user.Username),
+		Link:        "https://x.com/" + user.Username,
+		Description: user.Bio,
+		ImageURL:    user.AvatarURL,
+	}
+}
+
+// RSS is an RSS 2.0 document
+type RSS struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Media   string     `xml:"xmlns:media,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+// RSSChannel is a channel of RSS document
+type RSSChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Image       *RSSImage `xml:"image,omitempty"`
+	Generator   string    `xml:"generator"`
+	Items       []Item    `xml:"item"`
+}
+
+// RSSImage is a channel image
+type RSSImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+// Item is a tweet in RSS channel
+type Item struct {
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	Creator     string         `xml:"dc:creator,omitempty"`
+	PubDate     string         `xml:"pubDate,omitempty"`
+	GUID        GUID           `xml:"guid"`
+	Enclosure   *Enclosure     `xml:"enclosure,omitempty"`
+	Content     []MediaContent `xml:"media:content"`
+}
+
+// GUID is a unique item identifier
+type GUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// Enclosure is a media attachment of item. Size of attachments is unknown, so length is always 0.
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// MediaContent is a Media RSS content element
+type MediaContent struct {
+	URL       string          `xml:"url,attr"`
+	Type      string          `xml:"type,attr"`
+	Medium    string          `xml:"medium,attr"`
+	Duration  string          `xml:"duration,attr,omitempty"`
+	Thumbnail *MediaThumbnail `xml:"media:thumbnail,omitempty"`
+}
+
+// MediaThumbnail is a Media RSS thumbnail element
+type MediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+// NewRSS returns RSS document of tweets
+func NewRSS(channel Channel, tweets []twittertimeline.Tweet) *RSS {
+	rss := &RSS{
+		Version: "2.0",
+		Media:   MediaNamespace,
+		DC:      DCNamespace,
+		Channel: RSSChannel{
+			Title:       channel.Title,
+			Link:        channel.Link,
+			Description: channel.Description,
+			Generator:   "twitter-timeline",
+			Items:       make([]Item, 0, len(tweets)),
+		},
+	}
+	if channel.ImageURL != "" {
+		rss.Channel.Image = &RSSImage{URL: channel.ImageURL, Title: channel.Title, Link: channel.Link}
+	}
+	for i := range tweets {
+		rss.Channel.Items = append(rss.Channel.Items, NewItem(&tweets[i]))
+	}
+	return rss
+}
+
+// NewItem returns RSS item of tweet. The first attachment is the enclosure,
+// all attachments are listed as Media RSS content.
+func NewItem(tweet *twittertimeline.Tweet) Item {
+	// Tweet text comes HTML-escaped from API, while XML encoder escapes it again
+	text := html.UnescapeString(tweet.Text)
+	item := Item{
+		Title:       title(text),
+		Link:        tweet.PermanentURL,
+		Description: tweet.HTML,
+		GUID:        GUID{Value: tweet.PermanentURL, IsPermaLink: true},
+	}
+	if item.Description == "" {
+		item.Description = text
+	}
+	if item.GUID.Value == "" {
+		item.GUID = GUID{Value: tweet.ID}
+	}
+	if tweet.Username != "" {
+		item.Creator = "@" + tweet.Username
+	}
+	if createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt); err == nil {
+		item.PubDate = createdAt.Format(time.RFC1123Z)
+	}
+
+	for _, media := range attachments(tweet) {
+		content := MediaContent{
+			URL:    media.URL,
+			Type:   mediaType(media),
+			Medium: medium(media.Type),
+		}
+		if media.Duration > 0 {
+			content.Duration = strconv.Itoa(int(media.Duration.Round(time.Second).Seconds()))
+		}
+		if media.PreviewURL != "" {
+			content.Thumbnail = &MediaThumbnail{URL: media.PreviewURL}
+		}
+		if item.Enclosure == nil {
+			item.Enclosure = &Enclosure{URL: content.URL, Type: content.Type}
+		}
+		item.Content = append(item.Content, content)
+	}
+	return item
+}
+
+// WriteRSS writes tweets to w as RSS 2.0 feed
+func WriteRSS(w io.Writer, channel Channel, tweets []twittertimeline.Tweet) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(NewRSS(channel, tweets)); err != nil {
+		return fmt.Errorf("error encoding feed: %w", err)
+	}
+	return enc.Close()
+}
+
+// attachments returns media of tweet, falling back to images of tweets without media details
+func attachments(tweet *twittertimeline.Tweet) []twittertimeline.Media {
+	if len(tweet.Media) > 0 {
+		return tweet.Media
+	}
+	var media []twittertimeline.Media
+	for _, image := range tweet.Images {
+		if image != "" {
+			media = append(media, twittertimeline.Media{Type: twittertimeline.MediaPhoto, URL: image})
+		}
+	}
+	return media
+}
+
+// title returns the first line of text, truncated to TitleLength characters
+func title(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= TitleLength {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:TitleLength-1])) + "…"
+}
+
+// mediaType returns MIME type of attachment by its extension, falling back to media type
+func mediaType(media twittertimeline.Media) string {
+	if u, err := url.Parse(media.URL); err == nil {
+		if mimeType := mime.TypeByExtension(path.Ext(u.Path)); mimeType != "" {
+			return mimeType
+		}
+	}
+	switch media.Type {
+	case twittertimeline.MediaVideo, twittertimeline.MediaAnimatedGIF:
+		return "video/mp4"
+	case twittertimeline.MediaAudio:
+		return "audio/mp4"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// medium returns Media RSS medium of media type
+func medium(mediaType string) string {
+	switch mediaType {
+	case twittertimeline.MediaVideo, twittertimeline.MediaAnimatedGIF:
+		return "video"
+	case twittertimeline.MediaAudio:
+		return "audio"
+	default:
+		return "image"
+	}
+}
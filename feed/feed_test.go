@@ -0,0 +1,128 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+func TestWriteRSS(t *testing.T) {
+	user := twittertimeline.User{Username: "alice", Name: "Alice", Bio: "Gopher", AvatarURL: "https://pbs.twimg.com/a.jpg"}
+	tweets := []twittertimeline.Tweet{
+		{
+			ID:           "2",
+			Text:         "Video\nsecond line",
+			HTML:         "Video<br>second line",
+			CreatedAt:    "Mon Jan 01 09:30:00 +0000 2024",
+			PermanentURL: "https://x.com/alice/status/2",
+			Username:     "alice",
+			Media: []twittertimeline.Media{
+				{Type: twittertimeline.MediaVideo, URL: "https://video.twimg.com/2.mp4?tag=12", PreviewURL: "https://pbs.twimg.com/2.jpg", Duration: 1500 * time.Millisecond},
+				{Type: twittertimeline.MediaPhoto, URL: "https://pbs.twimg.com/media/b.png"},
+			},
+		},
+		{
+			ID:       "1",
+			Text:     strings.Repeat("a", 150),
+			Username: "alice",
+			Images:   []string{"https://pbs.twimg.com/media/a.jpg"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRSS(&buf, ChannelFromUser(&user), tweets); err != nil {
+		t.Fatalf("WriteRSS failed: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{
+		`<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/" xmlns:dc="http://purl.org/dc/elements/1.1/">`,
+		`<title>Alice (@alice)</title>`,
+		`<enclosure url="https://video.twimg.com/2.mp4?tag=12" length="0" type="video/mp4"></enclosure>`,
+		`<media:content url="https://pbs.twimg.com/media/b.png" type="image/png" medium="image"></media:content>`,
+		`<media:thumbnail url="https://pbs.twimg.com/2.jpg"></media:thumbnail>`,
+		`<enclosure url="https://pbs.twimg.com/media/a.jpg" length="0" type="image/jpeg"></enclosure>`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %s in output:\n%s", expected, output)
+		}
+	}
+
+	// Namespaced elements must be readable by namespace-aware parsers
+	var parsed struct {
+		Items []struct {
+			Title   string `xml:"title"`
+			PubDate string `xml:"pubDate"`
+			GUID    string `xml:"guid"`
+			Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Content []struct {
+				Medium   string `xml:"medium,attr"`
+				Duration string `xml:"duration,attr"`
+			} `xml:"http://search.yahoo.com/mrss/ content"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+	if len(parsed.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(parsed.Items))
+	}
+	first := parsed.Items[0]
+	if first.Title != "Video" || first.PubDate != "Mon, 01 Jan 2024 09:30:00 +0000" || first.Creator != "@alice" {
+		t.Errorf("Unexpected item: %+v", first)
+	}
+	if len(first.Content) != 2 || first.Content[0].Medium != "video" || first.Content[0].Duration != "2" {
+		t.Errorf("Unexpected media content: %+v", first.Content)
+	}
+	second := parsed.Items[1]
+	if utf8Len := len([]rune(second.Title)); utf8Len != TitleLength || !strings.HasSuffix(second.Title, "…") {
+		t.Errorf("Unexpected truncated title: %q", second.Title)
+	}
+	if second.GUID != "1" {
+		t.Errorf("Expected tweet ID as GUID without permalink, got %q", second.GUID)
+	}
+}
+
+func TestNewItemEscapedText(t *testing.T) {
+	item := NewItem(&twittertimeline.Tweet{ID: "1", Text: "Tom &amp; Jerry &lt;3"})
+	if item.Title != "Tom & Jerry <3" || item.Description != "Tom & Jerry <3" {
+		t.Errorf("Expected unescaped title and description, got %q and %q", item.Title, item.Description)
+	}
+
+	var buf bytes.Buffer
+	if err := xml.NewEncoder(&buf).Encode(item); err != nil {
+		t.Fatalf("Failed to encode item: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<title>Tom &amp; Jerry &lt;3</title>") {
+		t.Errorf("Expected single escaping in output: %s", buf.String())
+	}
+}
+
+func TestWriteOPML(t *testing.T) {
+	users := []twittertimeline.User{
+		{ID: "1", Username: "alice", Name: "Alice"},
+		{ID: "2", Username: "bob", Name: "Bob & Co"},
+	}
+
+	var buf bytes.Buffer
+	err := WriteOPML(&buf, "Watch list", users, func(user *twittertimeline.User) string {
+		return "http://localhost:8080/" + user.Username + "/rss"
+	})
+	if err != nil {
+		t.Fatalf("WriteOPML failed: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{
+		`<opml version="2.0">`,
+		`<title>Watch list</title>`,
+		`<outline type="rss" text="Alice (@alice)" title="Alice (@alice)" xmlUrl="http://localhost:8080/alice/rss" htmlUrl="https://x.com/alice"></outline>`,
+		`text="Bob &amp; Co (@bob)"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %s in output:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// OPML is an OPML 2.0 document with feed subscriptions
+type OPML struct {
+	XMLName xml.Name  `xml:"opml"`
+	Version string    `xml:"version,attr"`
+	Title   string    `xml:"head>title"`
+	Outline []Outline `xml:"body>outline"`
+}
+
+// Outline is a feed subscription of OPML document
+type Outline struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+}
+
+// NewOPML returns OPML document subscribing to feeds of users, feedURL returns feed URL of user
+func NewOPML(title string, users []twittertimeline.User, feedURL func(user *twittertimeline.User) string) *OPML {
+	opml := &OPML{Version: "2.0", Title: title, Outline: make([]Outline, 0, len(users))}
+	for i := range users {
+		user := &users[i]
+		channel := ChannelFromUser(user)
+		opml.Outline = append(opml.Outline, Outline{
+			Type:    "rss",
+			Text:    channel.Title,
+			Title:   channel.Title,
+			XMLURL:  feedURL(user),
+			HTMLURL: channel.Link,
+		})
+	}
+	return opml
+}
+
+// WriteOPML writes OPML document with feeds of users to w for importing the watch list into feed readers
+func WriteOPML(w io.Writer, title string, users []twittertimeline.User, feedURL func(user *twittertimeline.User) string) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(NewOPML(title, users, feedURL)); err != nil {
+		return fmt.Errorf("error encoding OPML: %w", err)
+	}
+	return enc.Close()
+}
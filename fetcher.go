@@ -0,0 +1,59 @@
+package twittertimeline
+
+import (
+	"io"
+	"time"
+)
+
+// TimelineFetcher is the data access API of Client. Applications can depend on it instead of *Client
+// to substitute a mock in their unit tests. Configuration methods (Use, OnTweet, OnPage) are not part of it.
+type TimelineFetcher interface {
+	// Timelines
+	GetUserTweets(userID string) ([]Tweet, error)
+	GetUserTweetsIfChanged(userID, etag string) ([]Tweet, string, error)
+	GetUserTweetsOrStale(userID string, maxStale time.Duration) ([]Tweet, time.Duration, error)
+	GetAllUserTweets(userID string, maxPages int) ([]Tweet, error)
+	GetTimelines(userIDs []string, concurrency int) (map[string][]Tweet, map[string]error)
+	GetPinnedTweet(userID string) (*Tweet, error)
+	GetSyndicationTweet(tweetID string) (*Tweet, error)
+
+	// Tweets
+	GetTweets(ids []string) (map[string]*Tweet, map[string]error)
+	RefreshMetrics(tweets []Tweet) (map[string]MetricsDelta, map[string]error)
+	CheckTweets(ids []string) map[string]Status
+
+	// Search
+	SearchTweets(query string, opts SearchOptions) ([]Tweet, error)
+	GetHashtagTweets(tag string, opts SearchOptions) ([]Tweet, error)
+
+	// Trends
+	GetTrends(woeid int) ([]Trend, error)
+	GetTrendsByPlace(place string) ([]Trend, error)
+	GetTrendLocations() ([]TrendLocation, error)
+	GetExploreTrends() ([]Trend, error)
+
+	// Users
+	GetUserID(username string) (string, error)
+	ResolveUser(input string) (*UserRef, error)
+	GetUserByScreenName(screenName string) (*UserResponse, error)
+	GetUserProfile(username string) (*User, error)
+	GetUserByID(userID string) (*User, error)
+	GetRelationship(sourceID, targetID string) (*Relationship, error)
+	SearchUsers(query string, maxPages int) ([]User, error)
+	CheckUser(username string) (Status, error)
+	CheckUserByID(username, userID string) (Status, string, error)
+
+	// Lists
+	GetListInfo(listID string) (*List, error)
+	GetListByURL(listURL string) (*List, error)
+
+	// Media and Spaces
+	DownloadMedia(mediaURL string, w io.Writer) (int64, error)
+	GetSpace(spaceID string) (*Space, error)
+
+	Stats() map[string]EndpointStats
+	Close() error
+}
+
+// Client implements TimelineFetcher
+var _ TimelineFetcher = (*Client)(nil)
@@ -0,0 +1,108 @@
+package twittertimeline
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Filter is a tweet predicate, filters are combined with And, Or and Not
+type Filter func(tweet *Tweet) bool
+
+// FilterTweets returns tweets matching the filter. The tweets slice is not modified.
+func FilterTweets(tweets []Tweet, filter Filter) []Tweet {
+	var filtered []Tweet
+	for i := range tweets {
+		if filter(&tweets[i]) {
+			filtered = append(filtered, tweets[i])
+		}
+	}
+	return filtered
+}
+
+// And matches tweets matching all filters
+func And(filters ...Filter) Filter {
+	return func(tweet *Tweet) bool {
+		for _, filter := range filters {
+			if !filter(tweet) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches tweets matching any of filters
+func Or(filters ...Filter) Filter {
+	return func(tweet *Tweet) bool {
+		for _, filter := range filters {
+			if filter(tweet) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches tweets not matching the filter
+func Not(filter Filter) Filter {
+	return func(tweet *Tweet) bool {
+		return !filter(tweet)
+	}
+}
+
+// HasMedia matches tweets with photos, videos, GIFs or audio
+func HasMedia() Filter {
+	return func(tweet *Tweet) bool {
+		return len(tweet.Media) > 0 || len(tweet.Images) > 0
+	}
+}
+
+// LangIs matches tweets in the given language, e.g. "en".
+// DetectedLang is used for tweets with undetermined language.
+func LangIs(lang string) Filter {
+	return func(tweet *Tweet) bool {
+		tweetLang := tweet.Lang
+		if tweetLang == "und" || tweetLang == "" {
+			tweetLang = tweet.DetectedLang
+		}
+		return tweetLang != "" && strings.EqualFold(tweetLang, lang)
+	}
+}
+
+// After matches tweets created after t
+func After(t time.Time) Filter {
+	return func(tweet *Tweet) bool {
+		createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt)
+		return err == nil && createdAt.After(t)
+	}
+}
+
+// Before matches tweets created before t
+func Before(t time.Time) Filter {
+	return func(tweet *Tweet) bool {
+		createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt)
+		return err == nil && createdAt.Before(t)
+	}
+}
+
+// MatchesRegexp matches tweets whose text matches re
+func MatchesRegexp(re *regexp.Regexp) Filter {
+	return func(tweet *Tweet) bool {
+		return re.MatchString(tweet.Text)
+	}
+}
+
+// MinLikes matches tweets with at least n likes
+func MinLikes(n int) Filter {
+	return func(tweet *Tweet) bool {
+		return tweet.Likes >= n
+	}
+}
+
+// IsOriginal matches tweets that are neither retweets nor replies
+func IsOriginal() Filter {
+	return func(tweet *Tweet) bool {
+		return !tweet.IsRetweet && !tweet.IsReply
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/n0madic/twitter-timeline
 
-go 1.18
\ No newline at end of file
+go 1.21
\ No newline at end of file
@@ -0,0 +1,188 @@
+package twittertimeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// secCHUA is the sec-ch-ua client hint matching Chrome version claimed in UserAgent
+var secCHUA = func() string {
+	version := "136"
+	if match := regexp.MustCompile(`Chrome/(\d+)`).FindStringSubmatch(UserAgent); match != nil {
+		version = match[1]
+	}
+	return fmt.Sprintf(`"Chromium";v="%s", "Google Chrome";v="%s", "Not.A/Brand";v="99"`, version, version)
+}()
+
+// browserHeaderOrder lists request headers in the order Chrome sends them to API hosts
+var browserHeaderOrder = []string{
+	"Host",
+	"Connection",
+	"Content-Length",
+	"Sec-Ch-Ua-Platform",
+	"Authorization",
+	"X-Twitter-Client-Language",
+	"Sec-Ch-Ua",
+	"Sec-Ch-Ua-Mobile",
+	"X-Twitter-Active-User",
+	"X-Guest-Token",
+	"X-Twitter-Client",
+	"User-Agent",
+	"Content-Type",
+	"Accept",
+	"Origin",
+	"Sec-Fetch-Site",
+	"Sec-Fetch-Mode",
+	"Sec-Fetch-Dest",
+	"Referer",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Cookie",
+	"Priority",
+}
+
+// headerRank maps lowercased header name to its position in browserHeaderOrder
+var headerRank = func() map[string]int {
+	rank := make(map[string]int, len(browserHeaderOrder))
+	for i, name := range browserHeaderOrder {
+		rank[strings.ToLower(name)] = i
+	}
+	return rank
+}()
+
+// setBrowserHeaders sets headers sent by Chrome for cross-origin API requests from x.com web application.
+// net/http writes headers sorted by name, WithHeaderOrder makes them follow browserHeaderOrder on the wire.
+func setBrowserHeaders(req *http.Request) {
+	req.Header.Set("Sec-Ch-Ua-Platform", `"Windows"`)
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Sec-Ch-Ua", secCHUA)
+	req.Header.Set("Sec-Ch-Ua-Mobile", "?0")
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Origin", "https://x.com")
+	req.Header.Set("Sec-Fetch-Site", "same-site")
+	req.Header.Set("Sec-Fetch-Mode", "cors")
+	req.Header.Set("Sec-Fetch-Dest", "empty")
+	req.Header.Set("Referer", "https://x.com/")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Priority", "u=1, i")
+}
+
+// dialTLSContext connects to address over TLS limited to HTTP/1.1, so that headers
+// of requests pass through orderedConn before being encrypted
+func (c *Client) dialTLSContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := c.dialAddress(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{}
+	if transport, ok := c.transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		config = transport.TLSClientConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	config.NextProtos = []string{"http/1.1"}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &orderedConn{Conn: tlsConn}, nil
+}
+
+// orderedConn rewrites header blocks of HTTP/1.1 requests written to the connection,
+// reordering header lines by browserHeaderOrder. Unknown headers follow the known ones.
+type orderedConn struct {
+	net.Conn
+	head        []byte // Incomplete header block of the current request
+	body        int64  // Bytes of the current request body left to pass through
+	passthrough bool   // Request framing is not tracked anymore, e.g. after chunked body
+}
+
+// Write buffers header block of the request until it is complete and passes body through
+func (c *orderedConn) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		switch {
+		case c.passthrough:
+			if _, err := c.Conn.Write(p); err != nil {
+				return 0, err
+			}
+			p = nil
+		case c.body > 0:
+			n := int64(len(p))
+			if n > c.body {
+				n = c.body
+			}
+			if _, err := c.Conn.Write(p[:n]); err != nil {
+				return 0, err
+			}
+			c.body -= n
+			p = p[n:]
+		default:
+			c.head = append(c.head, p...)
+			p = nil
+			end := bytes.Index(c.head, []byte("\r\n\r\n"))
+			if end < 0 {
+				continue
+			}
+			head, rest := c.head[:end+4], c.head[end+4:]
+			c.head = nil
+			if _, err := c.Conn.Write(c.reorder(head)); err != nil {
+				return 0, err
+			}
+			p = rest
+		}
+	}
+	return written, nil
+}
+
+// reorder sorts header lines of request header block and detects framing of its body
+func (c *orderedConn) reorder(head []byte) []byte {
+	lines := bytes.Split(head[:len(head)-4], []byte("\r\n"))
+	headers := lines[1:]
+
+	// Tunnel carries opaque bytes after its header block
+	c.body = 0
+	if bytes.HasPrefix(lines[0], []byte("CONNECT ")) {
+		c.passthrough = true
+	}
+
+	ranked := make([][]byte, len(browserHeaderOrder))
+	var unknown [][]byte
+	for _, line := range headers {
+		name, value, _ := bytes.Cut(line, []byte(":"))
+		lowerName := string(bytes.ToLower(name))
+		switch lowerName {
+		case "content-length":
+			c.body, _ = strconv.ParseInt(string(bytes.TrimSpace(value)), 10, 64)
+		case "transfer-encoding":
+			c.passthrough = true
+		}
+		if i, ok := headerRank[lowerName]; ok && ranked[i] == nil {
+			ranked[i] = line
+		} else {
+			unknown = append(unknown, line)
+		}
+	}
+
+	ordered := make([]byte, 0, len(head))
+	ordered = append(ordered, lines[0]...)
+	ordered = append(ordered, "\r\n"...)
+	for _, line := range append(ranked, unknown...) {
+		if line != nil {
+			ordered = append(ordered, line...)
+			ordered = append(ordered, "\r\n"...)
+		}
+	}
+	return append(ordered, "\r\n"...)
+}
@@ -0,0 +1,112 @@
+package twittertimeline
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HedgeHost is the alternate API host receiving hedged requests, see WithHedging
+const HedgeHost = "api.twitter.com"
+
+// hedgeTransport sends a second copy of API request to HedgeHost if the first one is not answered
+// within delay, and returns whichever response comes first
+type hedgeTransport struct {
+	next   http.RoundTripper
+	delay  time.Duration
+	client *Client // Source of clock
+}
+
+// hedgeResult is an outcome of one of hedged requests
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+func (t *hedgeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.URL.Host != apiHost() {
+		return t.next.RoundTrip(req)
+	}
+
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := t.next.RoundTrip(r.WithContext(ctx))
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+	send(req)
+	pending := 1
+
+	ticker := t.client.clock.Ticker(t.delay)
+	defer ticker.Stop()
+	hedge := ticker.Chan()
+
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			hedged := req.Clone(req.Context())
+			hedged.URL.Host = HedgeHost
+			hedged.Host = ""
+			send(hedged)
+			pending++
+		case result := <-results:
+			pending--
+			if result.err != nil && pending > 0 {
+				cancels[result.index]()
+				continue
+			}
+
+			// Cancel the losing request, keeping context of the winner until its body is closed
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go discardHedgeResults(results, pending)
+			}
+			if result.err != nil {
+				cancels[result.index]()
+				return nil, result.err
+			}
+			result.resp.Body = &cancelingBody{ReadCloser: result.resp.Body, cancel: cancels[result.index]}
+			return result.resp, nil
+		}
+	}
+}
+
+// discardHedgeResults closes responses of losing requests
+func discardHedgeResults(results <-chan hedgeResult, pending int) {
+	for ; pending > 0; pending-- {
+		if result := <-results; result.resp != nil {
+			result.resp.Body.Close()
+		}
+	}
+}
+
+// cancelingBody cancels request context once response body is closed
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// apiHost returns host of BaseURL
+func apiHost() string {
+	u, _ := url.Parse(BaseURL)
+	return u.Host
+}
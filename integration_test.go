@@ -0,0 +1,262 @@
+package twittertimeline_test
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/n0madic/twitter-timeline/twittertest"
+)
+
+// Integration tests run against twittertest server with recorded fixtures, so they need no network
+
+const (
+	InvalidUserID   = "999999999999999999"
+	InvalidUsername = "thisusernameshouldnotexist123456789"
+)
+
+func TestGetGuestToken(t *testing.T) {
+	server := twittertest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	var guestToken string
+	client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if token := req.Header.Get("X-Guest-Token"); token != "" {
+				guestToken = token
+			}
+			return next.RoundTrip(req)
+		})
+	})
+
+	if err := client.GetGuestToken(); err != nil {
+		t.Fatalf("GetGuestToken() failed: %v", err)
+	}
+	if _, err := client.GetUserID(twittertest.ScreenName); err != nil {
+		t.Fatalf("GetUserID() failed: %v", err)
+	}
+
+	if guestToken != twittertest.GuestToken {
+		t.Errorf("Expected guest token %s to be sent, got %q", twittertest.GuestToken, guestToken)
+	}
+	if requests := server.Requests(); len(requests) == 0 || requests[0] != "/1.1/guest/activate.json" {
+		t.Errorf("Expected guest activation first, got %v", requests)
+	}
+}
+
+func TestGetUserTweets_ValidUserID(t *testing.T) {
+	server := twittertest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	tweets, err := client.GetUserTweets(twittertest.UserID)
+	if err != nil {
+		t.Fatalf("GetUserTweets() failed for valid user ID: %v", err)
+	}
+
+	if len(tweets) == 0 {
+		t.Fatal("No tweets returned for active user")
+	}
+
+	// Test first tweet structure
+	tweet := tweets[0]
+
+	// Basic fields
+	if tweet.ID == "" {
+		t.Error("Tweet ID is empty")
+	}
+	if tweet.Text == "" {
+		t.Error("Tweet text is empty")
+	}
+	if tweet.CreatedAt == "" {
+		t.Error("Tweet CreatedAt is empty")
+	}
+	if tweet.Username == "" {
+		t.Error("Tweet Username is empty")
+	}
+	if tweet.UserID == "" {
+		t.Error("Tweet UserID is empty")
+	}
+
+	// Check PermanentURL format
+	expectedPrefix := fmt.Sprintf("https://x.com/%s/status/", tweet.Username)
+	if !strings.HasPrefix(tweet.PermanentURL, expectedPrefix) {
+		t.Errorf("PermanentURL format incorrect: %s", tweet.PermanentURL)
+	}
+	if !strings.HasSuffix(tweet.PermanentURL, tweet.ID) {
+		t.Errorf("PermanentURL doesn't end with tweet ID: %s", tweet.PermanentURL)
+	}
+
+	// HTML should be generated
+	if tweet.HTML == "" {
+		t.Error("HTML content is empty")
+	}
+
+	// Statistics should be non-negative
+	if tweet.Likes < 0 {
+		t.Error("Likes count is negative")
+	}
+	if tweet.Retweets < 0 {
+		t.Error("Retweets count is negative")
+	}
+	if tweet.Replies < 0 {
+		t.Error("Replies count is negative")
+	}
+}
+
+func TestGetUserTweets_InvalidUserID(t *testing.T) {
+	server := twittertest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	// Unknown users get empty timeline rather than error
+	tweets, err := client.GetUserTweets(InvalidUserID)
+	if err != nil {
+		t.Fatalf("GetUserTweets() failed for invalid user ID: %v", err)
+	}
+	if len(tweets) != 0 {
+		t.Errorf("Expected no tweets for invalid user ID, got %d", len(tweets))
+	}
+}
+
+func TestGetUserID_ValidUsername(t *testing.T) {
+	server := twittertest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	userID, err := client.GetUserID(twittertest.ScreenName)
+	if err != nil {
+		t.Fatalf("GetUserID() failed for valid username: %v", err)
+	}
+
+	if userID != twittertest.UserID {
+		t.Errorf("Expected user ID %s, got %s", twittertest.UserID, userID)
+	}
+}
+
+func TestGetUserID_InvalidUsername(t *testing.T) {
+	server := twittertest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	userID, err := client.GetUserID(InvalidUsername)
+	if err == nil {
+		t.Error("Expected error for invalid username")
+	}
+
+	if userID != "" {
+		t.Error("Should return empty user ID for invalid username")
+	}
+}
+
+func TestIntegration_FullWorkflow(t *testing.T) {
+	server := twittertest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	userID, err := client.GetUserID(twittertest.ScreenName)
+	if err != nil {
+		t.Fatalf("GetUserID() failed: %v", err)
+	}
+	tweets, err := client.GetUserTweets(userID)
+	if err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+	if len(tweets) == 0 {
+		t.Fatal("No tweets returned")
+	}
+
+	foundTweetWithImages := false
+	foundTweetWithHashtags := false
+	foundTweetWithURLs := false
+	foundTweetWithMentions := false
+
+	foundPinned := false
+	foundRetweet := false
+	foundReply := false
+
+	numericRegex := regexp.MustCompile(`^\d+$`)
+
+	for i, tweet := range tweets {
+		if tweet.ID == "" {
+			t.Errorf("Tweet %d has empty ID", i)
+		}
+		if tweet.Text == "" {
+			t.Errorf("Tweet %d has empty text", i)
+		}
+		if tweet.Username == "" {
+			t.Errorf("Tweet %d has empty username", i)
+		}
+		if tweet.PermanentURL == "" {
+			t.Errorf("Tweet %d has empty permanent URL", i)
+		}
+		if tweet.HTML == "" {
+			t.Errorf("Tweet %d has empty HTML", i)
+		}
+
+		// URL parsing
+		for _, url := range tweet.URLs {
+			foundTweetWithURLs = true
+			if url.Short == "" || url.Display == "" {
+				t.Error("URL structure incomplete")
+			}
+		}
+		// Hashtag extraction
+		for _, hashtag := range tweet.Hashtags {
+			foundTweetWithHashtags = true
+			if hashtag == "" || strings.HasPrefix(hashtag, "#") {
+				t.Errorf("Invalid hashtag %q", hashtag)
+			}
+		}
+		// Mention extraction
+		for _, mention := range tweet.Mentions {
+			foundTweetWithMentions = true
+			if mention == "" || strings.HasPrefix(mention, "@") {
+				t.Errorf("Invalid mention %q", mention)
+			}
+		}
+		// Image extraction
+		for _, image := range tweet.Images {
+			foundTweetWithImages = true
+			if !strings.HasPrefix(image, "https://") {
+				t.Error("Image URL should be HTTPS")
+			}
+		}
+
+		if !numericRegex.MatchString(tweet.UserID) {
+			t.Errorf("UserID should be numeric: %s", tweet.UserID)
+		}
+		if !numericRegex.MatchString(tweet.ID) {
+			t.Errorf("Tweet ID should be numeric: %s", tweet.ID)
+		}
+
+		foundPinned = foundPinned || tweet.IsPinned
+		foundRetweet = foundRetweet || tweet.IsRetweet
+		foundReply = foundReply || tweet.IsReply
+	}
+
+	// Recorded timeline covers all of these
+	if !foundTweetWithImages || !foundTweetWithHashtags || !foundTweetWithURLs || !foundTweetWithMentions {
+		t.Errorf("Found tweets with: images=%v, hashtags=%v, URLs=%v, mentions=%v",
+			foundTweetWithImages, foundTweetWithHashtags, foundTweetWithURLs, foundTweetWithMentions)
+	}
+	if !foundPinned || !foundRetweet || !foundReply {
+		t.Errorf("Tweet types found: pinned=%v, retweet=%v, reply=%v", foundPinned, foundRetweet, foundReply)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,185 @@
+// Package jsonschema generates JSON Schema (draft 2020-12) documents describing JSON encoding
+// of Tweet and User, so integrators can validate output and generate code from it
+package jsonschema
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Draft is the JSON Schema dialect of generated documents
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// BaseID is the prefix of IDs of generated documents
+const BaseID = "https://github.com/n0madic/twitter-timeline/jsonschema/"
+
+var (
+	tweetSchema = sync.OnceValue(func() []byte {
+		return Generate(BaseID+"tweet.json", "twitter-timeline tweet", twittertimeline.Tweet{})
+	})
+	userSchema = sync.OnceValue(func() []byte {
+		return Generate(BaseID+"user.json", "twitter-timeline user", twittertimeline.User{})
+	})
+)
+
+// Tweet returns JSON Schema of Tweet
+func Tweet() []byte {
+	return tweetSchema()
+}
+
+// User returns JSON Schema of User
+func User() []byte {
+	return userSchema()
+}
+
+// Handler serves schemas as "tweet.json" and "user.json" by the last element of request path,
+// e.g. mounted at "/schema/" it serves "/schema/tweet.json" and "/schema/user.json"
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var schema []byte
+		switch path.Base(r.URL.Path) {
+		case "tweet.json":
+			schema = Tweet()
+		case "user.json":
+			schema = User()
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write(schema)
+	})
+}
+
+// Generate returns indented JSON Schema of encoding/json output for type of v.
+// Named struct types are described in $defs and referenced by name.
+func Generate(id, title string, v any) []byte {
+	g := &generator{defs: map[string]any{}}
+	root := g.schema(reflect.TypeOf(v))
+
+	document := map[string]any{
+		"$schema": Draft,
+		"$id":     id,
+		"title":   title,
+		"$defs":   g.defs,
+	}
+	for key, value := range root {
+		document[key] = value
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		panic(err) // schema consists of maps, slices and strings only
+	}
+	return data
+}
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// generator collects definitions of named struct types
+type generator struct {
+	defs map[string]any
+}
+
+// schema returns schema of type t
+func (g *generator) schema(t reflect.Type) map[string]any {
+	switch t {
+	case durationType:
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds"}
+	case rawMessageType:
+		return map[string]any{"description": "Raw JSON"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return nullable(map[string]any{"type": "array", "items": g.schema(t.Elem())})
+	case reflect.Map:
+		return nullable(map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = nil // placeholder for recursive types
+			g.defs[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object returns schema of struct type t
+func (g *generator) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	g.fields(t, properties, &required)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// fields adds fields of struct type t to properties, flattening embedded structs like encoding/json
+func (g *generator) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.fields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := properties[name]; ok {
+			continue
+		}
+		properties[name] = g.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable allows null in addition to schema
+func nullable(schema map[string]any) map[string]any {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+		return schema
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
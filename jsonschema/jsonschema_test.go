@@ -0,0 +1,194 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// validate checks value against subset of JSON Schema used by generated documents
+func validate(t *testing.T, root map[string]any, path string, value any, schema map[string]any) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		if value == nil {
+			return
+		}
+		schema = anyOf[0].(map[string]any)
+		validate(t, root, path, value, schema)
+		return
+	}
+
+	var types []string
+	switch typ := schema["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []any:
+		for _, typ := range typ {
+			types = append(types, typ.(string))
+		}
+	default:
+		return // any value
+	}
+
+	var actual string
+	switch value.(type) {
+	case nil:
+		actual = "null"
+	case bool:
+		actual = "boolean"
+	case float64:
+		actual = "number"
+		if slices.Contains(types, "integer") {
+			actual = "integer"
+		}
+	case string:
+		actual = "string"
+	case []any:
+		actual = "array"
+	case map[string]any:
+		actual = "object"
+	}
+	if !slices.Contains(types, actual) {
+		t.Errorf("%s: expected %v, got %s", path, types, actual)
+		return
+	}
+
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			validate(t, root, path+"[]", item, schema["items"].(map[string]any))
+		}
+	case map[string]any:
+		properties := schema["properties"].(map[string]any)
+		for _, key := range schema["required"].([]any) {
+			if _, ok := value[key.(string)]; !ok {
+				t.Errorf("%s: required %s is missing", path, key)
+			}
+		}
+		for key, field := range value {
+			property, ok := properties[key]
+			if !ok {
+				t.Errorf("%s: field %s is not described in schema", path, key)
+				continue
+			}
+			validate(t, root, path+"."+key, field, property.(map[string]any))
+		}
+	}
+}
+
+func checkSchema(t *testing.T, schema []byte, v any) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+	if root["$schema"] != Draft {
+		t.Errorf("Unexpected dialect: %v", root["$schema"])
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value any
+	json.Unmarshal(data, &value)
+	validate(t, root, "$", value, root)
+}
+
+func TestTweet(t *testing.T) {
+	tweet := twittertimeline.Tweet{
+		ID:           "2",
+		Text:         "Hello #go",
+		CreatedAt:    "Mon Jan 01 09:30:00 +0000 2024",
+		Likes:        5,
+		IsQuoted:     true,
+		Raw:          json.RawMessage(`{"rest_id":"2"}`),
+		Images:       []string{"https://pbs.twimg.com/media/a.jpg"},
+		Media:        []twittertimeline.Media{{Type: twittertimeline.MediaVideo, Duration: time.Second}},
+		Hashtags:     []string{"go"},
+		URLs:         []twittertimeline.URL{{Short: "https://t.co/a"}},
+		UserMentions: []twittertimeline.Mention{{Username: "bob"}},
+		Entities:     []twittertimeline.Entity{{Type: twittertimeline.EntityHashtag, Value: "go", Start: 6, End: 9}},
+		Community:    &twittertimeline.Community{ID: "1"},
+		Restrictions: &twittertimeline.Restrictions{LimitedActions: []string{"Reply"}},
+		QuotedTweet: &twittertimeline.QuotedTweet{
+			Tweet:       twittertimeline.Tweet{ID: "1", Hashtags: []string{"quoted"}},
+			Unavailable: true,
+		},
+	}
+	checkSchema(t, Tweet(), tweet)
+	checkSchema(t, Tweet(), twittertimeline.Tweet{})
+}
+
+func TestUser(t *testing.T) {
+	user := twittertimeline.User{
+		ID:        "42",
+		Username:  "alice",
+		BioURLs:   []twittertimeline.URL{{Short: "https://t.co/b"}},
+		Followers: 10,
+		Affiliate: &twittertimeline.Affiliate{Name: "Org"},
+	}
+	checkSchema(t, User(), user)
+	checkSchema(t, User(), twittertimeline.User{})
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	for path, expected := range map[string][]byte{"/schema/tweet.json": Tweet(), "/schema/user.json": User()} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/schema+json" || !bytes.Equal(body, expected) {
+			t.Errorf("Unexpected response for %s: %d %s", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/schema/post.json")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown schema, got %d", resp.StatusCode)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	type node struct {
+		Name     string  `json:"name"`
+		Children []*node `json:"children,omitempty"`
+		Hidden   string  `json:"-"`
+	}
+
+	var schema struct {
+		Ref  string `json:"$ref"`
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Generate("id", "node", node{}), &schema); err != nil {
+		t.Fatal(err)
+	}
+	def := schema.Defs["node"]
+	if schema.Ref != "#/$defs/node" || len(def.Properties) != 2 || !slices.Equal(def.Required, []string{"name"}) {
+		t.Errorf("Unexpected schema: %+v", schema)
+	}
+	if children := string(def.Properties["children"]); !strings.Contains(children, `"#/$defs/node"`) {
+		t.Errorf("Expected recursive reference, got %s", children)
+	}
+}
@@ -0,0 +1,147 @@
+package twittertimeline
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// langNoiseRegex matches parts of tweet text that carry no language: links, mentions, hashtags and cashtags
+var langNoiseRegex = regexp.MustCompile(`https?://\S+|[@#$][\p{L}\p{N}_]+`)
+
+// scriptLanguages maps Unicode scripts used by a single major language to its code
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Armenian, "hy"},
+	{unicode.Georgian, "ka"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+	{unicode.Tamil, "ta"},
+}
+
+// stopwords are frequent short words of languages written in Latin script
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "to", "of", "in", "for", "you", "this", "that", "with", "it", "my", "on", "was", "have", "be"},
+	"es": {"el", "la", "los", "las", "de", "que", "y", "en", "es", "por", "para", "con", "una", "un", "del", "muy", "pero", "como"},
+	"pt": {"o", "os", "as", "de", "que", "e", "em", "um", "uma", "para", "com", "não", "do", "da", "muito", "mas", "você", "é"},
+	"fr": {"le", "la", "les", "de", "des", "et", "est", "un", "une", "pour", "dans", "que", "qui", "pas", "sur", "avec", "je", "vous"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "mit", "den", "für", "auf", "ich", "sie", "auch", "es", "wir"},
+	"it": {"il", "la", "di", "che", "e", "un", "una", "per", "non", "sono", "con", "del", "della", "anche", "ma", "questo", "gli", "è"},
+	"nl": {"de", "het", "een", "en", "van", "is", "niet", "op", "dat", "met", "voor", "zijn", "ik", "je", "ook", "maar", "wat", "naar"},
+	"tr": {"ve", "bir", "bu", "da", "de", "için", "ne", "çok", "ile", "gibi", "daha", "ama", "ben", "sen", "mi", "var", "yok", "olarak"},
+}
+
+// detectLanguage guesses language code of text by script and, for Latin script, by frequent words.
+// It returns empty string if the language can't be determined.
+func detectLanguage(text string) string {
+	text = langNoiseRegex.ReplaceAllString(text, " ")
+
+	var letters, latin, cyrillic, arabic, han int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Han, r):
+			han++
+		default:
+			for _, script := range scriptLanguages {
+				if unicode.Is(script.table, r) {
+					scripts[script.lang]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters, so any kana wins over Chinese
+	if scripts["ja"] > 0 && scripts["ja"]+han >= letters/2 {
+		return "ja"
+	}
+	for lang, count := range scripts {
+		if count*2 >= letters {
+			return lang
+		}
+	}
+	switch {
+	case han*2 >= letters:
+		return "zh"
+	case cyrillic*2 >= letters:
+		return cyrillicLanguage(text)
+	case arabic*2 >= letters:
+		// Letters absent from Arabic alphabet
+		if strings.ContainsAny(text, "پچژگ") {
+			return "fa"
+		}
+		return "ar"
+	case latin*2 >= letters:
+		return latinLanguage(text)
+	}
+	return ""
+}
+
+// cyrillicLanguage distinguishes languages written in Cyrillic script by their specific letters
+func cyrillicLanguage(text string) string {
+	lower := strings.ToLower(text)
+	switch {
+	case strings.ContainsAny(lower, "іїєґ"):
+		return "uk"
+	case strings.ContainsAny(lower, "ў"):
+		return "be"
+	case strings.ContainsAny(lower, "ђћџљњ"):
+		return "sr"
+	case strings.ContainsAny(lower, "ѓќѕ"):
+		return "mk"
+	}
+	return "ru"
+}
+
+// latinLanguage guesses language written in Latin script by counting stopwords.
+// It returns empty string if no language has a clear lead.
+func latinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	best, bestScore, secondScore := "", 0, 0
+	for lang, list := range stopwords {
+		score := 0
+		for _, word := range words {
+			for _, stopword := range list {
+				if word == stopword {
+					score++
+					break
+				}
+			}
+		}
+		if score > bestScore {
+			secondScore = bestScore
+			best, bestScore = lang, score
+		} else if score > secondScore {
+			secondScore = score
+		}
+	}
+	if bestScore == 0 || bestScore == secondScore {
+		return ""
+	}
+	return best
+}
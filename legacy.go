@@ -0,0 +1,107 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Constants for legacy v1.1 REST API
+const (
+	LegacyBaseURL      = "https://api.twitter.com"
+	AndroidBearerToken = "AAAAAAAAAAAAAAAAAAAAAFXzAwAAAAAAMHCxpeSDG1gLNLghVe8d74hl6k4%3DRUMF4xAQLsbeBhTSRrCiQpJtxoGWeyHrDb5te2jpGskWDFW82F"
+
+	// Legacy API endpoints
+	LegacyUserTimelinePath   = "/1.1/statuses/user_timeline.json"
+	LegacyFriendshipShowPath = "/1.1/friendships/show.json"
+	LegacyTrendsPlacePath    = "/1.1/trends/place.json"
+	LegacyTrendsAvailable    = "/1.1/trends/available.json"
+)
+
+// getLegacyUserTweets gets user timeline from v1.1 REST API using Android app bearer token
+func (c *Client) getLegacyUserTweets(userID string) ([]Tweet, error) {
+	params := url.Values{}
+	params.Add("user_id", userID)
+	params.Add("count", "100")
+	params.Add("include_rts", "1")
+	params.Add("tweet_mode", "extended")
+
+	resp, err := c.makeLegacyAPICall(LegacyUserTimelinePath, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rawTweets []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&rawTweets); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	var tweets []Tweet
+	for _, rawTweet := range rawTweets {
+		tweetResult, err := parseV1Tweet(rawTweet)
+		if err != nil {
+			return nil, err
+		}
+		processTweetResult(tweetResult, c.render)
+		if tweetResult.Legacy.FullText != "" {
+			tweets = append(tweets, convertTweetResult(tweetResult))
+		}
+	}
+
+	return tweets, nil
+}
+
+// makeLegacyAPICall makes GET request to v1.1 REST API authorized with Android app bearer token.
+// Non-200 responses are returned as StatusError.
+func (c *Client) makeLegacyAPICall(path string, params url.Values) (*http.Response, error) {
+	guestToken, err := c.currentLegacyGuestToken()
+	if err != nil {
+		return nil, fmt.Errorf("error getting guest token: %w", err)
+	}
+
+	req, err := http.NewRequest("GET", LegacyBaseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+AndroidBearerToken)
+	req.Header.Set("User-Agent", "TwitterAndroid/10.21.0-release.0 (310210000-r-0) ONEPLUS+A3010/9 (OnePlus;ONEPLUS+A3010;OnePlus;OnePlus3;0;;1;2016)")
+	req.Header.Set("X-Guest-Token", guestToken)
+	req.Header.Set("X-Twitter-Active-User", "yes")
+	req.Header.Set("X-Twitter-Client", "TwitterAndroid")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		// Guest token may be expired, request a new one next time
+		c.tokenMu.Lock()
+		c.legacyGuestToken = ""
+		c.tokenMu.Unlock()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return resp, nil
+}
+
+// currentLegacyGuestToken returns guest token for Android bearer token, requesting it if needed
+func (c *Client) currentLegacyGuestToken() (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.legacyGuestToken == "" {
+		guestToken, err := c.activateGuestToken(LegacyBaseURL, AndroidBearerToken)
+		if err != nil {
+			return "", err
+		}
+		c.legacyGuestToken = guestToken
+	}
+	return c.legacyGuestToken, nil
+}
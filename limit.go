@@ -0,0 +1,145 @@
+package twittertimeline
+
+import (
+	"container/heap"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Priorities of queued requests, higher are sent first
+const (
+	priorityNormal = iota
+	priorityGuestToken
+)
+
+// requestLimiter caps number of in-flight requests. Waiting requests are served by priority,
+// in FIFO order within the same priority.
+type requestLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	queue    waitQueue
+	seq      uint64
+}
+
+// waiter is a request waiting for a free slot
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int // Position in queue, -1 once the slot is granted
+	ready    chan struct{}
+}
+
+// waitQueue is a heap of waiters
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	w.index = -1
+	return w
+}
+
+// acquire waits for a free slot. It returns false if done is closed before the slot is granted.
+func (l *requestLimiter) acquire(priority int, done <-chan struct{}) bool {
+	l.mu.Lock()
+	if l.inFlight < l.limit && l.queue.Len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return true
+	}
+	l.seq++
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.queue, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-done:
+		l.mu.Lock()
+		granted := w.index < 0
+		if !granted {
+			heap.Remove(&l.queue, w.index)
+		}
+		l.mu.Unlock()
+		if granted {
+			l.release()
+		}
+		return false
+	}
+}
+
+// release frees a slot, passing it to the next waiting request
+func (l *requestLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.queue.Len() > 0 {
+		w := heap.Pop(&l.queue).(*waiter)
+		close(w.ready)
+		return
+	}
+	l.inFlight--
+}
+
+// limitTransport holds a slot of limiter from sending request until its response body is closed
+type limitTransport struct {
+	next    http.RoundTripper
+	limiter *requestLimiter
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	priority := priorityNormal
+	if strings.HasSuffix(req.URL.Path, "/guest/activate.json") {
+		priority = priorityGuestToken
+	}
+	if !t.limiter.acquire(priority, req.Context().Done()) {
+		return nil, req.Context().Err()
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.limiter.release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.limiter.release}
+	return resp, nil
+}
+
+// releasingBody releases limiter slot once the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
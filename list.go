@@ -0,0 +1,107 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// List is a Twitter/X list
+type List struct {
+	ID          string // List ID
+	Name        string // List name
+	Description string // List description
+	URL         string // Link to list page
+	Members     int    // MemberCount
+	Subscribers int    // SubscriberCount
+	IsPrivate   bool   // List is visible only to its owner
+	Owner       *User  // List owner, nil if not included in response
+}
+
+// ListResponse represents response of ListByRestId and ListBySlug queries
+type ListResponse struct {
+	Data struct {
+		List struct {
+			IDStr           string `json:"id_str"`
+			Name            string `json:"name"`
+			Description     string `json:"description"`
+			MemberCount     int    `json:"member_count"`
+			SubscriberCount int    `json:"subscriber_count"`
+			Mode            string `json:"mode"`
+			UserResults     struct {
+				Result *UserResult `json:"result"`
+			} `json:"user_results"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+// GetListInfo gets list metadata by list ID
+func (c *Client) GetListInfo(listID string) (*List, error) {
+	variables := map[string]any{
+		"listId": listID,
+	}
+	return c.getList(ListByRestIDPath, variables, listID)
+}
+
+// GetListByURL gets list metadata by list URL ("https://x.com/i/lists/123"),
+// legacy slug URL ("https://twitter.com/name/lists/slug") or numeric list ID
+func (c *Client) GetListByURL(listURL string) (*List, error) {
+	listURL = strings.TrimSpace(listURL)
+	if userIDRegex.MatchString(listURL) {
+		return c.GetListInfo(listURL)
+	}
+
+	match := profileRegex.FindStringSubmatch(listURL)
+	if match == nil || match[2] != "lists" || match[3] == "" {
+		return nil, fmt.Errorf("not a list URL: %s", listURL)
+	}
+	if match[1] == "i" {
+		if !userIDRegex.MatchString(match[3]) {
+			return nil, fmt.Errorf("invalid list ID: %s", listURL)
+		}
+		return c.GetListInfo(match[3])
+	}
+	if !usernameRegex.MatchString(match[1]) {
+		return nil, fmt.Errorf("invalid list owner: %s", listURL)
+	}
+
+	variables := map[string]any{
+		"screenName": match[1],
+		"listSlug":   match[3],
+	}
+	return c.getList(ListBySlugPath, variables, listURL)
+}
+
+// getList requests list by GraphQL query and converts it to List structure
+func (c *Client) getList(endpoint string, variables map[string]any, ref string) (*List, error) {
+	resp, err := c.makeAPICall(endpoint, variables, userFeatures(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var listResp ListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	result := &listResp.Data.List
+	if result.IDStr == "" {
+		return nil, &NotFoundError{Kind: "list", ID: ref}
+	}
+
+	list := &List{
+		ID:          result.IDStr,
+		Name:        result.Name,
+		Description: result.Description,
+		URL:         "https://x.com/i/lists/" + result.IDStr,
+		Members:     result.MemberCount,
+		Subscribers: result.SubscriberCount,
+		IsPrivate:   result.Mode == "Private",
+	}
+	if result.UserResults.Result != nil && result.UserResults.Result.RestID != "" {
+		list.Owner = convertUserResult(result.UserResults.Result)
+	}
+
+	return list, nil
+}
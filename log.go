@@ -0,0 +1,89 @@
+package twittertimeline
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sensitiveParams are query parameters whose values are redacted in logs
+var sensitiveParams = []string{"guest_token", "access_token", "auth_token"}
+
+// discardHandler is slog handler dropping all records, used when no logger is set
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// discardLogger is the default logger of the client
+var discardLogger = slog.New(discardHandler{})
+
+// loggingTransport logs every HTTP request made by the client
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.logger.Warn("request failed",
+			"method", req.Method,
+			"url", redactURL(req.URL),
+			"duration", elapsed,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	level := slog.LevelDebug
+	if resp.StatusCode >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+	t.logger.Log(req.Context(), level, "request",
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"status", resp.StatusCode,
+		"duration", elapsed,
+		"guest_token", redactToken(req.Header.Get("X-Guest-Token")),
+	)
+	return resp, nil
+}
+
+// redactURL returns URL string with values of sensitive query parameters redacted
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for _, param := range sensitiveParams {
+		if value := query.Get(param); value != "" {
+			query.Set(param, redactToken(value))
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
+
+// redactToken hides token except its last characters, enough to tell tokens apart
+func redactToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return "***" + token[len(token)-4:]
+}
@@ -0,0 +1,106 @@
+package twittertimeline
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Media types
+const (
+	MediaPhoto       = "photo"
+	MediaVideo       = "video"
+	MediaAnimatedGIF = "animated_gif"
+	MediaAudio       = "audio"
+)
+
+// Media is a media attachment of tweet
+type Media struct {
+	Type       string        // Media type: MediaPhoto, MediaVideo, MediaAnimatedGIF or MediaAudio for voice tweets
+	URL        string        // Media file URL, the highest bitrate variant for videos and audio
+	PreviewURL string        // Preview image URL of videos and audio
+	Duration   time.Duration // Duration of videos and audio
+}
+
+// tweetMedia collects media attachments of tweet including audio of voice tweets,
+// which come as video with audio-only variants or as audio player card
+func tweetMedia(tweetResult *TweetResult) []Media {
+	entities := tweetResult.Legacy.ExtendedEntities.Media
+	if len(entities) == 0 {
+		entities = tweetResult.Legacy.Entities.Media
+	}
+
+	var media []Media
+	if n := len(entities); n > 0 {
+		media = make([]Media, 0, n)
+	}
+	for i := range entities {
+		entity := &entities[i]
+		if entity.MediaURLHTTPS == "" && len(entity.VideoInfo.Variants) == 0 {
+			continue
+		}
+		if entity.Type == MediaPhoto || len(entity.VideoInfo.Variants) == 0 {
+			media = append(media, Media{Type: entity.Type, URL: entity.MediaURLHTTPS})
+			continue
+		}
+
+		// Pick the highest bitrate progressive variant, streaming playlists have no bitrate
+		item := Media{
+			Type:       entity.Type,
+			PreviewURL: entity.MediaURLHTTPS,
+			Duration:   time.Duration(entity.VideoInfo.DurationMillis) * time.Millisecond,
+		}
+		bitrate := -1
+		hasAudio, hasVideo := false, false
+		for _, variant := range entity.VideoInfo.Variants {
+			isAudio := strings.HasPrefix(variant.ContentType, "audio/")
+			hasAudio = hasAudio || isAudio
+			hasVideo = hasVideo || strings.HasPrefix(variant.ContentType, "video/")
+			if (isAudio || variant.ContentType == "video/mp4") && variant.Bitrate > bitrate {
+				item.URL = variant.URL
+				bitrate = variant.Bitrate
+			}
+		}
+		if item.URL == "" {
+			item.URL = entity.VideoInfo.Variants[0].URL
+		}
+		if hasAudio && !hasVideo {
+			item.Type = MediaAudio
+		}
+		media = append(media, item)
+	}
+
+	if audio, ok := cardAudio(tweetResult); ok {
+		media = append(media, audio)
+	}
+
+	return media
+}
+
+// cardAudio extracts audio attachment from player card of tweet
+func cardAudio(tweetResult *TweetResult) (Media, bool) {
+	var audio Media
+	var contentType string
+	for _, binding := range tweetResult.Card.Legacy.BindingValues {
+		value := binding.Value.StringValue
+		switch binding.Key {
+		case "player_stream_url":
+			audio.URL = value
+		case "player_stream_content_type":
+			contentType = value
+		case "player_image_large", "player_image":
+			if audio.PreviewURL == "" || binding.Key == "player_image_large" {
+				audio.PreviewURL = binding.Value.ImageValue.URL
+			}
+		case "content_duration_seconds":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				audio.Duration = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if audio.URL == "" || !strings.HasPrefix(contentType, "audio/") {
+		return Media{}, false
+	}
+	audio.Type = MediaAudio
+	return audio, true
+}
@@ -0,0 +1,39 @@
+package twittertimeline
+
+import "net/http"
+
+// Middleware wraps HTTP transport of the client, e.g. to inject headers, log or record requests
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Use adds middlewares to the client's request chain.
+// Middlewares are applied in the order they were added: the first one sees the request first.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.buildTransportChain()
+}
+
+// buildTransportChain wraps base transport with registered middlewares
+func (c *Client) buildTransportChain() {
+	var transport http.RoundTripper = c.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	transport = &statsTransport{next: transport, stats: &c.stats}
+	if c.hedgeDelay > 0 {
+		transport = &hedgeTransport{next: transport, delay: c.hedgeDelay, client: c}
+	}
+	if c.limiter != nil {
+		transport = &limitTransport{next: transport, limiter: c.limiter}
+	}
+	if c.dumper != nil {
+		c.dumper.logger = c.logger
+		transport = &dumpTransport{next: transport, dumper: c.dumper}
+	}
+	if c.logger != discardLogger {
+		transport = &loggingTransport{next: transport, logger: c.logger}
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		transport = c.middlewares[i](transport)
+	}
+	c.httpClient.Transport = transport
+}
@@ -0,0 +1,137 @@
+package twittertimeline
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Regexes for parsing Nitter RSS items
+var (
+	nitterStatusRegex = regexp.MustCompile(`/status/(\d+)`)
+	nitterImageRegex  = regexp.MustCompile(`<img src="([^"]+)"`)
+)
+
+// NitterRSS represents Nitter user timeline RSS feed
+type NitterRSS struct {
+	Channel struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+			Link        string `xml:"link"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// getNitterUserTweets gets user timeline from the RSS feed of configured Nitter instance
+func (c *Client) getNitterUserTweets(userID string) ([]Tweet, error) {
+	screenName, err := c.nitterScreenName(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed NitterRSS
+	err = c.withBody(c.nitterInstance+"/"+url.PathEscape(screenName)+"/rss", func(body []byte) error {
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tweets []Tweet
+	for _, item := range feed.Channel.Items {
+		match := nitterStatusRegex.FindStringSubmatch(item.Link)
+		if match == nil {
+			continue
+		}
+
+		tweetResult := &TweetResult{RestID: match[1]}
+		tweetResult.Legacy.UserIDStr = userID
+		tweetResult.Core.UserResults.Result.Core.ScreenName = strings.TrimPrefix(item.Creator, "@")
+		if createdAt, err := time.Parse(time.RFC1123, item.PubDate); err == nil {
+			tweetResult.Legacy.CreatedAt = createdAt.Format(time.RubyDate)
+		}
+
+		// Nitter marks retweets and replies with title prefixes
+		text := item.Title
+		isRetweet := strings.HasPrefix(text, "RT by @")
+		isReply := strings.HasPrefix(text, "R to @")
+		if isRetweet || isReply {
+			if i := strings.Index(text, ": "); i >= 0 {
+				text = text[i+2:]
+			}
+		}
+		tweetResult.Legacy.FullText = text
+
+		for _, hashtag := range hashtagRegex.FindAllStringSubmatch(text, -1) {
+			tweetResult.Legacy.Entities.Hashtags = append(tweetResult.Legacy.Entities.Hashtags, HashtagEntity{Text: hashtag[1]})
+		}
+		for _, image := range nitterImageRegex.FindAllStringSubmatch(item.Description, -1) {
+			tweetResult.Legacy.ExtendedEntities.Media = append(tweetResult.Legacy.ExtendedEntities.Media, MediaEntity{
+				MediaURLHTTPS: nitterImageURL(image[1]),
+				Type:          "photo",
+			})
+		}
+
+		processTweetResult(tweetResult, c.render)
+		tweetResult.IsRetweet = isRetweet
+		tweetResult.IsReply = isReply
+		if tweetResult.Legacy.FullText != "" {
+			tweets = append(tweets, convertTweetResult(tweetResult))
+		}
+	}
+
+	return tweets, nil
+}
+
+// nitterScreenName resolves user ID to screen name using the cache or Nitter user ID redirect
+func (c *Client) nitterScreenName(userID string) (string, error) {
+	var screenName string
+	userIDCache.Range(func(key, value any) bool {
+		if value.(*userIDCacheEntry).UserID == userID {
+			screenName = key.(string)
+			return false
+		}
+		return true
+	})
+	if screenName != "" {
+		return screenName, nil
+	}
+
+	// Nitter redirects /i/user/<id> to the profile page
+	resp, err := c.httpClient.Get(c.nitterInstance + "/i/user/" + url.PathEscape(userID))
+	if err != nil {
+		return "", fmt.Errorf("error executing request: %w", err)
+	}
+	resp.Body.Close()
+
+	screenName = strings.Trim(resp.Request.URL.Path, "/")
+	if screenName == "" || strings.Contains(screenName, "/") {
+		return "", &NotFoundError{Kind: "user", ID: userID}
+	}
+
+	return screenName, nil
+}
+
+// nitterImageURL converts Nitter image proxy URL to original Twitter media URL
+func nitterImageURL(imageURL string) string {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return imageURL
+	}
+
+	path, err := url.PathUnescape(strings.TrimPrefix(u.EscapedPath(), "/pic/"))
+	if err != nil || !strings.HasPrefix(path, "media/") {
+		return imageURL
+	}
+
+	return "https://pbs.twimg.com/" + path
+}
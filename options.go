@@ -0,0 +1,330 @@
+package twittertimeline
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client
+type Option func(*Client)
+
+// httpTransport returns base transport of the HTTP client, replacing the default one with its clone
+// so that it can be tuned without affecting other clients
+func (c *Client) httpTransport() *http.Transport {
+	if transport, ok := c.transport.(*http.Transport); ok {
+		return transport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = c.dialContext
+	c.transport = transport
+	c.buildTransportChain()
+	return transport
+}
+
+// WithSyndicationFallback enables fallback to the public syndication API
+// when GraphQL guest access is blocked
+func WithSyndicationFallback() Option {
+	return func(c *Client) {
+		c.syndicationFallback = true
+	}
+}
+
+// WithLegacyAPIFallback enables fallback to the v1.1 REST API authorized with Android app bearer token
+// when GraphQL guest access is blocked
+func WithLegacyAPIFallback() Option {
+	return func(c *Client) {
+		c.legacyFallback = true
+	}
+}
+
+// WithNitterFallback enables a Nitter instance (e.g. "https://nitter.net")
+// as a last-resort source when GraphQL guest access is blocked.
+// Nitter is tried after all other enabled fallbacks.
+func WithNitterFallback(instanceURL string) Option {
+	return func(c *Client) {
+		c.nitterInstance = strings.TrimSuffix(instanceURL, "/")
+	}
+}
+
+// WithQueryIDDiscovery enables discovery of current GraphQL query IDs and feature switches
+// from x.com web application bundle. Discovered values are cached for ttl.
+// Hardcoded query IDs are used when discovery fails.
+func WithQueryIDDiscovery(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.discovery = &operationDiscovery{ttl: ttl}
+	}
+}
+
+// WithDNSOverHTTPS resolves API host names using DNS-over-HTTPS instead of system resolver.
+// Provider is either a well-known name ("cloudflare", "google", "quad9")
+// or URL of a DoH endpoint supporting JSON API.
+func WithDNSOverHTTPS(provider string) Option {
+	return func(c *Client) {
+		c.resolver = newDoHResolver(provider)
+		c.httpTransport().DialContext = c.dialContext
+	}
+}
+
+// WithChallengeSolver sets a callback invoked when an anti-bot challenge page is received.
+// If the solver returns nil, the request is retried once.
+func WithChallengeSolver(solver func(*ChallengeError) error) Option {
+	return func(c *Client) {
+		c.challengeSolver = solver
+	}
+}
+
+// WithPageParallelism sets how many timeline pages may be fetched ahead and converted concurrently
+// while following cursors in GetAllUserTweets. Values less than 1 are ignored.
+func WithPageParallelism(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.pageParallelism = n
+		}
+	}
+}
+
+// WithPageDelay sets delay between requests of timeline pages in GetAllUserTweets, randomized by
+// jitter fraction (0.25 means ±25%). Zero delay disables it. Default is DefaultPageDelay ± DefaultPageJitter.
+func WithPageDelay(d time.Duration, jitter float64) Option {
+	return func(c *Client) {
+		c.pageDelay = max(d, 0)
+		c.pageJitter = min(max(jitter, 0), 1)
+	}
+}
+
+// WithMaxInFlight limits number of concurrent requests of all goroutines using the client to n,
+// preventing self-inflicted rate limiting by bursts. Excess requests wait in FIFO order, guest token
+// requests go first. A request holds its slot until response body is closed. Zero disables the limit.
+func WithMaxInFlight(n int) Option {
+	return func(c *Client) {
+		c.limiter = nil
+		if n > 0 {
+			c.limiter = &requestLimiter{limit: n}
+		}
+		c.buildTransportChain()
+	}
+}
+
+// WithRetryBudget enables retries of transient failures (5xx responses, timeouts, reset or refused
+// connections and truncated responses) throttled by a budget shared by all goroutines using the client, like gRPC retry throttling: each failure
+// takes one of maxTokens, each success returns ratio of a token, and requests are retried only while
+// more than half of tokens are left. So during an outage retries stop instead of amplifying load.
+func WithRetryBudget(maxTokens, ratio float64) Option {
+	return func(c *Client) {
+		c.retryBudget = nil
+		if maxTokens > 0 {
+			c.retryBudget = newRetryBudget(maxTokens, ratio)
+		}
+	}
+}
+
+// WithHedging sends a hedged copy of API request to HedgeHost if the response does not arrive
+// within delay and takes whichever answers first, trading extra requests for lower tail latency.
+// Zero delay disables hedging.
+func WithHedging(delay time.Duration) Option {
+	return func(c *Client) {
+		c.hedgeDelay = max(delay, 0)
+		c.buildTransportChain()
+	}
+}
+
+// WithResponseCache caches up to maxEntries successful GraphQL responses for ttl, keyed by endpoint and
+// variables, so frequent polling of the same timelines does not cause extra upstream calls.
+// Least recently used responses are evicted first. Zero maxEntries or ttl disables the cache.
+func WithResponseCache(maxEntries int, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.responseCache = nil
+		if maxEntries > 0 && ttl > 0 {
+			c.responseCache = newResponseCache(maxEntries, ttl)
+		}
+	}
+}
+
+// WithLocalAddr binds outgoing connections to local IP address or to the first address of network
+// interface with given name, e.g. to spread rate limits of several clients over egress IPs of the host.
+// Unknown addresses are ignored.
+func WithLocalAddr(addr string) Option {
+	return func(c *Client) {
+		if localAddr := localTCPAddr(addr); localAddr != nil {
+			c.dialer.LocalAddr = localAddr
+			c.httpTransport().DialContext = c.dialContext
+		}
+	}
+}
+
+// WithPreferIPv6 connects over IPv6 when host has IPv6 addresses, falling back to IPv4
+func WithPreferIPv6() Option {
+	return func(c *Client) {
+		c.preferIPv6 = true
+		c.httpTransport().DialContext = c.dialContext
+	}
+}
+
+// WithDialTimeout sets timeout of establishing connections and delay before falling back
+// to the other address family when host has both IPv4 and IPv6 addresses ("Happy Eyeballs").
+// Zero values keep defaults (30 seconds and 300 milliseconds), negative fallbackDelay disables the fallback.
+func WithDialTimeout(connectTimeout, fallbackDelay time.Duration) Option {
+	return func(c *Client) {
+		if connectTimeout > 0 {
+			c.dialer.Timeout = connectTimeout
+		}
+		if fallbackDelay != 0 {
+			c.dialer.FallbackDelay = fallbackDelay
+		}
+		c.httpTransport().DialContext = c.dialContext
+	}
+}
+
+// WithDialContext replaces dialer of outgoing connections, e.g. to dial through a tunnel or a custom resolver.
+// Address is already resolved if DoH is enabled and is passed unchanged otherwise. Options of the default dialer do not apply to custom one.
+func WithDialContext(dial DialFunc) Option {
+	return func(c *Client) {
+		c.customDial = dial
+		c.httpTransport().DialContext = c.dialContext
+	}
+}
+
+// WithConnectionPool tunes keep-alive connection pool: maximum idle connections per host
+// and how long idle connections are kept open. Zero values keep the defaults.
+func WithConnectionPool(maxIdleConnsPerHost int, idleTimeout time.Duration) Option {
+	return func(c *Client) {
+		transport := c.httpTransport()
+		if maxIdleConnsPerHost > 0 {
+			transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+			if transport.MaxIdleConns < maxIdleConnsPerHost {
+				transport.MaxIdleConns = maxIdleConnsPerHost
+			}
+		}
+		if idleTimeout > 0 {
+			transport.IdleConnTimeout = idleTimeout
+		}
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2 (enabled by default)
+func WithHTTP2(enabled bool) Option {
+	return func(c *Client) {
+		transport := c.httpTransport()
+		transport.ForceAttemptHTTP2 = enabled
+		if !enabled {
+			// Non-nil empty map disables HTTP/2 upgrade
+			transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		}
+	}
+}
+
+// WithHeaderOrder writes request headers in the order Chrome sends them instead of sorted by name.
+// Requests are sent over HTTP/1.1, because HTTP/2 header encoding of net/http is out of client control.
+// Connections through HTTPS proxies keep the net/http order.
+func WithHeaderOrder() Option {
+	return func(c *Client) {
+		c.headerOrder = true
+		transport := c.httpTransport()
+		transport.DialContext = c.dialContext
+		transport.DialTLSContext = c.dialTLSContext
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+}
+
+// WithDisplayText strips trailing t.co links of attached media from tweet text and HTML,
+// so that they match text displayed by Twitter. Media remain available in Images.
+func WithDisplayText() Option {
+	return func(c *Client) {
+		c.render.trimMediaLinks = true
+	}
+}
+
+// WithUnescapedText returns tweet text with HTML entities (&amp;, &lt;, &gt;) decoded.
+// HTML field is escaped properly regardless of this option.
+func WithUnescapedText() Option {
+	return func(c *Client) {
+		c.render.unescapeText = true
+	}
+}
+
+// WithLineBreaks sets how newlines of tweet text are rendered in HTML.
+// By default they are converted to <br>.
+func WithLineBreaks(mode LineBreakMode) Option {
+	return func(c *Client) {
+		c.render.lineBreaks = mode
+	}
+}
+
+// WithLanguageDetection detects language of tweets for which API reports undetermined "und" language
+// and exposes it as Tweet.DetectedLang, so that LangIs filters still work.
+// Detection relies on script and frequent words and is meant for tweets of reasonable length.
+func WithLanguageDetection() Option {
+	return func(c *Client) {
+		c.render.detectLanguage = true
+	}
+}
+
+// WithPinnedPosition sets where the pinned tweet is placed in returned timeline.
+// By default it is returned first.
+func WithPinnedPosition(position PinnedPosition) Option {
+	return func(c *Client) {
+		c.pinnedPosition = position
+	}
+}
+
+// WithNewestFirst returns tweets strictly newest first instead of timeline entry order.
+// The pinned tweet, unless excluded, takes its chronological position.
+func WithNewestFirst() Option {
+	return func(c *Client) {
+		c.newestFirst = true
+	}
+}
+
+// WithQuoteDepth resolves quote chains (quote of a quote) up to the given depth,
+// fetching quoted tweets missing from timeline response from the syndication API.
+// Each level is attached as QuotedTweet of the previous one. Zero keeps only quotes
+// included in response.
+func WithQuoteDepth(depth int) Option {
+	return func(c *Client) {
+		c.quoteDepth = depth
+	}
+}
+
+// WithRawJSON attaches original JSON of every timeline tweet as Tweet.Raw,
+// so fields not exposed by Tweet can be extracted without requesting data again.
+// It costs an extra decoding pass and memory for every tweet.
+func WithRawJSON() Option {
+	return func(c *Client) {
+		c.rawJSON = true
+	}
+}
+
+// WithLogger logs requests, retries, rate limits and skipped timeline entries to the logger.
+// Requests are logged at debug level, failures and rate limits at warning level.
+// Guest and bearer tokens are never logged in full.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = discardLogger
+		}
+		c.logger = logger
+		c.buildTransportChain()
+	}
+}
+
+// WithDumpDir writes every request and response into a separate directory under dir,
+// numbered in order of calls. Query parameters are decoded from JSON and credentials are redacted.
+// Dumps are meant for diagnosing changes of API payloads and may take a lot of disk space.
+func WithDumpDir(dir string) Option {
+	return func(c *Client) {
+		c.dumper = &requestDumper{dir: dir}
+		c.buildTransportChain()
+	}
+}
+
+// WithClock replaces system time used for expiration of user ID cache and discovered query IDs,
+// so that tests can advance time instead of sleeping
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
@@ -0,0 +1,53 @@
+package twittertimeline
+
+import "sort"
+
+// PinnedPosition controls placement of the pinned tweet in returned timeline
+type PinnedPosition int
+
+// Pinned tweet positions
+const (
+	PinnedFirst         PinnedPosition = iota // Pinned tweet is returned first (default)
+	PinnedChronological                       // Pinned tweet is placed among other tweets of the page by its ID
+	PinnedExcluded                            // Pinned tweet is omitted unless it is also a regular timeline entry
+)
+
+// compareTweetIDs compares snowflake IDs numerically without parsing them.
+// It returns -1 if a is older than b, 1 if a is newer and 0 if they are equal.
+func compareTweetIDs(a, b string) int {
+	switch {
+	case len(a) != len(b):
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SortTweets sorts tweets newest first. Snowflake tweet IDs grow with creation time,
+// so they are compared instead of parsing dates. Retweets are ordered by the original tweet.
+func SortTweets(tweets []Tweet) {
+	sort.SliceStable(tweets, func(i, j int) bool {
+		return compareTweetIDs(tweets[i].ID, tweets[j].ID) > 0
+	})
+}
+
+// SortTweetsOldestFirst sorts tweets oldest first, see SortTweets
+func SortTweetsOldestFirst(tweets []Tweet) {
+	sort.SliceStable(tweets, func(i, j int) bool {
+		return compareTweetIDs(tweets[i].ID, tweets[j].ID) < 0
+	})
+}
+
+// sortTweetResults sorts tweet results newest first, see SortTweets
+func sortTweetResults(results []*TweetResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return compareTweetIDs(results[i].RestID, results[j].RestID) > 0
+	})
+}
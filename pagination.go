@@ -0,0 +1,155 @@
+package twittertimeline
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// pageJob is a fetched timeline page waiting for conversion
+type pageJob struct {
+	collector *timelineCollector
+	tweets    chan []Tweet
+	entryIDs  map[string]string // Timeline entry IDs by tweet IDs, set before tweets are sent
+}
+
+// GetAllUserTweets follows timeline cursors and returns tweets from up to maxPages pages
+// (all available pages if maxPages <= 0). Tweets repeated on several pages are returned once. Decoding of the next page runs concurrently
+// with conversion of previous ones, see WithPageParallelism. Requests of the next pages are delayed, see WithPageDelay.
+// On error, tweets fetched before the failure are returned along with the error.
+func (c *Client) GetAllUserTweets(userID string, maxPages int) ([]Tweet, error) {
+	jobs := make(chan *pageJob, c.pageParallelism)
+	errc := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// Fetch pages sequentially, since every cursor comes from the previous page,
+	// and convert each page in its own goroutine
+	go func() {
+		defer close(jobs)
+		cursor := ""
+		for page := 0; maxPages <= 0 || page < maxPages; page++ {
+			if page > 0 && !c.waitPageDelay(stop) {
+				return
+			}
+
+			collector, err := c.fetchUserTweets(userID, cursor, DefaultPageSize)
+			if err != nil {
+				errc <- err
+				return
+			}
+
+			job := &pageJob{collector: collector, tweets: make(chan []Tweet, 1)}
+			go func() {
+				if !c.newestFirst {
+					job.tweets <- job.collector.tweets()
+					return
+				}
+				var tweets []Tweet
+				tweets, job.entryIDs = job.collector.tweetsWithEntryIDs()
+				job.tweets <- tweets
+			}()
+
+			select {
+			case jobs <- job:
+			case <-stop:
+				return
+			}
+
+			// Empty page or missing cursor means the end of timeline
+			if len(collector.tweetResults) == 0 || collector.bottomCursor == "" || collector.bottomCursor == cursor {
+				return
+			}
+			cursor = collector.bottomCursor
+		}
+	}()
+
+	// Pinned and promoted tweets reappear on many pages, so skip already seen IDs
+	seen := make(map[string]struct{})
+	entryIDs := make(map[string]string)
+
+	var allTweets []Tweet
+	for job := range jobs {
+		tweets := <-job.tweets
+		for tweetID, entryID := range job.entryIDs {
+			if _, ok := entryIDs[tweetID]; !ok {
+				entryIDs[tweetID] = entryID
+			}
+		}
+		page := TimelinePage{
+			UserID:       userID,
+			Tweets:       dedupTweets(tweets, seen),
+			TopCursor:    job.collector.topCursor,
+			BottomCursor: job.collector.bottomCursor,
+		}
+		c.runHooks(&page)
+		allTweets = append(allTweets, page.Tweets...)
+	}
+
+	// Pinned tweet older than the first page ends up at its end, so restore order across pages.
+	// Pages are ordered by IDs of timeline entries, which are the retweets' own IDs rather than
+	// Tweet.ID of the original tweet, so merge by them like GetUserTweets does.
+	if c.newestFirst {
+		sortTweetsByEntryID(allTweets, entryIDs)
+	}
+
+	select {
+	case err := <-errc:
+		return allTweets, err
+	default:
+		return allTweets, nil
+	}
+}
+
+// dedupTweets removes tweets whose IDs are in seen set and adds remaining IDs to it.
+// The tweets slice is filtered in place.
+func dedupTweets(tweets []Tweet, seen map[string]struct{}) []Tweet {
+	unique := tweets[:0]
+	for _, tweet := range tweets {
+		if _, ok := seen[tweet.ID]; ok {
+			continue
+		}
+		seen[tweet.ID] = struct{}{}
+		unique = append(unique, tweet)
+	}
+	return unique
+}
+
+// sortTweetsByEntryID sorts tweets newest first by IDs of their timeline entries, see tweetsWithEntryIDs.
+// Tweets without known entry are sorted by their own IDs.
+func sortTweetsByEntryID(tweets []Tweet, entryIDs map[string]string) {
+	entryID := func(tweet *Tweet) string {
+		if id, ok := entryIDs[tweet.ID]; ok {
+			return id
+		}
+		return tweet.ID
+	}
+	sort.SliceStable(tweets, func(i, j int) bool {
+		return compareTweetIDs(entryID(&tweets[i]), entryID(&tweets[j])) > 0
+	})
+}
+
+// waitPageDelay waits politeness delay before request of the next page.
+// It returns false if waiting is interrupted by stop.
+func (c *Client) waitPageDelay(stop <-chan struct{}) bool {
+	d := jitterDuration(c.pageDelay, c.pageJitter)
+	if d <= 0 {
+		return true
+	}
+	ticker := c.clock.Ticker(d)
+	defer ticker.Stop()
+	select {
+	case <-ticker.Chan():
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// jitterDuration returns random duration within d±jitter*d
+func jitterDuration(d time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*jitter*float64(d))
+}
@@ -0,0 +1,483 @@
+// Package parquet exports tweets to Apache Parquet files for columnar analysis of large archives
+// with DuckDB, Spark or pandas.
+//
+// Files have a single row group with uncompressed PLAIN encoded columns and the following schema:
+//
+//	message tweet {
+//	  required binary id (UTF8);
+//	  required binary text (UTF8);
+//	  required binary html (UTF8);
+//	  required binary lang (UTF8);
+//	  optional int64 created_at (TIMESTAMP_MILLIS);
+//	  required binary url (UTF8);
+//	  required binary username (UTF8);
+//	  required binary user_id (UTF8);
+//	  required int64 likes;
+//	  required int64 retweets;
+//	  required int64 replies;
+//	  required int64 views;
+//	  required boolean is_pinned;
+//	  required boolean is_retweet;
+//	  required boolean is_quoted;
+//	  required boolean is_reply;
+//	  required binary conversation_id (UTF8);
+//	  required binary in_reply_to_id (UTF8);
+//	  required binary in_reply_to_username (UTF8);
+//	  required binary quoted_tweet_id (UTF8);
+//	  required group hashtags (LIST) {
+//	    repeated group list { required binary element (UTF8); }
+//	  }
+//	  required group mentions (LIST) {
+//	    repeated group list { required binary element (UTF8); }
+//	  }
+//	  required group urls (LIST) {
+//	    repeated group list {
+//	      required group element {
+//	        required binary short (UTF8);
+//	        required binary expanded (UTF8);
+//	        required binary display (UTF8);
+//	      }
+//	    }
+//	  }
+//	  required group media (LIST) {
+//	    repeated group list {
+//	      required group element {
+//	        required binary type (UTF8);
+//	        required binary url (UTF8);
+//	        required binary preview_url (UTF8);
+//	        required int64 duration_ms;
+//	      }
+//	    }
+//	  }
+//	}
+//
+// Missing strings are written as empty strings, missing lists as empty lists.
+package parquet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// magic starts and ends Parquet file
+const magic = "PAR1"
+
+// Parquet physical types
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeByteArray = 6
+)
+
+// Parquet field repetition types
+const (
+	repetitionRequired = 0
+	repetitionOptional = 1
+	repetitionRepeated = 2
+)
+
+// Parquet converted types
+const (
+	convertedNone            = -1
+	convertedUTF8            = 0
+	convertedList            = 3
+	convertedTimestampMillis = 9
+)
+
+// Parquet encodings
+const (
+	encodingPlain = 0
+	encodingRLE   = 3
+)
+
+// schemaElement is a node of Parquet schema in depth-first order
+type schemaElement struct {
+	name        string
+	physical    int32 // Physical type of leaf, -1 for groups
+	repetition  int32
+	numChildren int32
+	converted   int32
+}
+
+// column accumulates levels and PLAIN encoded values of a leaf column
+type column struct {
+	path      []string
+	physical  int32
+	maxDef    int
+	maxRep    int
+	defLevels []int
+	repLevels []int
+	values    []byte
+	bools     []bool
+	numValues int // Number of level entries, including empty lists and nulls
+
+	// fill appends values of tweet to the column
+	fill func(c *column, tweet *twittertimeline.Tweet)
+}
+
+// level records definition and repetition level of the next entry
+func (c *column) level(def, rep int) {
+	if c.maxDef > 0 {
+		c.defLevels = append(c.defLevels, def)
+	}
+	if c.maxRep > 0 {
+		c.repLevels = append(c.repLevels, rep)
+	}
+	c.numValues++
+}
+
+func (c *column) appendString(s string) {
+	c.values = binary.LittleEndian.AppendUint32(c.values, uint32(len(s)))
+	c.values = append(c.values, s...)
+}
+
+func (c *column) appendInt64(v int64) {
+	c.values = binary.LittleEndian.AppendUint64(c.values, uint64(v))
+}
+
+// schema builds elements and columns of tweet schema
+type schema struct {
+	elements []schemaElement
+	columns  []*column
+}
+
+// requiredString adds required UTF8 column
+func (s *schema) requiredString(name string, get func(*twittertimeline.Tweet) string) {
+	s.elements = append(s.elements, schemaElement{name, typeByteArray, repetitionRequired, 0, convertedUTF8})
+	s.columns = append(s.columns, &column{path: []string{name}, physical: typeByteArray, fill: func(c *column, tweet *twittertimeline.Tweet) {
+		c.level(0, 0)
+		c.appendString(get(tweet))
+	}})
+}
+
+// requiredInt64 adds required INT64 column
+func (s *schema) requiredInt64(name string, get func(*twittertimeline.Tweet) int) {
+	s.elements = append(s.elements, schemaElement{name, typeInt64, repetitionRequired, 0, convertedNone})
+	s.columns = append(s.columns, &column{path: []string{name}, physical: typeInt64, fill: func(c *column, tweet *twittertimeline.Tweet) {
+		c.level(0, 0)
+		c.appendInt64(int64(get(tweet)))
+	}})
+}
+
+// requiredBool adds required BOOLEAN column
+func (s *schema) requiredBool(name string, get func(*twittertimeline.Tweet) bool) {
+	s.elements = append(s.elements, schemaElement{name, typeBoolean, repetitionRequired, 0, convertedNone})
+	s.columns = append(s.columns, &column{path: []string{name}, physical: typeBoolean, fill: func(c *column, tweet *twittertimeline.Tweet) {
+		c.level(0, 0)
+		c.bools = append(c.bools, get(tweet))
+	}})
+}
+
+// optionalTimestamp adds optional TIMESTAMP_MILLIS column of tweet date, null if it is invalid
+func (s *schema) optionalTimestamp(name string) {
+	s.elements = append(s.elements, schemaElement{name, typeInt64, repetitionOptional, 0, convertedTimestampMillis})
+	s.columns = append(s.columns, &column{path: []string{name}, physical: typeInt64, maxDef: 1, fill: func(c *column, tweet *twittertimeline.Tweet) {
+		createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt)
+		if err != nil {
+			c.level(0, 0)
+			return
+		}
+		c.level(1, 0)
+		c.appendInt64(createdAt.UnixMilli())
+	}})
+}
+
+// listGroup adds elements of required LIST group with repeated "list" group
+func (s *schema) listGroup(name string) {
+	s.elements = append(s.elements,
+		schemaElement{name, -1, repetitionRequired, 1, convertedList},
+		schemaElement{"list", -1, repetitionRepeated, 1, convertedNone},
+	)
+}
+
+// stringList adds list of UTF8 strings
+func (s *schema) stringList(name string, get func(*twittertimeline.Tweet) []string) {
+	s.listGroup(name)
+	s.elements = append(s.elements, schemaElement{"element", typeByteArray, repetitionRequired, 0, convertedUTF8})
+	s.columns = append(s.columns, &column{
+		path:     []string{name, "list", "element"},
+		physical: typeByteArray,
+		maxDef:   1,
+		maxRep:   1,
+		fill: func(c *column, tweet *twittertimeline.Tweet) {
+			values := get(tweet)
+			if len(values) == 0 {
+				c.level(0, 0)
+				return
+			}
+			for i, value := range values {
+				c.level(1, min(i, 1))
+				c.appendString(value)
+			}
+		},
+	})
+}
+
+// structField is a field of struct list element
+type structField struct {
+	name     string
+	physical int32 // typeByteArray or typeInt64
+	get      func(tweet *twittertimeline.Tweet, i int) any
+}
+
+// structList adds list of structs with n elements per tweet
+func (s *schema) structList(name string, n func(*twittertimeline.Tweet) int, fields ...structField) {
+	s.listGroup(name)
+	s.elements = append(s.elements, schemaElement{"element", -1, repetitionRequired, int32(len(fields)), convertedNone})
+	for _, field := range fields {
+		field := field
+		converted := int32(convertedNone)
+		if field.physical == typeByteArray {
+			converted = convertedUTF8
+		}
+		s.elements = append(s.elements, schemaElement{field.name, field.physical, repetitionRequired, 0, converted})
+		s.columns = append(s.columns, &column{
+			path:     []string{name, "list", "element", field.name},
+			physical: field.physical,
+			maxDef:   1,
+			maxRep:   1,
+			fill: func(c *column, tweet *twittertimeline.Tweet) {
+				count := n(tweet)
+				if count == 0 {
+					c.level(0, 0)
+					return
+				}
+				for i := 0; i < count; i++ {
+					c.level(1, min(i, 1))
+					switch value := field.get(tweet, i).(type) {
+					case string:
+						c.appendString(value)
+					case int64:
+						c.appendInt64(value)
+					}
+				}
+			},
+		})
+	}
+}
+
+// tweetSchema returns schema of tweets documented in package description
+func tweetSchema() *schema {
+	s := &schema{}
+	s.requiredString("id", func(t *twittertimeline.Tweet) string { return t.ID })
+	s.requiredString("text", func(t *twittertimeline.Tweet) string { return t.Text })
+	s.requiredString("html", func(t *twittertimeline.Tweet) string { return t.HTML })
+	s.requiredString("lang", func(t *twittertimeline.Tweet) string { return t.Lang })
+	s.optionalTimestamp("created_at")
+	s.requiredString("url", func(t *twittertimeline.Tweet) string { return t.PermanentURL })
+	s.requiredString("username", func(t *twittertimeline.Tweet) string { return t.Username })
+	s.requiredString("user_id", func(t *twittertimeline.Tweet) string { return t.UserID })
+	s.requiredInt64("likes", func(t *twittertimeline.Tweet) int { return t.Likes })
+	s.requiredInt64("retweets", func(t *twittertimeline.Tweet) int { return t.Retweets })
+	s.requiredInt64("replies", func(t *twittertimeline.Tweet) int { return t.Replies })
+	s.requiredInt64("views", func(t *twittertimeline.Tweet) int { return t.Views })
+	s.requiredBool("is_pinned", func(t *twittertimeline.Tweet) bool { return t.IsPinned })
+	s.requiredBool("is_retweet", func(t *twittertimeline.Tweet) bool { return t.IsRetweet })
+	s.requiredBool("is_quoted", func(t *twittertimeline.Tweet) bool { return t.IsQuoted })
+	s.requiredBool("is_reply", func(t *twittertimeline.Tweet) bool { return t.IsReply })
+	s.requiredString("conversation_id", func(t *twittertimeline.Tweet) string { return t.ConversationID })
+	s.requiredString("in_reply_to_id", func(t *twittertimeline.Tweet) string { return t.InReplyToID })
+	s.requiredString("in_reply_to_username", func(t *twittertimeline.Tweet) string { return t.InReplyToUsername })
+	s.requiredString("quoted_tweet_id", func(t *twittertimeline.Tweet) string {
+		if t.QuotedTweet == nil {
+			return ""
+		}
+		return t.QuotedTweet.ID
+	})
+	s.stringList("hashtags", func(t *twittertimeline.Tweet) []string { return t.Hashtags })
+	s.stringList("mentions", func(t *twittertimeline.Tweet) []string { return t.Mentions })
+	s.structList("urls", func(t *twittertimeline.Tweet) int { return len(t.URLs) },
+		structField{"short", typeByteArray, func(t *twittertimeline.Tweet, i int) any { return t.URLs[i].Short }},
+		structField{"expanded", typeByteArray, func(t *twittertimeline.Tweet, i int) any { return t.URLs[i].Expanded }},
+		structField{"display", typeByteArray, func(t *twittertimeline.Tweet, i int) any { return t.URLs[i].Display }},
+	)
+	s.structList("media", func(t *twittertimeline.Tweet) int { return len(t.Media) },
+		structField{"type", typeByteArray, func(t *twittertimeline.Tweet, i int) any { return t.Media[i].Type }},
+		structField{"url", typeByteArray, func(t *twittertimeline.Tweet, i int) any { return t.Media[i].URL }},
+		structField{"preview_url", typeByteArray, func(t *twittertimeline.Tweet, i int) any { return t.Media[i].PreviewURL }},
+		structField{"duration_ms", typeInt64, func(t *twittertimeline.Tweet, i int) any { return t.Media[i].Duration.Milliseconds() }},
+	)
+	return s
+}
+
+// chunk describes written column chunk
+type chunk struct {
+	offset int64
+	size   int64
+}
+
+// Write writes tweets to w as Parquet file
+func Write(w io.Writer, tweets []twittertimeline.Tweet) error {
+	s := tweetSchema()
+	for i := range tweets {
+		for _, c := range s.columns {
+			c.fill(c, &tweets[i])
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	offset := int64(len(magic))
+	if _, err := bw.WriteString(magic); err != nil {
+		return fmt.Errorf("error writing parquet: %w", err)
+	}
+
+	chunks := make([]chunk, len(s.columns))
+	for i, c := range s.columns {
+		page := encodePage(c)
+		if _, err := bw.Write(page); err != nil {
+			return fmt.Errorf("error writing parquet: %w", err)
+		}
+		chunks[i] = chunk{offset: offset, size: int64(len(page))}
+		offset += int64(len(page))
+	}
+
+	footer := encodeFileMetaData(s, chunks, int64(len(tweets)))
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, magic...)
+	if _, err := bw.Write(footer); err != nil {
+		return fmt.Errorf("error writing parquet: %w", err)
+	}
+	return bw.Flush()
+}
+
+// encodePage encodes column as a single data page with its header
+func encodePage(c *column) []byte {
+	var data []byte
+	if c.maxRep > 0 {
+		data = appendLevels(data, c.repLevels, c.maxRep)
+	}
+	if c.maxDef > 0 {
+		data = appendLevels(data, c.defLevels, c.maxDef)
+	}
+	if c.physical == typeBoolean {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, v := range c.bools {
+			if v {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		data = append(data, packed...)
+	} else {
+		data = append(data, c.values...)
+	}
+
+	var w thriftWriter
+	w.begin()
+	w.i32(1, 0) // DATA_PAGE
+	w.i32(2, int32(len(data)))
+	w.i32(3, int32(len(data)))
+	w.structField(5)
+	w.i32(1, int32(c.numValues))
+	w.i32(2, encodingPlain)
+	w.i32(3, encodingRLE)
+	w.i32(4, encodingRLE)
+	w.end()
+	w.end()
+	return append(w.buf, data...)
+}
+
+// appendLevels appends levels encoded with RLE hybrid encoding, prefixed with its length
+func appendLevels(b []byte, levels []int, maxLevel int) []byte {
+	width := (bits.Len(uint(maxLevel)) + 7) / 8
+
+	var encoded []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		encoded = binary.AppendUvarint(encoded, uint64(j-i)<<1)
+		for k := 0; k < width; k++ {
+			encoded = append(encoded, byte(levels[i]>>(8*k)))
+		}
+		i = j
+	}
+
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(encoded)))
+	return append(b, encoded...)
+}
+
+// encodeFileMetaData encodes file footer
+func encodeFileMetaData(s *schema, chunks []chunk, numRows int64) []byte {
+	var w thriftWriter
+	w.begin()
+	w.i32(1, 1) // version
+
+	w.list(2, thriftStruct, len(s.elements)+1)
+	w.begin()
+	w.binary(4, "schema")
+	w.i32(5, int32(countTopLevel(s.elements)))
+	w.end()
+	for _, e := range s.elements {
+		w.begin()
+		if e.physical >= 0 {
+			w.i32(1, e.physical)
+		}
+		w.i32(3, e.repetition)
+		w.binary(4, e.name)
+		if e.numChildren > 0 {
+			w.i32(5, e.numChildren)
+		}
+		if e.converted >= 0 {
+			w.i32(6, e.converted)
+		}
+		w.end()
+	}
+
+	w.i64(3, numRows)
+
+	var totalSize int64
+	for _, c := range chunks {
+		totalSize += c.size
+	}
+	w.list(4, thriftStruct, 1)
+	w.begin()
+	w.list(1, thriftStruct, len(s.columns))
+	for i, c := range s.columns {
+		w.begin()
+		w.i64(2, chunks[i].offset)
+		w.structField(3)
+		w.i32(1, c.physical)
+		w.i32List(2, encodingPlain, encodingRLE)
+		w.binaryList(3, c.path)
+		w.i32(4, 0) // UNCOMPRESSED
+		w.i64(5, int64(c.numValues))
+		w.i64(6, chunks[i].size)
+		w.i64(7, chunks[i].size)
+		w.i64(9, chunks[i].offset)
+		w.end()
+		w.end()
+	}
+	w.i64(2, totalSize)
+	w.i64(3, numRows)
+	w.end()
+
+	w.binary(6, "twitter-timeline")
+	w.end()
+	return w.buf
+}
+
+// countTopLevel returns number of top-level fields in depth-first list of schema elements
+func countTopLevel(elements []schemaElement) int {
+	count := 0
+	for i := 0; i < len(elements); i = skipElement(elements, i) {
+		count++
+	}
+	return count
+}
+
+// skipElement returns index of the element following element i with all its descendants
+func skipElement(elements []schemaElement, i int) int {
+	children := int(elements[i].numChildren)
+	i++
+	for ; children > 0; children-- {
+		i = skipElement(elements, i)
+	}
+	return i
+}
@@ -0,0 +1,218 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// thriftReader decodes Thrift compact protocol into generic values: int64, string, []any and map[int16]any
+type thriftReader struct {
+	t   *testing.T
+	buf []byte
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.t.Fatalf("invalid varint")
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		v := r.varint()
+		return int64(v>>1) ^ -int64(v&1)
+	case thriftBinary:
+		n := r.varint()
+		s := string(r.buf[:n])
+		r.buf = r.buf[n:]
+		return s
+	case thriftList:
+		header := r.buf[0]
+		r.buf = r.buf[1:]
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		fields := map[int16]any{}
+		var id int16
+		for {
+			header := r.buf[0]
+			r.buf = r.buf[1:]
+			if header == 0 {
+				return fields
+			}
+			if delta := int16(header >> 4); delta != 0 {
+				id += delta
+			} else {
+				id = int16(r.value(thriftI32).(int64))
+			}
+			fields[id] = r.value(header & 0x0f)
+		}
+	}
+	r.t.Fatalf("unexpected type %d", typ)
+	return nil
+}
+
+// readColumn decodes levels and values of column chunk
+func readColumn(t *testing.T, file []byte, meta map[int16]any, maxDef, maxRep int) (rep, def []int, values []byte) {
+	offset := meta[9].(int64)
+	r := &thriftReader{t: t, buf: file[offset:]}
+	header := r.value(thriftStruct).(map[int16]any)
+	page := r.buf[:header[3].(int64)]
+	numValues := int(header[5].(map[int16]any)[1].(int64))
+
+	readLevels := func(maxLevel int) []int {
+		if maxLevel == 0 {
+			return nil
+		}
+		size := binary.LittleEndian.Uint32(page)
+		lr := &thriftReader{t: t, buf: page[4 : 4+size]}
+		page = page[4+size:]
+		var levels []int
+		for len(lr.buf) > 0 {
+			count := int(lr.varint() >> 1)
+			level := int(lr.buf[0])
+			lr.buf = lr.buf[1:]
+			for i := 0; i < count; i++ {
+				levels = append(levels, level)
+			}
+		}
+		if len(levels) != numValues {
+			t.Fatalf("Expected %d levels, got %d", numValues, len(levels))
+		}
+		return levels
+	}
+	rep = readLevels(maxRep)
+	def = readLevels(maxDef)
+	return rep, def, page
+}
+
+func readStrings(values []byte) []string {
+	var result []string
+	for len(values) > 0 {
+		n := binary.LittleEndian.Uint32(values)
+		result = append(result, string(values[4:4+n]))
+		values = values[4+n:]
+	}
+	return result
+}
+
+func readInt64s(values []byte) []int64 {
+	var result []int64
+	for ; len(values) > 0; values = values[8:] {
+		result = append(result, int64(binary.LittleEndian.Uint64(values)))
+	}
+	return result
+}
+
+func TestWrite(t *testing.T) {
+	tweets := []twittertimeline.Tweet{
+		{
+			ID:        "1",
+			Text:      "Hello #go #parquet",
+			CreatedAt: "Mon Jan 01 09:30:00 +0000 2024",
+			Likes:     5,
+			IsPinned:  true,
+			Hashtags:  []string{"go", "parquet"},
+			Media:     []twittertimeline.Media{{Type: twittertimeline.MediaVideo, URL: "https://video.twimg.com/1.mp4", Duration: 1500 * time.Millisecond}},
+		},
+		{
+			ID:          "2",
+			Text:        "Quote",
+			Views:       1 << 40,
+			IsQuoted:    true,
+			QuotedTweet: &twittertimeline.QuotedTweet{Tweet: twittertimeline.Tweet{ID: "1"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, tweets); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatalf("Missing magic bytes")
+	}
+
+	footerSize := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footerStart := len(file) - 8 - int(footerSize)
+	r := &thriftReader{t: t, buf: file[footerStart : len(file)-8]}
+	meta := r.value(thriftStruct).(map[int16]any)
+	if len(r.buf) != 0 {
+		t.Errorf("Unexpected %d bytes after footer", len(r.buf))
+	}
+	if meta[3].(int64) != 2 {
+		t.Errorf("Expected 2 rows, got %v", meta[3])
+	}
+
+	schema := meta[2].([]any)
+	root := schema[0].(map[int16]any)
+	if root[4] != "schema" || root[5].(int64) != 24 {
+		t.Errorf("Unexpected root schema element: %v", root)
+	}
+	if len(schema) != 1+len(tweetSchema().elements) {
+		t.Errorf("Unexpected number of schema elements: %d", len(schema))
+	}
+
+	chunks := map[string]map[int16]any{}
+	for _, c := range meta[4].([]any)[0].(map[int16]any)[1].([]any) {
+		columnMeta := c.(map[int16]any)[3].(map[int16]any)
+		var path []byte
+		for _, p := range columnMeta[3].([]any) {
+			if len(path) > 0 {
+				path = append(path, '.')
+			}
+			path = append(path, p.(string)...)
+		}
+		chunks[string(path)] = columnMeta
+	}
+	if len(chunks) != 29 {
+		t.Errorf("Expected 29 columns, got %d", len(chunks))
+	}
+
+	if _, _, values := readColumn(t, file, chunks["id"], 0, 0); !reflect.DeepEqual(readStrings(values), []string{"1", "2"}) {
+		t.Errorf("Unexpected id values: %q", readStrings(values))
+	}
+	if _, _, values := readColumn(t, file, chunks["quoted_tweet_id"], 0, 0); !reflect.DeepEqual(readStrings(values), []string{"", "1"}) {
+		t.Errorf("Unexpected quoted_tweet_id values: %q", readStrings(values))
+	}
+	if _, _, values := readColumn(t, file, chunks["views"], 0, 0); !reflect.DeepEqual(readInt64s(values), []int64{0, 1 << 40}) {
+		t.Errorf("Unexpected views values: %v", readInt64s(values))
+	}
+	if _, _, values := readColumn(t, file, chunks["is_pinned"], 0, 0); !bytes.Equal(values, []byte{0x01}) {
+		t.Errorf("Unexpected is_pinned values: % x", values)
+	}
+
+	_, def, values := readColumn(t, file, chunks["created_at"], 1, 0)
+	if !reflect.DeepEqual(def, []int{1, 0}) || !reflect.DeepEqual(readInt64s(values), []int64{1704101400000}) {
+		t.Errorf("Unexpected created_at: %v %v", def, readInt64s(values))
+	}
+
+	rep, def, values := readColumn(t, file, chunks["hashtags.list.element"], 1, 1)
+	if !reflect.DeepEqual(rep, []int{0, 1, 0}) || !reflect.DeepEqual(def, []int{1, 1, 0}) {
+		t.Errorf("Unexpected hashtags levels: rep %v, def %v", rep, def)
+	}
+	if !reflect.DeepEqual(readStrings(values), []string{"go", "parquet"}) {
+		t.Errorf("Unexpected hashtags values: %q", readStrings(values))
+	}
+
+	rep, def, values = readColumn(t, file, chunks["media.list.element.duration_ms"], 1, 1)
+	if !reflect.DeepEqual(rep, []int{0, 0}) || !reflect.DeepEqual(def, []int{1, 0}) || !reflect.DeepEqual(readInt64s(values), []int64{1500}) {
+		t.Errorf("Unexpected media duration: rep %v, def %v, values %v", rep, def, readInt64s(values))
+	}
+}
@@ -0,0 +1,103 @@
+package parquet
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structures of Parquet metadata with Thrift compact protocol
+type thriftWriter struct {
+	buf    []byte
+	fields []int16 // Last field ID of every open struct
+}
+
+// fieldHeader writes header of field with ID and type, using delta encoding when possible
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.fields[len(w.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	for v >= 0x80 {
+		w.buf = append(w.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	w.buf = append(w.buf, byte(v))
+}
+
+// zigzag maps signed integers to unsigned so that small absolute values are encoded short
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(zigzag(v))
+}
+
+func (w *thriftWriter) binary(id int16, s string) {
+	w.fieldHeader(id, thriftBinary)
+	w.rawBinary(s)
+}
+
+func (w *thriftWriter) rawBinary(s string) {
+	w.varint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// begin starts top-level struct or struct element of list
+func (w *thriftWriter) begin() {
+	w.fields = append(w.fields, 0)
+}
+
+// structField starts struct field
+func (w *thriftWriter) structField(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.begin()
+}
+
+// end finishes struct
+func (w *thriftWriter) end() {
+	w.buf = append(w.buf, 0)
+	w.fields = w.fields[:len(w.fields)-1]
+}
+
+// list writes header of list field with size elements of type
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elemType)
+	} else {
+		w.buf = append(w.buf, 0xf0|elemType)
+		w.varint(uint64(size))
+	}
+}
+
+func (w *thriftWriter) i32List(id int16, values ...int32) {
+	w.list(id, thriftI32, len(values))
+	for _, v := range values {
+		w.varint(zigzag(int64(v)))
+	}
+}
+
+func (w *thriftWriter) binaryList(id int16, values []string) {
+	w.list(id, thriftBinary, len(values))
+	for _, v := range values {
+		w.rawBinary(v)
+	}
+}
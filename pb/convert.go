@@ -0,0 +1,212 @@
+package pb
+
+import (
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// FromTweet converts tweet into message. Entities, mentions with IDs, community, restrictions
+// and raw JSON are not part of the message.
+func FromTweet(tweet *twittertimeline.Tweet) *Tweet {
+	m := &Tweet{
+		ID:                tweet.ID,
+		Text:              tweet.Text,
+		HTML:              tweet.HTML,
+		Lang:              tweet.Lang,
+		CreatedAt:         unixTime(tweet.CreatedAt),
+		PermanentURL:      tweet.PermanentURL,
+		Username:          tweet.Username,
+		UserID:            tweet.UserID,
+		Likes:             int64(tweet.Likes),
+		Retweets:          int64(tweet.Retweets),
+		Replies:           int64(tweet.Replies),
+		Views:             int64(tweet.Views),
+		IsPinned:          tweet.IsPinned,
+		IsRetweet:         tweet.IsRetweet,
+		IsQuoted:          tweet.IsQuoted,
+		IsReply:           tweet.IsReply,
+		IsExclusive:       tweet.IsExclusive,
+		ConversationID:    tweet.ConversationID,
+		InReplyToID:       tweet.InReplyToID,
+		InReplyToUserID:   tweet.InReplyToUserID,
+		InReplyToUsername: tweet.InReplyToUsername,
+		Images:            tweet.Images,
+		Hashtags:          tweet.Hashtags,
+		Cashtags:          tweet.Cashtags,
+		URLs:              fromURLs(tweet.URLs),
+		Mentions:          tweet.Mentions,
+		DetectedLang:      tweet.DetectedLang,
+	}
+	for _, media := range tweet.Media {
+		m.Media = append(m.Media, &Media{
+			Type:       media.Type,
+			URL:        media.URL,
+			PreviewURL: media.PreviewURL,
+			DurationMs: media.Duration.Milliseconds(),
+		})
+	}
+	if quoted := tweet.QuotedTweet; quoted != nil {
+		m.QuotedTweet = &QuotedTweet{
+			Tweet:       FromTweet(&quoted.Tweet),
+			Unavailable: quoted.Unavailable,
+			Reason:      quoted.Reason,
+		}
+	}
+	return m
+}
+
+// ToTweet converts message into tweet
+func (m *Tweet) ToTweet() twittertimeline.Tweet {
+	tweet := twittertimeline.Tweet{
+		ID:                m.ID,
+		Text:              m.Text,
+		HTML:              m.HTML,
+		Lang:              m.Lang,
+		CreatedAt:         rubyDate(m.CreatedAt),
+		PermanentURL:      m.PermanentURL,
+		Username:          m.Username,
+		UserID:            m.UserID,
+		Likes:             int(m.Likes),
+		Retweets:          int(m.Retweets),
+		Replies:           int(m.Replies),
+		Views:             int(m.Views),
+		IsPinned:          m.IsPinned,
+		IsRetweet:         m.IsRetweet,
+		IsQuoted:          m.IsQuoted,
+		IsReply:           m.IsReply,
+		IsExclusive:       m.IsExclusive,
+		ConversationID:    m.ConversationID,
+		InReplyToID:       m.InReplyToID,
+		InReplyToUserID:   m.InReplyToUserID,
+		InReplyToUsername: m.InReplyToUsername,
+		Images:            m.Images,
+		Hashtags:          m.Hashtags,
+		Cashtags:          m.Cashtags,
+		URLs:              toURLs(m.URLs),
+		Mentions:          m.Mentions,
+		DetectedLang:      m.DetectedLang,
+	}
+	for _, media := range m.Media {
+		tweet.Media = append(tweet.Media, twittertimeline.Media{
+			Type:       media.Type,
+			URL:        media.URL,
+			PreviewURL: media.PreviewURL,
+			Duration:   time.Duration(media.DurationMs) * time.Millisecond,
+		})
+	}
+	if quoted := m.QuotedTweet; quoted != nil {
+		tweet.QuotedTweet = &twittertimeline.QuotedTweet{Unavailable: quoted.Unavailable, Reason: quoted.Reason}
+		if quoted.Tweet != nil {
+			tweet.QuotedTweet.Tweet = quoted.Tweet.ToTweet()
+		}
+	}
+	return tweet
+}
+
+// FromUser converts user into message
+func FromUser(user *twittertimeline.User) *User {
+	m := &User{
+		ID:             user.ID,
+		Username:       user.Username,
+		Name:           user.Name,
+		Bio:            user.Bio,
+		BioHTML:        user.BioHTML,
+		BioURLs:        fromURLs(user.BioURLs),
+		Location:       user.Location,
+		Website:        user.Website,
+		CreatedAt:      unixTime(user.CreatedAt),
+		AvatarURL:      user.AvatarURL,
+		BannerURL:      user.BannerURL,
+		Followers:      int64(user.Followers),
+		Following:      int64(user.Following),
+		Tweets:         int64(user.Tweets),
+		Likes:          int64(user.Likes),
+		Media:          int64(user.Media),
+		Listed:         int64(user.Listed),
+		IsVerified:     user.IsVerified,
+		IsBlueVerified: user.IsBlueVerified,
+		VerifiedType:   user.VerifiedType,
+		IsProtected:    user.IsProtected,
+	}
+	if affiliate := user.Affiliate; affiliate != nil {
+		m.Affiliate = &Affiliate{
+			Name:      affiliate.Name,
+			URL:       affiliate.URL,
+			BadgeURL:  affiliate.BadgeURL,
+			LabelType: affiliate.LabelType,
+		}
+	}
+	return m
+}
+
+// ToUser converts message into user
+func (m *User) ToUser() twittertimeline.User {
+	user := twittertimeline.User{
+		ID:             m.ID,
+		Username:       m.Username,
+		Name:           m.Name,
+		Bio:            m.Bio,
+		BioHTML:        m.BioHTML,
+		BioURLs:        toURLs(m.BioURLs),
+		Location:       m.Location,
+		Website:        m.Website,
+		CreatedAt:      rubyDate(m.CreatedAt),
+		AvatarURL:      m.AvatarURL,
+		BannerURL:      m.BannerURL,
+		Followers:      int(m.Followers),
+		Following:      int(m.Following),
+		Tweets:         int(m.Tweets),
+		Likes:          int(m.Likes),
+		Media:          int(m.Media),
+		Listed:         int(m.Listed),
+		IsVerified:     m.IsVerified,
+		IsBlueVerified: m.IsBlueVerified,
+		VerifiedType:   m.VerifiedType,
+		IsProtected:    m.IsProtected,
+	}
+	if affiliate := m.Affiliate; affiliate != nil {
+		user.Affiliate = &twittertimeline.Affiliate{
+			Name:      affiliate.Name,
+			URL:       affiliate.URL,
+			BadgeURL:  affiliate.BadgeURL,
+			LabelType: affiliate.LabelType,
+		}
+	}
+	return user
+}
+
+// fromURLs converts links into messages
+func fromURLs(urls []twittertimeline.URL) []*URL {
+	var messages []*URL
+	for _, u := range urls {
+		messages = append(messages, &URL{Short: u.Short, Expanded: u.Expanded, Display: u.Display})
+	}
+	return messages
+}
+
+// toURLs converts messages into links
+func toURLs(messages []*URL) []twittertimeline.URL {
+	var urls []twittertimeline.URL
+	for _, u := range messages {
+		urls = append(urls, twittertimeline.URL{Short: u.Short, Expanded: u.Expanded, Display: u.Display})
+	}
+	return urls
+}
+
+// unixTime converts API date into Unix time, zero if it is invalid
+func unixTime(date string) int64 {
+	t, err := time.Parse(time.RubyDate, date)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// rubyDate converts Unix time into API date format, empty for zero time
+func rubyDate(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RubyDate)
+}
@@ -0,0 +1,513 @@
+// Package pb encodes tweets and users in Protocol Buffers wire format of messages defined in
+// twittertimeline.proto, e.g. for shipping them over Kafka. Messages are plain hand-written structs
+// with their own Marshal and Unmarshal methods, not protoc-gen-go output: they have no dependencies
+// and are wire-compatible with code generated by protoc, but do not implement proto.Message.
+// gRPC services and other users of the protobuf runtime must generate their own types from
+// twittertimeline.proto with protoc.
+package pb
+
+// Tweet is twittertimeline.v1.Tweet message
+type Tweet struct {
+	ID           string
+	Text         string
+	HTML         string
+	Lang         string
+	CreatedAt    int64 // Unix time in seconds
+	PermanentURL string
+
+	Username string
+	UserID   string
+
+	Likes    int64
+	Retweets int64
+	Replies  int64
+	Views    int64
+
+	IsPinned    bool
+	IsRetweet   bool
+	IsQuoted    bool
+	IsReply     bool
+	IsExclusive bool
+
+	ConversationID    string
+	InReplyToID       string
+	InReplyToUserID   string
+	InReplyToUsername string
+
+	Images   []string
+	Media    []*Media
+	Hashtags []string
+	Cashtags []string
+	URLs     []*URL
+	Mentions []string
+
+	QuotedTweet  *QuotedTweet
+	DetectedLang string
+}
+
+// QuotedTweet is twittertimeline.v1.QuotedTweet message
+type QuotedTweet struct {
+	Tweet       *Tweet
+	Unavailable bool
+	Reason      string
+}
+
+// Media is twittertimeline.v1.Media message
+type Media struct {
+	Type       string
+	URL        string
+	PreviewURL string
+	DurationMs int64
+}
+
+// URL is twittertimeline.v1.URL message
+type URL struct {
+	Short    string
+	Expanded string
+	Display  string
+}
+
+// User is twittertimeline.v1.User message
+type User struct {
+	ID       string
+	Username string
+	Name     string
+
+	Bio       string
+	BioHTML   string
+	BioURLs   []*URL
+	Location  string
+	Website   string
+	CreatedAt int64 // Unix time in seconds
+	AvatarURL string
+	BannerURL string
+
+	Followers int64
+	Following int64
+	Tweets    int64
+	Likes     int64
+	Media     int64
+	Listed    int64
+
+	IsVerified     bool
+	IsBlueVerified bool
+	VerifiedType   string
+	Affiliate      *Affiliate
+	IsProtected    bool
+}
+
+// Affiliate is twittertimeline.v1.Affiliate message
+type Affiliate struct {
+	Name      string
+	URL       string
+	BadgeURL  string
+	LabelType string
+}
+
+// Marshal encodes tweet in Protocol Buffers wire format
+func (m *Tweet) Marshal() []byte {
+	return m.appendTo(nil)
+}
+
+func (m *Tweet) appendTo(b []byte) []byte {
+	b = appendString(b, 1, m.ID)
+	b = appendString(b, 2, m.Text)
+	b = appendString(b, 3, m.HTML)
+	b = appendString(b, 4, m.Lang)
+	b = appendInt64(b, 5, m.CreatedAt)
+	b = appendString(b, 6, m.PermanentURL)
+	b = appendString(b, 7, m.Username)
+	b = appendString(b, 8, m.UserID)
+	b = appendInt64(b, 9, m.Likes)
+	b = appendInt64(b, 10, m.Retweets)
+	b = appendInt64(b, 11, m.Replies)
+	b = appendInt64(b, 12, m.Views)
+	b = appendBool(b, 13, m.IsPinned)
+	b = appendBool(b, 14, m.IsRetweet)
+	b = appendBool(b, 15, m.IsQuoted)
+	b = appendBool(b, 16, m.IsReply)
+	b = appendBool(b, 17, m.IsExclusive)
+	b = appendString(b, 18, m.ConversationID)
+	b = appendString(b, 19, m.InReplyToID)
+	b = appendString(b, 20, m.InReplyToUserID)
+	b = appendString(b, 21, m.InReplyToUsername)
+	b = appendRepeatedString(b, 22, m.Images)
+	for _, media := range m.Media {
+		b = appendMessage(b, 23, media.appendTo(nil))
+	}
+	b = appendRepeatedString(b, 24, m.Hashtags)
+	b = appendRepeatedString(b, 25, m.Cashtags)
+	for _, u := range m.URLs {
+		b = appendMessage(b, 26, u.appendTo(nil))
+	}
+	b = appendRepeatedString(b, 27, m.Mentions)
+	if m.QuotedTweet != nil {
+		b = appendMessage(b, 28, m.QuotedTweet.appendTo(nil))
+	}
+	b = appendString(b, 29, m.DetectedLang)
+	return b
+}
+
+// Unmarshal decodes tweet from Protocol Buffers wire format. Unknown fields are skipped.
+func (m *Tweet) Unmarshal(data []byte) error {
+	*m = Tweet{}
+	d := decoder{data: data}
+	for {
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch field {
+		case 1:
+			m.ID, err = d.string(wireType)
+		case 2:
+			m.Text, err = d.string(wireType)
+		case 3:
+			m.HTML, err = d.string(wireType)
+		case 4:
+			m.Lang, err = d.string(wireType)
+		case 5:
+			m.CreatedAt, err = d.int64(wireType)
+		case 6:
+			m.PermanentURL, err = d.string(wireType)
+		case 7:
+			m.Username, err = d.string(wireType)
+		case 8:
+			m.UserID, err = d.string(wireType)
+		case 9:
+			m.Likes, err = d.int64(wireType)
+		case 10:
+			m.Retweets, err = d.int64(wireType)
+		case 11:
+			m.Replies, err = d.int64(wireType)
+		case 12:
+			m.Views, err = d.int64(wireType)
+		case 13:
+			m.IsPinned, err = d.bool(wireType)
+		case 14:
+			m.IsRetweet, err = d.bool(wireType)
+		case 15:
+			m.IsQuoted, err = d.bool(wireType)
+		case 16:
+			m.IsReply, err = d.bool(wireType)
+		case 17:
+			m.IsExclusive, err = d.bool(wireType)
+		case 18:
+			m.ConversationID, err = d.string(wireType)
+		case 19:
+			m.InReplyToID, err = d.string(wireType)
+		case 20:
+			m.InReplyToUserID, err = d.string(wireType)
+		case 21:
+			m.InReplyToUsername, err = d.string(wireType)
+		case 22:
+			m.Images, err = appendDecodedString(m.Images, &d, wireType)
+		case 23:
+			media := &Media{}
+			err = decodeMessage(&d, wireType, media.Unmarshal)
+			m.Media = append(m.Media, media)
+		case 24:
+			m.Hashtags, err = appendDecodedString(m.Hashtags, &d, wireType)
+		case 25:
+			m.Cashtags, err = appendDecodedString(m.Cashtags, &d, wireType)
+		case 26:
+			u := &URL{}
+			err = decodeMessage(&d, wireType, u.Unmarshal)
+			m.URLs = append(m.URLs, u)
+		case 27:
+			m.Mentions, err = appendDecodedString(m.Mentions, &d, wireType)
+		case 28:
+			m.QuotedTweet = &QuotedTweet{}
+			err = decodeMessage(&d, wireType, m.QuotedTweet.Unmarshal)
+		case 29:
+			m.DetectedLang, err = d.string(wireType)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Marshal encodes quoted tweet in Protocol Buffers wire format
+func (m *QuotedTweet) Marshal() []byte {
+	return m.appendTo(nil)
+}
+
+func (m *QuotedTweet) appendTo(b []byte) []byte {
+	if m.Tweet != nil {
+		b = appendMessage(b, 1, m.Tweet.appendTo(nil))
+	}
+	b = appendBool(b, 2, m.Unavailable)
+	b = appendString(b, 3, m.Reason)
+	return b
+}
+
+// Unmarshal decodes quoted tweet from Protocol Buffers wire format. Unknown fields are skipped.
+func (m *QuotedTweet) Unmarshal(data []byte) error {
+	*m = QuotedTweet{}
+	d := decoder{data: data}
+	for {
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch field {
+		case 1:
+			m.Tweet = &Tweet{}
+			err = decodeMessage(&d, wireType, m.Tweet.Unmarshal)
+		case 2:
+			m.Unavailable, err = d.bool(wireType)
+		case 3:
+			m.Reason, err = d.string(wireType)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Marshal encodes media in Protocol Buffers wire format
+func (m *Media) Marshal() []byte {
+	return m.appendTo(nil)
+}
+
+func (m *Media) appendTo(b []byte) []byte {
+	b = appendString(b, 1, m.Type)
+	b = appendString(b, 2, m.URL)
+	b = appendString(b, 3, m.PreviewURL)
+	b = appendInt64(b, 4, m.DurationMs)
+	return b
+}
+
+// Unmarshal decodes media from Protocol Buffers wire format. Unknown fields are skipped.
+func (m *Media) Unmarshal(data []byte) error {
+	*m = Media{}
+	d := decoder{data: data}
+	for {
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch field {
+		case 1:
+			m.Type, err = d.string(wireType)
+		case 2:
+			m.URL, err = d.string(wireType)
+		case 3:
+			m.PreviewURL, err = d.string(wireType)
+		case 4:
+			m.DurationMs, err = d.int64(wireType)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Marshal encodes link in Protocol Buffers wire format
+func (m *URL) Marshal() []byte {
+	return m.appendTo(nil)
+}
+
+func (m *URL) appendTo(b []byte) []byte {
+	b = appendString(b, 1, m.Short)
+	b = appendString(b, 2, m.Expanded)
+	b = appendString(b, 3, m.Display)
+	return b
+}
+
+// Unmarshal decodes link from Protocol Buffers wire format. Unknown fields are skipped.
+func (m *URL) Unmarshal(data []byte) error {
+	*m = URL{}
+	d := decoder{data: data}
+	for {
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch field {
+		case 1:
+			m.Short, err = d.string(wireType)
+		case 2:
+			m.Expanded, err = d.string(wireType)
+		case 3:
+			m.Display, err = d.string(wireType)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Marshal encodes user in Protocol Buffers wire format
+func (m *User) Marshal() []byte {
+	return m.appendTo(nil)
+}
+
+func (m *User) appendTo(b []byte) []byte {
+	b = appendString(b, 1, m.ID)
+	b = appendString(b, 2, m.Username)
+	b = appendString(b, 3, m.Name)
+	b = appendString(b, 4, m.Bio)
+	b = appendString(b, 5, m.BioHTML)
+	for _, u := range m.BioURLs {
+		b = appendMessage(b, 6, u.appendTo(nil))
+	}
+	b = appendString(b, 7, m.Location)
+	b = appendString(b, 8, m.Website)
+	b = appendInt64(b, 9, m.CreatedAt)
+	b = appendString(b, 10, m.AvatarURL)
+	b = appendString(b, 11, m.BannerURL)
+	b = appendInt64(b, 12, m.Followers)
+	b = appendInt64(b, 13, m.Following)
+	b = appendInt64(b, 14, m.Tweets)
+	b = appendInt64(b, 15, m.Likes)
+	b = appendInt64(b, 16, m.Media)
+	b = appendInt64(b, 17, m.Listed)
+	b = appendBool(b, 18, m.IsVerified)
+	b = appendBool(b, 19, m.IsBlueVerified)
+	b = appendString(b, 20, m.VerifiedType)
+	if m.Affiliate != nil {
+		b = appendMessage(b, 21, m.Affiliate.appendTo(nil))
+	}
+	b = appendBool(b, 22, m.IsProtected)
+	return b
+}
+
+// Unmarshal decodes user from Protocol Buffers wire format. Unknown fields are skipped.
+func (m *User) Unmarshal(data []byte) error {
+	*m = User{}
+	d := decoder{data: data}
+	for {
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch field {
+		case 1:
+			m.ID, err = d.string(wireType)
+		case 2:
+			m.Username, err = d.string(wireType)
+		case 3:
+			m.Name, err = d.string(wireType)
+		case 4:
+			m.Bio, err = d.string(wireType)
+		case 5:
+			m.BioHTML, err = d.string(wireType)
+		case 6:
+			u := &URL{}
+			err = decodeMessage(&d, wireType, u.Unmarshal)
+			m.BioURLs = append(m.BioURLs, u)
+		case 7:
+			m.Location, err = d.string(wireType)
+		case 8:
+			m.Website, err = d.string(wireType)
+		case 9:
+			m.CreatedAt, err = d.int64(wireType)
+		case 10:
+			m.AvatarURL, err = d.string(wireType)
+		case 11:
+			m.BannerURL, err = d.string(wireType)
+		case 12:
+			m.Followers, err = d.int64(wireType)
+		case 13:
+			m.Following, err = d.int64(wireType)
+		case 14:
+			m.Tweets, err = d.int64(wireType)
+		case 15:
+			m.Likes, err = d.int64(wireType)
+		case 16:
+			m.Media, err = d.int64(wireType)
+		case 17:
+			m.Listed, err = d.int64(wireType)
+		case 18:
+			m.IsVerified, err = d.bool(wireType)
+		case 19:
+			m.IsBlueVerified, err = d.bool(wireType)
+		case 20:
+			m.VerifiedType, err = d.string(wireType)
+		case 21:
+			m.Affiliate = &Affiliate{}
+			err = decodeMessage(&d, wireType, m.Affiliate.Unmarshal)
+		case 22:
+			m.IsProtected, err = d.bool(wireType)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Marshal encodes affiliate badge in Protocol Buffers wire format
+func (m *Affiliate) Marshal() []byte {
+	return m.appendTo(nil)
+}
+
+func (m *Affiliate) appendTo(b []byte) []byte {
+	b = appendString(b, 1, m.Name)
+	b = appendString(b, 2, m.URL)
+	b = appendString(b, 3, m.BadgeURL)
+	b = appendString(b, 4, m.LabelType)
+	return b
+}
+
+// Unmarshal decodes affiliate badge from Protocol Buffers wire format. Unknown fields are skipped.
+func (m *Affiliate) Unmarshal(data []byte) error {
+	*m = Affiliate{}
+	d := decoder{data: data}
+	for {
+		field, wireType, err := d.next()
+		if err != nil || field == 0 {
+			return err
+		}
+
+		switch field {
+		case 1:
+			m.Name, err = d.string(wireType)
+		case 2:
+			m.URL, err = d.string(wireType)
+		case 3:
+			m.BadgeURL, err = d.string(wireType)
+		case 4:
+			m.LabelType, err = d.string(wireType)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// appendDecodedString reads string value of repeated field and appends it to values
+func appendDecodedString(values []string, d *decoder, wireType int) ([]string, error) {
+	s, err := d.string(wireType)
+	if err != nil {
+		return values, err
+	}
+	return append(values, s), nil
+}
+
+// decodeMessage reads embedded message and decodes it with unmarshal
+func decodeMessage(d *decoder, wireType int, unmarshal func([]byte) error) error {
+	data, err := d.message(wireType)
+	if err != nil {
+		return err
+	}
+	return unmarshal(data)
+}
@@ -0,0 +1,96 @@
+package pb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+func TestTweetRoundTrip(t *testing.T) {
+	tweet := twittertimeline.Tweet{
+		ID:           "2",
+		Text:         "Привет #go",
+		HTML:         `Привет <a href="https://x.com/hashtag/go">#go</a>`,
+		Lang:         "ru",
+		CreatedAt:    "Mon Jan 01 09:30:00 +0000 2024",
+		PermanentURL: "https://x.com/alice/status/2",
+		Username:     "alice",
+		UserID:       "42",
+		Likes:        5,
+		Views:        1 << 40,
+		IsReply:      true,
+		InReplyToID:  "1",
+		Images:       []string{"https://pbs.twimg.com/media/a.jpg", ""},
+		Media:        []twittertimeline.Media{{Type: twittertimeline.MediaVideo, URL: "https://video.twimg.com/1.mp4", Duration: 1500 * time.Millisecond}},
+		Hashtags:     []string{"go"},
+		URLs:         []twittertimeline.URL{{Short: "https://t.co/a", Expanded: "https://go.dev", Display: "go.dev"}},
+		QuotedTweet: &twittertimeline.QuotedTweet{
+			Tweet:       twittertimeline.Tweet{ID: "1", Text: "Quoted"},
+			Unavailable: true,
+			Reason:      "deleted",
+		},
+	}
+
+	var decoded Tweet
+	if err := decoded.Unmarshal(FromTweet(&tweet).Marshal()); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := decoded.ToTweet(); !reflect.DeepEqual(got, tweet) {
+		t.Errorf("Round trip mismatch:\ngot  %+v\nwant %+v", got, tweet)
+	}
+}
+
+func TestUserRoundTrip(t *testing.T) {
+	user := twittertimeline.User{
+		ID:           "42",
+		Username:     "alice",
+		Name:         "Alice",
+		BioURLs:      []twittertimeline.URL{{Short: "https://t.co/b", Expanded: "https://alice.dev", Display: "alice.dev"}},
+		CreatedAt:    "Sat Jun 02 20:12:29 +0000 2007",
+		Followers:    1000,
+		IsVerified:   true,
+		VerifiedType: twittertimeline.VerifiedTypeBusiness,
+		Affiliate:    &twittertimeline.Affiliate{Name: "Org", LabelType: "BusinessLabel"},
+	}
+
+	var decoded User
+	if err := decoded.Unmarshal(FromUser(&user).Marshal()); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := decoded.ToUser(); !reflect.DeepEqual(got, user) {
+		t.Errorf("Round trip mismatch:\ngot  %+v\nwant %+v", got, user)
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	// Bytes produced by protoc-generated code for the same message
+	media := &Media{Type: "photo", DurationMs: 150}
+	expected := []byte{0x0a, 0x05, 'p', 'h', 'o', 't', 'o', 0x20, 0x96, 0x01}
+	if data := media.Marshal(); !bytes.Equal(data, expected) {
+		t.Errorf("Unexpected encoding: % x", data)
+	}
+
+	// Fields of newer schema versions are skipped
+	data := append([]byte{}, expected...)
+	data = append(data, 0xf8, 0x07, 0x01)                   // field 127, varint
+	data = append(data, 0x9a, 0x08, 0x01, 'x')              // field 131, bytes
+	data = append(data, 0xa1, 0x08, 1, 2, 3, 4, 5, 6, 7, 8) // field 132, fixed64
+	var decoded Media
+	if err := decoded.Unmarshal(data); err != nil || decoded != *media {
+		t.Errorf("Unexpected decoding with unknown fields: %+v, %v", decoded, err)
+	}
+
+	for _, invalid := range [][]byte{
+		expected[:len(expected)-1], // truncated varint
+		{0x0a, 0x05, 'p'},          // truncated string
+		{0x08, 0x01},               // wrong wire type of string field
+		{0x00},                     // zero field number
+	} {
+		if err := decoded.Unmarshal(invalid); err == nil {
+			t.Errorf("Expected error for % x", invalid)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package pb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Protocol Buffers wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errTruncated is returned when message ends in the middle of a field
+var errTruncated = errors.New("pb: truncated message")
+
+// appendVarint appends base 128 varint
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// appendTag appends field key
+func appendTag(b []byte, field int, wireType int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+// appendString appends string field, omitting empty value as proto3 does
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendRepeatedString appends every value of repeated string field, including empty ones
+func appendRepeatedString(b []byte, field int, values []string) []byte {
+	for _, s := range values {
+		b = appendTag(b, field, wireBytes)
+		b = appendVarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	return b
+}
+
+// appendInt64 appends int64 field, omitting zero value
+func appendInt64(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, uint64(v))
+}
+
+// appendBool appends bool field, omitting false value
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return append(b, 1)
+}
+
+// appendMessage appends embedded message field
+func appendMessage(b []byte, field int, message []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(message)))
+	return append(b, message...)
+}
+
+// decoder reads fields of encoded message
+type decoder struct {
+	data []byte
+}
+
+// next reads key of the next field. It returns zero field at the end of message.
+func (d *decoder) next() (field int, wireType int, err error) {
+	if len(d.data) == 0 {
+		return 0, 0, nil
+	}
+	key, err := d.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	field, wireType = int(key>>3), int(key&7)
+	if field <= 0 {
+		return 0, 0, fmt.Errorf("pb: invalid field number %d", field)
+	}
+	return field, wireType, nil
+}
+
+// varint reads base 128 varint
+func (d *decoder) varint() (uint64, error) {
+	var v uint64
+	for i := 0; i < 10; i++ {
+		if i >= len(d.data) {
+			return 0, errTruncated
+		}
+		c := d.data[i]
+		v |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			d.data = d.data[i+1:]
+			return v, nil
+		}
+	}
+	return 0, errors.New("pb: varint overflow")
+}
+
+// bytes reads length-delimited value
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)) {
+		return nil, errTruncated
+	}
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// string reads string value of field with the wire type
+func (d *decoder) string(wireType int) (string, error) {
+	if wireType != wireBytes {
+		return "", fmt.Errorf("pb: unexpected wire type %d for string", wireType)
+	}
+	v, err := d.bytes()
+	return string(v), err
+}
+
+// message reads embedded message of field with the wire type
+func (d *decoder) message(wireType int) ([]byte, error) {
+	if wireType != wireBytes {
+		return nil, fmt.Errorf("pb: unexpected wire type %d for message", wireType)
+	}
+	return d.bytes()
+}
+
+// int64 reads int64 value of field with the wire type
+func (d *decoder) int64(wireType int) (int64, error) {
+	if wireType != wireVarint {
+		return 0, fmt.Errorf("pb: unexpected wire type %d for integer", wireType)
+	}
+	v, err := d.varint()
+	return int64(v), err
+}
+
+// bool reads bool value of field with the wire type
+func (d *decoder) bool(wireType int) (bool, error) {
+	v, err := d.int64(wireType)
+	return v != 0, err
+}
+
+// skip skips value of unknown field, so that messages of newer schema can be decoded
+func (d *decoder) skip(wireType int) error {
+	switch wireType {
+	case wireVarint:
+		_, err := d.varint()
+		return err
+	case wireBytes:
+		_, err := d.bytes()
+		return err
+	case wireFixed64, wireFixed32:
+		n := 8
+		if wireType == wireFixed32 {
+			n = 4
+		}
+		if len(d.data) < n {
+			return errTruncated
+		}
+		d.data = d.data[n:]
+		return nil
+	default:
+		return fmt.Errorf("pb: unsupported wire type %d", wireType)
+	}
+}
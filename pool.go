@@ -0,0 +1,31 @@
+package twittertimeline
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize limits size of buffers returned to the pool,
+// so occasional huge responses do not stay in memory forever
+const maxPooledBufferSize = 4 << 20
+
+// bufferPool reuses buffers for response bodies and generated HTML
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buffer to the pool. The buffer must not be used afterwards.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
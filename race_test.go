@@ -0,0 +1,8 @@
+//go:build race
+
+package twittertimeline
+
+func init() {
+	// Race detector adds allocations and randomly drops sync.Pool items
+	raceEnabled = true
+}
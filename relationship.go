@@ -0,0 +1,75 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Relationship describes relationship between source and target users
+type Relationship struct {
+	SourceID       string
+	SourceUsername string
+	TargetID       string
+	TargetUsername string
+
+	Following  bool // Source follows target
+	FollowedBy bool // Target follows source
+
+	// Available only when authenticated as source user, nil otherwise
+	Blocking *bool // Source blocks target
+	Muting   *bool // Source mutes target
+}
+
+// FriendshipResponse represents response of v1.1 friendships/show endpoint
+type FriendshipResponse struct {
+	Relationship struct {
+		Source struct {
+			IDStr      string `json:"id_str"`
+			ScreenName string `json:"screen_name"`
+			Following  bool   `json:"following"`
+			FollowedBy bool   `json:"followed_by"`
+			Blocking   *bool  `json:"blocking"`
+			Muting     *bool  `json:"muting"`
+		} `json:"source"`
+		Target struct {
+			IDStr      string `json:"id_str"`
+			ScreenName string `json:"screen_name"`
+		} `json:"target"`
+	} `json:"relationship"`
+}
+
+// GetRelationship gets relationship between users with the given IDs from v1.1 REST API
+func (c *Client) GetRelationship(sourceID, targetID string) (*Relationship, error) {
+	params := url.Values{}
+	params.Add("source_id", sourceID)
+	params.Add("target_id", targetID)
+
+	resp, err := c.makeLegacyAPICall(LegacyFriendshipShowPath, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var friendshipResp FriendshipResponse
+	if err := json.NewDecoder(resp.Body).Decode(&friendshipResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	source := &friendshipResp.Relationship.Source
+	target := &friendshipResp.Relationship.Target
+	if source.IDStr == "" || target.IDStr == "" {
+		return nil, &NotFoundError{Kind: "relationship", ID: sourceID + " -> " + targetID}
+	}
+
+	return &Relationship{
+		SourceID:       source.IDStr,
+		SourceUsername: source.ScreenName,
+		TargetID:       target.IDStr,
+		TargetUsername: target.ScreenName,
+		Following:      source.Following,
+		FollowedBy:     source.FollowedBy,
+		Blocking:       source.Blocking,
+		Muting:         source.Muting,
+	}, nil
+}
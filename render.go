@@ -0,0 +1,372 @@
+package twittertimeline
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// LineBreakMode controls how newlines of tweet text are rendered in HTML
+type LineBreakMode int
+
+// Line break modes
+const (
+	LineBreakBR         LineBreakMode = iota // Newlines are converted to <br> (default)
+	LineBreakParagraphs                      // Blank-line separated paragraphs are wrapped in <p>, other newlines converted to <br>
+	LineBreakNone                            // Newlines are kept as is
+)
+
+// renderOptions controls how tweet text, HTML and derived fields are produced from API data
+type renderOptions struct {
+	trimMediaLinks bool          // Strip trailing t.co links of attached media
+	unescapeText   bool          // Return plain text without HTML entities
+	lineBreaks     LineBreakMode // Rendering of newlines in HTML
+	detectLanguage bool          // Detect language of text when API doesn't determine it
+}
+
+// trimMediaLinks removes trailing t.co links of attached media from tweet text,
+// matching text displayed by Twitter
+func trimMediaLinks(text string, media ...[]MediaEntity) string {
+	for {
+		trimmed := strings.TrimRight(text, " \n")
+		found := false
+		for _, entities := range media {
+			for _, entity := range entities {
+				if entity.URL != "" && strings.HasSuffix(trimmed, entity.URL) {
+					trimmed = strings.TrimSuffix(trimmed, entity.URL)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return text
+		}
+		text = strings.TrimRight(trimmed, " \n")
+	}
+}
+
+// HTML links for tweet entities
+func urlLink(entity URLEntity) string {
+	expandedURL := entity.ExpandedURL
+	if expandedURL == "" {
+		expandedURL = entity.URL
+	}
+	return fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`,
+		html.EscapeString(expandedURL),
+		html.EscapeString(entity.DisplayURL))
+}
+
+func hashtagLink(hashtag, display string) string {
+	return fmt.Sprintf(`<a href="https://x.com/hashtag/%s" target="_blank">%s</a>`,
+		html.EscapeString(url.PathEscape(hashtag)),
+		html.EscapeString(display))
+}
+
+func cashtagLink(cashtag, display string) string {
+	return fmt.Sprintf(`<a href="https://x.com/search?q=%%24%s&amp;src=cashtag_click" target="_blank">%s</a>`,
+		html.EscapeString(url.QueryEscape(cashtag)),
+		html.EscapeString(display))
+}
+
+func mentionLink(screenName, display string) string {
+	return fmt.Sprintf(`<a href="https://x.com/%s" target="_blank">%s</a>`,
+		html.EscapeString(screenName),
+		html.EscapeString(display))
+}
+
+// entitySpan is an entity range of tweet text replaced with HTML link
+type entitySpan struct {
+	start, end int
+	link       string
+}
+
+// linkEntitiesByIndices builds HTML from API tweet text with escaped &, < and >, replacing entities
+// at their index ranges. Text between entities is unescaped and escaped again, so that it is
+// escaped the same way as text of tweets without entities.
+// It reports false when the tweet has no entities or any entity indices do not match the text.
+func linkEntitiesByIndices(rawText string, tweetResult *TweetResult) (string, bool) {
+	entities := &tweetResult.Legacy.Entities
+	n := len(entities.Urls) + len(entities.Hashtags) + len(entities.Symbols) + len(entities.UserMentions)
+	if n == 0 {
+		return "", false
+	}
+
+	runes := []rune(rawText)
+	spans := make([]entitySpan, 0, n)
+
+	// segment returns entity text at the indices or false if they are out of range
+	segment := func(indices [2]int) (string, bool) {
+		if indices[0] < 0 || indices[0] >= indices[1] || indices[1] > len(runes) {
+			return "", false
+		}
+		return string(runes[indices[0]:indices[1]]), true
+	}
+
+	for _, entity := range entities.Urls {
+		text, ok := segment(entity.Indices)
+		if !ok || text != entity.URL {
+			return "", false
+		}
+		spans = append(spans, entitySpan{entity.Indices[0], entity.Indices[1], urlLink(entity)})
+	}
+	for _, entity := range entities.Hashtags {
+		text, ok := segment(entity.Indices)
+		if !ok || !strings.EqualFold(trimEntityPrefix(text, '#', '＃'), entity.Text) {
+			return "", false
+		}
+		spans = append(spans, entitySpan{entity.Indices[0], entity.Indices[1], hashtagLink(entity.Text, text)})
+	}
+	for _, entity := range entities.Symbols {
+		text, ok := segment(entity.Indices)
+		if !ok || !strings.EqualFold(trimEntityPrefix(text, '$', '＄'), entity.Text) {
+			return "", false
+		}
+		spans = append(spans, entitySpan{entity.Indices[0], entity.Indices[1], cashtagLink(entity.Text, text)})
+	}
+	for _, entity := range entities.UserMentions {
+		text, ok := segment(entity.Indices)
+		if !ok || !strings.EqualFold(trimEntityPrefix(text, '@', '＠'), entity.ScreenName) {
+			return "", false
+		}
+		spans = append(spans, entitySpan{entity.Indices[0], entity.Indices[1], mentionLink(entity.ScreenName, text)})
+	}
+
+	// Insertion sort, entities are few and mostly grouped by type in order
+	for i := 1; i < len(spans); i++ {
+		for j := i; j > 0 && spans[j].start < spans[j-1].start; j-- {
+			spans[j], spans[j-1] = spans[j-1], spans[j]
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(rawText) + 64*len(spans))
+	pos := 0
+	for _, span := range spans {
+		if span.start < pos {
+			return "", false // Overlapping entities
+		}
+		b.WriteString(html.EscapeString(html.UnescapeString(string(runes[pos:span.start]))))
+		b.WriteString(span.link)
+		pos = span.end
+	}
+	b.WriteString(html.EscapeString(html.UnescapeString(string(runes[pos:]))))
+
+	return b.String(), true
+}
+
+// unescapeEntityIndices converts entity indices from rune offsets in API text
+// to offsets in its unescaped form, see WithUnescapedText
+func unescapeEntityIndices(tweetResult *TweetResult, rawText string) {
+	offsets := unescapedOffsets(rawText)
+	shift := func(indices *[2]int) {
+		if indices[0] >= 0 && indices[0] <= indices[1] && indices[1] < len(offsets) {
+			indices[0], indices[1] = offsets[indices[0]], offsets[indices[1]]
+		}
+	}
+
+	entities := &tweetResult.Legacy.Entities
+	for i := range entities.Hashtags {
+		shift(&entities.Hashtags[i].Indices)
+	}
+	for i := range entities.Symbols {
+		shift(&entities.Symbols[i].Indices)
+	}
+	for i := range entities.Urls {
+		shift(&entities.Urls[i].Indices)
+	}
+	for i := range entities.UserMentions {
+		shift(&entities.UserMentions[i].Indices)
+	}
+	for i := range entities.Media {
+		shift(&entities.Media[i].Indices)
+	}
+	for i := range tweetResult.Legacy.ExtendedEntities.Media {
+		shift(&tweetResult.Legacy.ExtendedEntities.Media[i].Indices)
+	}
+}
+
+// unescapedOffsets maps every rune offset of text with HTML character references to the offset
+// in unescaped text. The extra last element maps the end of text.
+func unescapedOffsets(text string) []int {
+	runes := []rune(text)
+	offsets := make([]int, len(runes)+1)
+	plain := 0
+	for i := 0; i < len(runes); {
+		if runes[i] == '&' {
+			if end := indexRune(runes[i:], ';'); end > 0 && end <= maxReferenceLength {
+				reference := string(runes[i : i+end+1])
+				if unescaped := html.UnescapeString(reference); unescaped != reference {
+					for j := i; j <= i+end; j++ {
+						offsets[j] = plain
+					}
+					plain += utf8.RuneCountInString(unescaped)
+					i += end + 1
+					continue
+				}
+			}
+		}
+		offsets[i] = plain
+		plain++
+		i++
+	}
+	offsets[len(runes)] = plain
+	return offsets
+}
+
+// maxReferenceLength limits distance between "&" and ";" of HTML character reference
+const maxReferenceLength = 32
+
+// indexRune returns index of the first rune r in runes or -1
+func indexRune(runes []rune, r rune) int {
+	for i, c := range runes {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// trimEntityPrefix removes leading entity sign in ASCII or fullwidth form
+func trimEntityPrefix(text string, prefixes ...rune) string {
+	for _, prefix := range prefixes {
+		if trimmed := strings.TrimPrefix(text, string(prefix)); trimmed != text {
+			return trimmed
+		}
+	}
+	return text
+}
+
+// linkEntitiesByText replaces entities in escaped tweet text with HTML links by matching their texts.
+// It is used for sources without entity indices, such as Nitter.
+func linkEntitiesByText(text string, tweetResult *TweetResult) string {
+	entities := &tweetResult.Legacy.Entities
+
+	// Replace URLs with HTML links
+	for _, entity := range entities.Urls {
+		text = strings.ReplaceAll(text, entity.URL, urlLink(entity))
+	}
+
+	// Replace hashtags with HTML links in a single pass
+	if len(entities.Hashtags) > 0 && strings.IndexByte(text, '#') >= 0 {
+		hashtags := make(map[string]string, len(entities.Hashtags))
+		for _, hashtag := range entities.Hashtags {
+			hashtags[strings.ToLower(hashtag.Text)] = hashtag.Text
+		}
+		text = hashtagRegex.ReplaceAllStringFunc(text, func(match string) string {
+			hashtag, ok := hashtags[strings.ToLower(match[1:])]
+			if !ok {
+				return match
+			}
+			return hashtagLink(hashtag, "#"+hashtag)
+		})
+	}
+
+	// Replace cashtags with HTML links to search
+	if len(entities.Symbols) > 0 && strings.IndexByte(text, '$') >= 0 {
+		cashtags := make(map[string]string, len(entities.Symbols))
+		for _, symbol := range entities.Symbols {
+			cashtags[strings.ToLower(symbol.Text)] = symbol.Text
+		}
+		text = cashtagRegex.ReplaceAllStringFunc(text, func(match string) string {
+			cashtag, ok := cashtags[strings.ToLower(match[1:])]
+			if !ok {
+				return match
+			}
+			return cashtagLink(cashtag, "$"+cashtag)
+		})
+	}
+
+	// Replace mentions with HTML links
+	if strings.IndexByte(text, '@') >= 0 {
+		text = mentionRegex.ReplaceAllString(text, `<a href="https://x.com/$1" target="_blank">@$1</a>`)
+	}
+
+	return text
+}
+
+// renderLineBreaks converts newlines of HTML tweet text according to the mode
+func renderLineBreaks(text string, mode LineBreakMode) string {
+	if strings.IndexByte(text, '\n') < 0 {
+		if mode == LineBreakParagraphs && text != "" {
+			return "<p>" + text + "</p>"
+		}
+		return text
+	}
+
+	switch mode {
+	case LineBreakNone:
+		return text
+	case LineBreakParagraphs:
+		var b strings.Builder
+		for _, paragraph := range strings.Split(text, "\n\n") {
+			paragraph = strings.Trim(paragraph, "\n")
+			if strings.TrimSpace(paragraph) == "" {
+				continue
+			}
+			b.WriteString("<p>")
+			b.WriteString(strings.ReplaceAll(paragraph, "\n", "<br>"))
+			b.WriteString("</p>")
+		}
+		return b.String()
+	default:
+		return strings.ReplaceAll(text, "\n", "<br>")
+	}
+}
+
+// tweetEntities collects entities of tweet text that have indices, ordered by position
+func tweetEntities(tweetResult *TweetResult) []Entity {
+	entities := &tweetResult.Legacy.Entities
+	media := tweetResult.Legacy.ExtendedEntities.Media
+	if len(media) == 0 {
+		media = entities.Media
+	}
+	n := len(entities.Hashtags) + len(entities.Symbols) + len(entities.Urls) + len(entities.UserMentions) + len(media)
+	if n == 0 {
+		return nil
+	}
+
+	result := make([]Entity, 0, n)
+	add := func(entityType EntityType, value string, indices [2]int) {
+		// Sources without indices leave them zeroed
+		if indices[1] > indices[0] {
+			result = append(result, Entity{Type: entityType, Value: value, Start: indices[0], End: indices[1]})
+		}
+	}
+	for _, hashtag := range entities.Hashtags {
+		add(EntityHashtag, hashtag.Text, hashtag.Indices)
+	}
+	for _, symbol := range entities.Symbols {
+		add(EntityCashtag, symbol.Text, symbol.Indices)
+	}
+	for _, entity := range entities.Urls {
+		expandedURL := entity.ExpandedURL
+		if expandedURL == "" {
+			expandedURL = entity.URL
+		}
+		add(EntityURL, expandedURL, entity.Indices)
+	}
+	for _, mention := range entities.UserMentions {
+		add(EntityMention, mention.ScreenName, mention.Indices)
+	}
+
+	// Media link may be trimmed from text by WithDisplayText
+	if len(media) > 0 && strings.Contains(tweetResult.Legacy.FullText, media[0].URL) {
+		for i, entity := range media {
+			// All photos of a tweet share a single link
+			if i > 0 && entity.Indices == media[i-1].Indices {
+				continue
+			}
+			add(EntityMedia, entity.MediaURLHTTPS, entity.Indices)
+		}
+	}
+
+	for i := 1; i < len(result); i++ {
+		for j := i; j > 0 && result[j].Start < result[j-1].Start; j-- {
+			result[j], result[j-1] = result[j-1], result[j]
+		}
+	}
+
+	return result
+}
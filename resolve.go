@@ -0,0 +1,113 @@
+package twittertimeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hostPattern matches hosts of x.com, twitter.com and embed fixing mirrors like fxtwitter.com
+const hostPattern = `^(?i:https?://)?(?i:(?:www\.|mobile\.)?(?:x|twitter|fxtwitter|vxtwitter|fixupx|fixvx)\.com)`
+
+// Regexes for user input parsing
+var (
+	userIDRegex   = regexp.MustCompile(`^\d{1,19}$`)
+	usernameRegex = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+	profileRegex  = regexp.MustCompile(hostPattern + `/([^/?#]+)(?:/([^/?#]+))?(?:/([^/?#]+))?`)
+	tweetURLRegex = regexp.MustCompile(hostPattern + `/(?:([A-Za-z0-9_]{1,15})|i/web)/status(?:es)?/(\d{1,19})(?:[/?#]|$)`)
+)
+
+// Paths of x.com that are not user profiles
+var reservedPaths = map[string]bool{
+	"home": true, "explore": true, "search": true, "settings": true, "notifications": true,
+	"messages": true, "hashtag": true, "intent": true, "share": true, "login": true, "tos": true, "privacy": true,
+}
+
+// UserRef is a user reference resolved from user input
+type UserRef struct {
+	ID       string // User ID
+	Username string // Username without @, empty if input was a numeric ID
+}
+
+// ParseUserInput parses user ID or username from "@name", "name", numeric ID
+// or profile URL ("x.com/name", "https://twitter.com/name/status/1", "x.com/i/user/123").
+// Bare numbers are treated as user IDs, use "@123" for numeric usernames.
+func ParseUserInput(input string) (UserRef, error) {
+	input = strings.TrimSpace(input)
+
+	if profileRegex.MatchString(input) {
+		return ParseProfileURL(input)
+	}
+
+	if strings.HasPrefix(input, "@") {
+		username := input[1:]
+		if !usernameRegex.MatchString(username) {
+			return UserRef{}, fmt.Errorf("invalid username: %s", input)
+		}
+		return UserRef{Username: username}, nil
+	}
+
+	if userIDRegex.MatchString(input) {
+		return UserRef{ID: input}, nil
+	}
+	if usernameRegex.MatchString(input) {
+		return UserRef{Username: input}, nil
+	}
+
+	return UserRef{}, fmt.Errorf("invalid user ID, username or profile URL: %s", input)
+}
+
+// ParseProfileURL parses user from profile URL on x.com, twitter.com, mobile.twitter.com
+// or fxtwitter.com and similar mirrors ("x.com/name", "https://twitter.com/name/status/1", "x.com/i/user/123")
+func ParseProfileURL(s string) (UserRef, error) {
+	s = strings.TrimSpace(s)
+	match := profileRegex.FindStringSubmatch(s)
+	if match == nil {
+		return UserRef{}, fmt.Errorf("not a profile URL: %s", s)
+	}
+
+	// Profile by ID: x.com/i/user/<id>
+	if match[1] == "i" && match[2] == "user" && userIDRegex.MatchString(match[3]) {
+		return UserRef{ID: match[3]}, nil
+	}
+	if reservedPaths[strings.ToLower(match[1])] || match[1] == "i" {
+		return UserRef{}, fmt.Errorf("not a user profile URL: %s", s)
+	}
+	if !usernameRegex.MatchString(match[1]) {
+		return UserRef{}, fmt.Errorf("invalid username: %s", s)
+	}
+	return UserRef{Username: match[1]}, nil
+}
+
+// ParseTweetURL parses author username and tweet ID from tweet URL on x.com, twitter.com, mobile.twitter.com
+// or fxtwitter.com and similar mirrors ("https://x.com/name/status/123/photo/1").
+// Username is empty for URLs without it ("x.com/i/status/123", "twitter.com/i/web/status/123").
+func ParseTweetURL(s string) (username, tweetID string, err error) {
+	s = strings.TrimSpace(s)
+	match := tweetURLRegex.FindStringSubmatch(s)
+	if match == nil {
+		return "", "", fmt.Errorf("not a tweet URL: %s", s)
+	}
+	if match[1] != "i" {
+		username = match[1]
+	}
+	return username, match[2], nil
+}
+
+// ResolveUser parses user input (see ParseUserInput) and resolves username to user ID
+// using the cached GetUserID
+func (c *Client) ResolveUser(input string) (*UserRef, error) {
+	ref, err := ParseUserInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.ID == "" {
+		ref.ID, err = c.GetUserID(ref.Username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ref, nil
+}
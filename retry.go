@@ -0,0 +1,72 @@
+package twittertimeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Retries of transient failures allowed by retry budget
+const (
+	maxTransientRetries = 2
+	retryBackoff        = 500 * time.Millisecond // Doubled on every retry
+)
+
+// retryBudget throttles retries of all goroutines sharing the client like gRPC retry throttling:
+// every transient failure takes a token, every success returns ratio of a token,
+// and retries are allowed only while more than half of tokens are left
+type retryBudget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// newRetryBudget creates a full budget
+func newRetryBudget(maxTokens, ratio float64) *retryBudget {
+	return &retryBudget{tokens: maxTokens, maxTokens: maxTokens, ratio: ratio}
+}
+
+// success returns ratio of a token to the budget
+func (b *retryBudget) success() {
+	b.mu.Lock()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+	b.mu.Unlock()
+}
+
+// failure takes a token and reports whether the failed request may be retried
+func (b *retryBudget) failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = max(b.tokens-1, 0)
+	return b.tokens > b.maxTokens/2
+}
+
+// isTransientError reports whether request failed due to server error status, timeout, reset or refused
+// connection, or truncated response. Canceled requests and other network errors, e.g. invalid URL,
+// unknown host or TLS failure, are not expected to succeed on retry.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// sleep waits for d using client clock
+func (c *Client) sleep(d time.Duration) {
+	ticker := c.clock.Ticker(d)
+	defer ticker.Stop()
+	<-ticker.Chan()
+}
@@ -0,0 +1,247 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hashtagTagRegex matches hashtag text without "#"
+var hashtagTagRegex = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_]+$`)
+
+// SearchOptions configures tweet search
+type SearchOptions struct {
+	MaxPages        int    // Pages of results to fetch, all available pages if <= 0
+	Top             bool   // Top tweets instead of the latest ones
+	Lang            string // Only tweets in language with the code, e.g. "en"
+	ExcludeRetweets bool   // Skip retweets
+	ExcludeReplies  bool   // Skip replies
+}
+
+// SearchTweetsResponse represents response of GraphQL search timeline with tweets
+type SearchTweetsResponse struct {
+	Data struct {
+		SearchByRawQuery struct {
+			SearchTimeline struct {
+				Timeline struct {
+					Instructions []struct {
+						Type    string          `json:"type"`
+						Entries []TimelineEntry `json:"entries"`
+						Entry   *TimelineEntry  `json:"entry"`
+					} `json:"instructions"`
+				} `json:"timeline"`
+			} `json:"search_timeline"`
+		} `json:"search_by_raw_query"`
+	} `json:"data"`
+}
+
+// SearchTimelineResponse represents response of GraphQL search timeline
+type SearchTimelineResponse struct {
+	Data struct {
+		SearchByRawQuery struct {
+			SearchTimeline struct {
+				Timeline struct {
+					Instructions []struct {
+						Type    string `json:"type"`
+						Entries []struct {
+							EntryID string `json:"entryId"`
+							Content struct {
+								EntryType   string `json:"entryType"`
+								CursorType  string `json:"cursorType"`
+								Value       string `json:"value"`
+								ItemContent struct {
+									UserResults struct {
+										Result UserResult `json:"result"`
+									} `json:"user_results"`
+								} `json:"itemContent"`
+							} `json:"content"`
+						} `json:"entries"`
+						Entry *struct {
+							Content struct {
+								CursorType string `json:"cursorType"`
+								Value      string `json:"value"`
+							} `json:"content"`
+						} `json:"entry"`
+					} `json:"instructions"`
+				} `json:"timeline"`
+			} `json:"search_timeline"`
+		} `json:"search_by_raw_query"`
+	} `json:"data"`
+}
+
+// SearchUsers searches accounts by name or keyword and returns users from up to maxPages pages
+// of results (all available pages if maxPages <= 0).
+// On error, users fetched before the failure are returned along with the error.
+func (c *Client) SearchUsers(query string, maxPages int) ([]User, error) {
+	seen := make(map[string]struct{})
+
+	var users []User
+	cursor := ""
+	for page := 0; maxPages <= 0 || page < maxPages; page++ {
+		pageUsers, bottomCursor, err := c.searchUsersPage(query, cursor)
+		if err != nil {
+			return users, err
+		}
+
+		added := 0
+		for _, user := range pageUsers {
+			if _, ok := seen[user.ID]; ok {
+				continue
+			}
+			seen[user.ID] = struct{}{}
+			users = append(users, user)
+			added++
+		}
+
+		// Page without new users or missing cursor means the end of results
+		if added == 0 || bottomCursor == "" || bottomCursor == cursor {
+			break
+		}
+		cursor = bottomCursor
+	}
+
+	return users, nil
+}
+
+// searchUsersPage fetches a single page of people search results
+func (c *Client) searchUsersPage(query, cursor string) ([]User, string, error) {
+	variables := map[string]any{
+		"rawQuery":    query,
+		"count":       20,
+		"querySource": "typed_query",
+		"product":     "People",
+	}
+	if cursor != "" {
+		variables["cursor"] = cursor
+	}
+
+	resp, err := c.makeAPICall(SearchTimelinePath, variables, timelineFeatures(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var searchResp SearchTimelineResponse
+	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+		return nil, "", fmt.Errorf("error decoding response: %w", err)
+	}
+
+	var users []User
+	var bottomCursor string
+	for _, instruction := range searchResp.Data.SearchByRawQuery.SearchTimeline.Timeline.Instructions {
+		for _, entry := range instruction.Entries {
+			if entry.Content.EntryType == "TimelineTimelineCursor" {
+				if entry.Content.CursorType == "Bottom" {
+					bottomCursor = entry.Content.Value
+				}
+				continue
+			}
+			if result := &entry.Content.ItemContent.UserResults.Result; result.RestID != "" {
+				users = append(users, *convertUserResult(result))
+			}
+		}
+		// Later pages replace the cursor entry instead of adding it
+		if instruction.Type == "TimelineReplaceEntry" && instruction.Entry != nil &&
+			instruction.Entry.Content.CursorType == "Bottom" {
+			bottomCursor = instruction.Entry.Content.Value
+		}
+	}
+
+	return users, bottomCursor, nil
+}
+
+// SearchTweets searches tweets with search query syntax, e.g. "golang from:golang since:2024-01-01".
+// Tweets repeated on several pages are returned once.
+// On error, tweets fetched before the failure are returned along with the error.
+func (c *Client) SearchTweets(query string, opts SearchOptions) ([]Tweet, error) {
+	if opts.Lang != "" {
+		query += " lang:" + opts.Lang
+	}
+	if opts.ExcludeRetweets {
+		query += " -filter:retweets"
+	}
+	if opts.ExcludeReplies {
+		query += " -filter:replies"
+	}
+	product := "Latest"
+	if opts.Top {
+		product = "Top"
+	}
+
+	seen := make(map[string]struct{})
+
+	var tweets []Tweet
+	cursor := ""
+	for page := 0; opts.MaxPages <= 0 || page < opts.MaxPages; page++ {
+		if page > 0 && !c.waitPageDelay(nil) {
+			break
+		}
+
+		collector, err := c.searchTweetsPage(query, product, cursor)
+		if err != nil {
+			return tweets, err
+		}
+		c.resolveCollectorQuotes(collector)
+		pageTweets := dedupTweets(collector.tweets(), seen)
+		for i := range pageTweets {
+			c.runTweetHooks(&pageTweets[i])
+		}
+		tweets = append(tweets, pageTweets...)
+
+		// Empty page or missing cursor means the end of results
+		if len(collector.tweetResults) == 0 || collector.bottomCursor == "" || collector.bottomCursor == cursor {
+			break
+		}
+		cursor = collector.bottomCursor
+	}
+
+	return tweets, nil
+}
+
+// GetHashtagTweets searches tweets with hashtag, given with or without "#"
+func (c *Client) GetHashtagTweets(tag string, opts SearchOptions) ([]Tweet, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	if !hashtagTagRegex.MatchString(tag) {
+		return nil, fmt.Errorf("invalid hashtag: %q", tag)
+	}
+	return c.SearchTweets("#"+tag, opts)
+}
+
+// searchTweetsPage fetches a single page of tweet search results
+func (c *Client) searchTweetsPage(query, product, cursor string) (*timelineCollector, error) {
+	variables := map[string]any{
+		"rawQuery":    query,
+		"count":       20,
+		"querySource": "typed_query",
+		"product":     product,
+	}
+	if cursor != "" {
+		variables["cursor"] = cursor
+	}
+
+	resp, err := c.makeAPICall(SearchTimelinePath, variables, timelineFeatures(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var searchResp SearchTweetsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	collector := &timelineCollector{render: c.render, logger: c.logger}
+	instructions := searchResp.Data.SearchByRawQuery.SearchTimeline.Timeline.Instructions
+	for i := range instructions {
+		instruction := &instructions[i]
+		for j := range instruction.Entries {
+			collector.addEntry(instruction.Type, &instruction.Entries[j])
+		}
+		if instruction.Entry != nil {
+			collector.addEntry(instruction.Type, instruction.Entry)
+		}
+	}
+
+	return collector, nil
+}
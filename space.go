@@ -0,0 +1,146 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Space is a Twitter/X audio Space
+type Space struct {
+	ID             string             // Space ID
+	Title          string             // Space title
+	State          string             // "NotStarted", "Running", "Ended", "Canceled" or "TimedOut"
+	URL            string             // Link to Space page
+	Creator        *User              // Host, nil if not included in response
+	Admins         []SpaceParticipant // Host and co-hosts
+	Speakers       []SpaceParticipant // Speakers other than hosts
+	Listeners      int                // Live listeners, or total listeners once ended
+	Replays        int                // Replay views
+	Replayable     bool               // Recording is available after the Space ends
+	ScheduledStart time.Time          // Zero if the Space was not scheduled
+	StartedAt      time.Time          // Zero if the Space has not started
+	EndedAt        time.Time          // Zero if the Space has not ended
+}
+
+// SpaceParticipant is a host or speaker of Space
+type SpaceParticipant struct {
+	UserID   string
+	Username string
+	Name     string
+}
+
+// spaceParticipantResponse represents participant in AudioSpaceById response
+type spaceParticipantResponse struct {
+	TwitterScreenName string `json:"twitter_screen_name"`
+	DisplayName       string `json:"display_name"`
+	UserResults       struct {
+		RestID string `json:"rest_id"`
+	} `json:"user_results"`
+}
+
+// AudioSpaceResponse represents response of AudioSpaceById query
+type AudioSpaceResponse struct {
+	Data struct {
+		AudioSpace struct {
+			Metadata *struct {
+				RestID                    string `json:"rest_id"`
+				State                     string `json:"state"`
+				Title                     string `json:"title"`
+				ScheduledStart            int64  `json:"scheduled_start"`
+				StartedAt                 int64  `json:"started_at"`
+				EndedAt                   string `json:"ended_at"`
+				TotalLiveListeners        int    `json:"total_live_listeners"`
+				TotalReplayWatched        int    `json:"total_replay_watched"`
+				IsSpaceAvailableForReplay bool   `json:"is_space_available_for_replay"`
+				CreatorResults            struct {
+					Result *UserResult `json:"result"`
+				} `json:"creator_results"`
+			} `json:"metadata"`
+			Participants struct {
+				Total    int                        `json:"total"`
+				Admins   []spaceParticipantResponse `json:"admins"`
+				Speakers []spaceParticipantResponse `json:"speakers"`
+			} `json:"participants"`
+		} `json:"audioSpace"`
+	} `json:"data"`
+}
+
+// GetSpace gets audio Space details by Space ID, e.g. "1YqKDqWqdPLGV" from https://x.com/i/spaces/1YqKDqWqdPLGV
+func (c *Client) GetSpace(spaceID string) (*Space, error) {
+	variables := map[string]any{
+		"id":              spaceID,
+		"isMetatagsQuery": false,
+		"withReplays":     true,
+		"withListeners":   true,
+	}
+
+	resp, err := c.makeAPICall(AudioSpaceByIDPath, variables, spaceFeatures(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var spaceResp AudioSpaceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&spaceResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	audioSpace := &spaceResp.Data.AudioSpace
+	metadata := audioSpace.Metadata
+	if metadata == nil || metadata.RestID == "" {
+		return nil, &NotFoundError{Kind: "space", ID: spaceID}
+	}
+
+	space := &Space{
+		ID:             metadata.RestID,
+		Title:          metadata.Title,
+		State:          metadata.State,
+		URL:            "https://x.com/i/spaces/" + metadata.RestID,
+		Admins:         convertSpaceParticipants(audioSpace.Participants.Admins),
+		Speakers:       convertSpaceParticipants(audioSpace.Participants.Speakers),
+		Listeners:      metadata.TotalLiveListeners,
+		Replays:        metadata.TotalReplayWatched,
+		Replayable:     metadata.IsSpaceAvailableForReplay,
+		ScheduledStart: unixMilli(metadata.ScheduledStart),
+		StartedAt:      unixMilli(metadata.StartedAt),
+	}
+	if endedAt, err := strconv.ParseInt(metadata.EndedAt, 10, 64); err == nil {
+		space.EndedAt = unixMilli(endedAt)
+	}
+	if result := metadata.CreatorResults.Result; result != nil && result.RestID != "" {
+		space.Creator = convertUserResult(result)
+	}
+
+	return space, nil
+}
+
+// convertSpaceParticipants converts participants of AudioSpaceById response
+func convertSpaceParticipants(participants []spaceParticipantResponse) []SpaceParticipant {
+	var converted []SpaceParticipant
+	for _, p := range participants {
+		converted = append(converted, SpaceParticipant{
+			UserID:   p.UserResults.RestID,
+			Username: p.TwitterScreenName,
+			Name:     p.DisplayName,
+		})
+	}
+	return converted
+}
+
+// unixMilli converts Unix time in milliseconds to time, zero time for zero
+func unixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// spaceFeatures returns features of AudioSpaceById query
+func spaceFeatures() map[string]any {
+	features := timelineFeatures()
+	features["spaces_2022_h2_clipping"] = true
+	features["spaces_2022_h2_spaces_communities"] = true
+	return features
+}
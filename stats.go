@@ -0,0 +1,118 @@
+package twittertimeline
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EndpointStats are counters of requests to an endpoint since the client was created
+type EndpointStats struct {
+	Calls          int64         // Requests sent, including retries and hedged copies
+	Errors         int64         // Failed requests and responses with status 400 or higher
+	Retries        int64         // Retries after transient failures, learned features or solved challenges
+	Bytes          int64         // Response body bytes read
+	AverageLatency time.Duration // Average time to response headers
+}
+
+// endpointCounters accumulates EndpointStats
+type endpointCounters struct {
+	calls   atomic.Int64
+	errors  atomic.Int64
+	retries atomic.Int64
+	bytes   atomic.Int64
+	latency atomic.Int64 // Total nanoseconds
+}
+
+// clientStats holds counters by endpoint name
+type clientStats struct {
+	endpoints sync.Map // Endpoint name -> *endpointCounters
+}
+
+// counters returns counters of endpoint, creating them if needed
+func (s *clientStats) counters(endpoint string) *endpointCounters {
+	if counters, ok := s.endpoints.Load(endpoint); ok {
+		return counters.(*endpointCounters)
+	}
+	counters, _ := s.endpoints.LoadOrStore(endpoint, &endpointCounters{})
+	return counters.(*endpointCounters)
+}
+
+// Stats returns request counters by endpoint: GraphQL operation name (e.g. "UserTweets"),
+// path of other API requests (e.g. "/1.1/guest/activate.json") or host of other requests (e.g. "pbs.twimg.com")
+func (c *Client) Stats() map[string]EndpointStats {
+	stats := make(map[string]EndpointStats)
+	c.stats.endpoints.Range(func(key, value any) bool {
+		counters := value.(*endpointCounters)
+		s := EndpointStats{
+			Calls:   counters.calls.Load(),
+			Errors:  counters.errors.Load(),
+			Retries: counters.retries.Load(),
+			Bytes:   counters.bytes.Load(),
+		}
+		if s.Calls > 0 {
+			s.AverageLatency = time.Duration(counters.latency.Load() / s.Calls)
+		}
+		stats[key.(string)] = s
+		return true
+	})
+	return stats
+}
+
+// countRetry counts retry of GraphQL API call
+func (c *Client) countRetry(endpoint string) {
+	c.stats.counters(path.Base(endpoint)).retries.Add(1)
+}
+
+// endpointName returns name of request endpoint in Stats
+func endpointName(u *url.URL) string {
+	switch {
+	case strings.HasPrefix(u.Path, "/graphql/"):
+		return path.Base(u.Path)
+	case u.Host == apiHost() || u.Host == HedgeHost:
+		return u.Path
+	default:
+		return u.Host
+	}
+}
+
+// statsTransport counts requests made through next transport
+type statsTransport struct {
+	next  http.RoundTripper
+	stats *clientStats
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	counters := t.stats.counters(endpointName(req.URL))
+	counters.calls.Add(1)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	counters.latency.Add(int64(time.Since(start)))
+	if err != nil {
+		counters.errors.Add(1)
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		counters.errors.Add(1)
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, bytes: &counters.bytes}
+	return resp, nil
+}
+
+// countingBody counts bytes read from response body
+type countingBody struct {
+	io.ReadCloser
+	bytes *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes.Add(int64(n))
+	return n, err
+}
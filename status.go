@@ -0,0 +1,121 @@
+package twittertimeline
+
+import (
+	"errors"
+	"strings"
+)
+
+// Status is availability of a tweet or account, see CheckTweets and CheckUser
+type Status string
+
+// Availability statuses
+const (
+	StatusUnknown     Status = "unknown"     // Check failed, e.g. due to rate limit
+	StatusExists      Status = "exists"      // Tweet is available
+	StatusNotFound    Status = "not_found"   // No tweet with the ID or account with the username
+	StatusDeleted     Status = "deleted"     // Tweet or its author's account was deleted
+	StatusWithheld    Status = "withheld"    // Tweet is withheld in some countries or due to copyright claim
+	StatusProtected   Status = "protected"   // Tweet is from protected account
+	StatusSuspended   Status = "suspended"   // Account or tweet author's account is suspended
+	StatusUnavailable Status = "unavailable" // Tweet is hidden for another reason
+
+	StatusActive      Status = "active"      // Account is available
+	StatusDeactivated Status = "deactivated" // Account with known ID no longer exists
+	StatusRenamed     Status = "renamed"     // Account with known ID changed its username
+)
+
+// CheckTweets reports availability of tweets by IDs, e.g. for link rot auditing.
+// IDs which could not be checked get StatusUnknown.
+func (c *Client) CheckTweets(ids []string) map[string]Status {
+	tweets, errs := c.GetTweets(ids)
+
+	statuses := make(map[string]Status, len(tweets)+len(errs))
+	for id, tweet := range tweets {
+		statuses[id] = StatusExists
+		if r := tweet.Restrictions; r != nil && (r.WithheldCopyright || len(r.WithheldInCountries) > 0) {
+			statuses[id] = StatusWithheld
+		}
+	}
+	for id, err := range errs {
+		statuses[id] = tweetErrorStatus(err)
+	}
+	return statuses
+}
+
+// tweetErrorStatus classifies error of GetTweets by tombstone text or unavailability reason
+func tweetErrorStatus(err error) Status {
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return StatusUnknown
+	}
+
+	reason := strings.ToLower(notFoundErr.Reason)
+	switch {
+	case reason == "":
+		return StatusNotFound
+	case strings.Contains(reason, "deleted") || strings.Contains(reason, "no longer exists"):
+		return StatusDeleted
+	case strings.Contains(reason, "suspended"):
+		return StatusSuspended
+	case strings.Contains(reason, "withheld"):
+		return StatusWithheld
+	case strings.Contains(reason, "protected") || strings.Contains(reason, "limits who can view"):
+		return StatusProtected
+	default:
+		return StatusUnavailable
+	}
+}
+
+// CheckUser reports availability of account with username, e.g. to validate stored account lists:
+// StatusActive, StatusSuspended, StatusDeactivated, StatusRenamed or StatusNotFound.
+// Renamed and deactivated accounts are told apart from never existing ones only with a warm
+// user ID cache, i.e. when the client has resolved the username within cache TTL (see GetUserID).
+// Otherwise they are reported as StatusNotFound; use CheckUserByID with stored user IDs instead.
+func (c *Client) CheckUser(username string) (Status, error) {
+	var userID string
+	if value, ok := userIDCache.Load(strings.ToLower(strings.TrimPrefix(username, "@"))); ok {
+		userID = value.(*userIDCacheEntry).UserID
+	}
+	status, _, err := c.CheckUserByID(username, userID)
+	return status, err
+}
+
+// CheckUserByID reports availability of account with username like CheckUser, using known user ID
+// (may be empty) to detect renamed and deactivated accounts. It also returns the current username,
+// the new one for StatusRenamed, or empty string if the account is unavailable.
+func (c *Client) CheckUserByID(username, userID string) (Status, string, error) {
+	username = strings.TrimPrefix(username, "@")
+
+	var notFoundErr *NotFoundError
+	userResp, err := c.GetUserByScreenName(username)
+	switch {
+	case err == nil:
+		result := &userResp.Data.User.Result
+		// Username taken by another account means the known one was renamed
+		if userID == "" || result.RestID == userID {
+			return StatusActive, firstNonEmpty(result.Core.ScreenName, result.Legacy.ScreenName, username), nil
+		}
+	case !errors.As(err, &notFoundErr):
+		return StatusUnknown, "", err
+	case strings.EqualFold(notFoundErr.Reason, "Suspended"):
+		return StatusSuspended, "", nil
+	case userID == "":
+		return StatusNotFound, "", nil
+	}
+
+	// Username is free or taken by another account, so look up the known account by ID
+	user, err := c.GetUserByID(userID)
+	switch {
+	case err == nil:
+		if strings.EqualFold(user.Username, username) {
+			return StatusActive, user.Username, nil
+		}
+		return StatusRenamed, user.Username, nil
+	case !errors.As(err, &notFoundErr):
+		return StatusUnknown, "", err
+	case strings.EqualFold(notFoundErr.Reason, "Suspended"):
+		return StatusSuspended, "", nil
+	default:
+		return StatusDeactivated, "", nil
+	}
+}
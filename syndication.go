@@ -0,0 +1,244 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants for public syndication API
+const (
+	SyndicationURL    = "https://syndication.twitter.com"
+	SyndicationCDNURL = "https://cdn.syndication.twimg.com"
+
+	// Syndication endpoints
+	SyndicationTimelinePath = "/srv/timeline-profile/user-id/"
+	SyndicationTweetPath    = "/tweet-result"
+)
+
+// nextDataRegex extracts embedded Next.js data from syndication timeline page
+var nextDataRegex = regexp.MustCompile(`(?s)<script id="__NEXT_DATA__" type="application/json">(.*?)</script>`)
+
+// v1Tweet contains fields of v1.1-style tweet that differ from GraphQL legacy structure
+type v1Tweet struct {
+	Typename  string `json:"__typename"`
+	IDStr     string `json:"id_str"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+	User      struct {
+		IDStr      string `json:"id_str"`
+		ScreenName string `json:"screen_name"`
+	} `json:"user"`
+	ConversationCount int             `json:"conversation_count"`
+	MediaDetails      []MediaEntity   `json:"mediaDetails"`
+	QuotedTweet       json.RawMessage `json:"quoted_tweet"`
+	QuotedStatus      json.RawMessage `json:"quoted_status"`
+	RetweetedStatus   json.RawMessage `json:"retweeted_status"`
+}
+
+// SyndicationTimelineResponse represents data embedded into syndication timeline page
+type SyndicationTimelineResponse struct {
+	Props struct {
+		PageProps struct {
+			Timeline struct {
+				Entries []struct {
+					Type    string `json:"type"`
+					EntryID string `json:"entry_id"`
+					Content struct {
+						Tweet json.RawMessage `json:"tweet"`
+					} `json:"content"`
+				} `json:"entries"`
+			} `json:"timeline"`
+		} `json:"pageProps"`
+	} `json:"props"`
+}
+
+// GetSyndicationTweet gets a single tweet by ID from the public syndication API
+func (c *Client) GetSyndicationTweet(tweetID string) (*Tweet, error) {
+	tweetResult, err := c.getSyndicationTweetResult(tweetID)
+	if err != nil {
+		return nil, err
+	}
+	if tweetResult.RestID == "" {
+		return nil, &NotFoundError{Kind: "tweet", ID: tweetID}
+	}
+
+	tweet := convertTweetResult(tweetResult)
+	c.runTweetHooks(&tweet)
+	return &tweet, nil
+}
+
+// getSyndicationTweetResult gets a single processed tweet result by ID from the public syndication API.
+// Deleted or hidden tweets are returned as tombstones without RestID.
+func (c *Client) getSyndicationTweetResult(tweetID string) (*TweetResult, error) {
+	params := url.Values{}
+	params.Add("id", tweetID)
+	params.Add("lang", "en")
+	params.Add("token", syndicationToken(tweetID))
+
+	var tweetResult *TweetResult
+	err := c.withBody(SyndicationCDNURL+SyndicationTweetPath+"?"+params.Encode(), func(body []byte) (err error) {
+		tweetResult, err = parseV1Tweet(body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	processTweetResult(tweetResult, c.render)
+	return tweetResult, nil
+}
+
+// getSyndicationUserTweets gets user timeline from the public syndication API
+func (c *Client) getSyndicationUserTweets(userID string) ([]Tweet, error) {
+	var timelineResp SyndicationTimelineResponse
+	err := c.withBody(SyndicationURL+SyndicationTimelinePath+url.PathEscape(userID), func(body []byte) error {
+		match := nextDataRegex.FindSubmatch(body)
+		if match == nil {
+			return fmt.Errorf("timeline data not found in syndication response")
+		}
+		if err := json.Unmarshal(match[1], &timelineResp); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tweets []Tweet
+	for _, entry := range timelineResp.Props.PageProps.Timeline.Entries {
+		if entry.Type != "tweet" || len(entry.Content.Tweet) == 0 {
+			continue
+		}
+		tweetResult, err := parseV1Tweet(entry.Content.Tweet)
+		if err != nil {
+			return nil, err
+		}
+		processTweetResult(tweetResult, c.render)
+		if tweetResult.Legacy.FullText != "" {
+			tweets = append(tweets, convertTweetResult(tweetResult))
+		}
+	}
+
+	return tweets, nil
+}
+
+// withBody makes a GET request to a non-GraphQL endpoint and passes response body to fn.
+// The body is read into a pooled buffer and is valid only until fn returns.
+func (c *Client) withBody(apiURL string, fn func(body []byte) error) error {
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Accept-Language", "en")
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode, Body: buf.String()}
+	}
+
+	return fn(buf.Bytes())
+}
+
+// parseV1Tweet maps v1.1-style tweet JSON (syndication and legacy REST API) into TweetResult structure
+func parseV1Tweet(data []byte) (*TweetResult, error) {
+	var tweet v1Tweet
+	if err := json.Unmarshal(data, &tweet); err != nil {
+		return nil, fmt.Errorf("error decoding tweet: %w", err)
+	}
+
+	// Syndication tweets share v1.1 field names with GraphQL legacy object
+	tweetResult := &TweetResult{Typename: tweet.Typename, RestID: tweet.IDStr}
+	if tweet.Typename == "TweetTombstone" {
+		if err := json.Unmarshal(data, tweetResult); err != nil {
+			return nil, fmt.Errorf("error decoding tweet: %w", err)
+		}
+		return tweetResult, nil
+	}
+	if err := json.Unmarshal(data, &tweetResult.Legacy); err != nil {
+		return nil, fmt.Errorf("error decoding tweet: %w", err)
+	}
+
+	tweetResult.Core.UserResults.Result.Core.ScreenName = tweet.User.ScreenName
+	tweetResult.Legacy.UserIDStr = tweet.User.IDStr
+	if tweetResult.Legacy.FullText == "" {
+		tweetResult.Legacy.FullText = tweet.Text
+	}
+
+	// tweet-result endpoint uses ISO timestamps
+	if createdAt, err := time.Parse(time.RFC3339, tweet.CreatedAt); err == nil {
+		tweetResult.Legacy.CreatedAt = createdAt.Format(time.RubyDate)
+	}
+	if tweetResult.Legacy.ReplyCount == 0 {
+		tweetResult.Legacy.ReplyCount = tweet.ConversationCount
+	}
+	if len(tweetResult.Legacy.ExtendedEntities.Media) == 0 {
+		tweetResult.Legacy.ExtendedEntities.Media = tweet.MediaDetails
+	}
+	// Syndication API names quoted tweet differently from v1.1 REST API
+	quotedTweet := tweet.QuotedTweet
+	if len(quotedTweet) == 0 || string(quotedTweet) == "null" {
+		quotedTweet = tweet.QuotedStatus
+	}
+	if len(quotedTweet) > 0 && string(quotedTweet) != "null" {
+		tweetResult.Legacy.IsQuoteStatus = true
+		quoted, err := parseV1Tweet(quotedTweet)
+		if err != nil {
+			return nil, err
+		}
+		tweetResult.QuotedStatusResult.Result = quoted
+	}
+
+	if len(tweet.RetweetedStatus) > 0 && string(tweet.RetweetedStatus) != "null" {
+		retweeted, err := parseV1Tweet(tweet.RetweetedStatus)
+		if err != nil {
+			return nil, err
+		}
+		tweetResult.RetweetedStatusResult.Result = retweeted
+	}
+
+	return tweetResult, nil
+}
+
+// syndicationToken computes token required by tweet-result endpoint.
+// It reproduces the embed script: base-36 representation of id / 1e15 * Pi without zeros and dot.
+func syndicationToken(tweetID string) string {
+	id, err := strconv.ParseFloat(tweetID, 64)
+	if err != nil {
+		return ""
+	}
+	value := id / 1e15 * math.Pi
+
+	intPart, fracPart := math.Modf(value)
+	token := strconv.FormatInt(int64(intPart), 36)
+	for i := 0; i < 12 && fracPart > 0; i++ {
+		fracPart *= 36
+		digit, rest := math.Modf(fracPart)
+		token += strconv.FormatInt(int64(digit), 36)
+		fracPart = rest
+	}
+
+	return strings.ReplaceAll(token, "0", "")
+}
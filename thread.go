@@ -0,0 +1,52 @@
+package twittertimeline
+
+// BuildThreads groups self-reply chains (threads) of tweets. Each thread is ordered oldest first,
+// threads follow the order in which their first tweets appear in tweets.
+// Tweets that are not part of a self-reply chain are returned as single-tweet threads.
+// Replies whose parent is missing from tweets start a new thread.
+func BuildThreads(tweets []Tweet) [][]Tweet {
+	index := make(map[string]int, len(tweets))
+	for i, tweet := range tweets {
+		if _, ok := index[tweet.ID]; !ok {
+			index[tweet.ID] = i
+		}
+	}
+
+	// root walks up the self-reply chain to the earliest available tweet
+	root := func(i int) int {
+		for steps := 0; steps < len(tweets); steps++ {
+			tweet := &tweets[i]
+			if tweet.InReplyToID == "" || tweet.InReplyToUserID != tweet.UserID {
+				break
+			}
+			parent, ok := index[tweet.InReplyToID]
+			if !ok || tweets[parent].UserID != tweet.UserID {
+				break
+			}
+			i = parent
+		}
+		return i
+	}
+
+	var threads [][]Tweet
+	threadIndex := make(map[int]int)
+	for i, tweet := range tweets {
+		if index[tweet.ID] != i {
+			continue // Duplicate
+		}
+		r := root(i)
+		n, ok := threadIndex[r]
+		if !ok {
+			n = len(threads)
+			threadIndex[r] = n
+			threads = append(threads, nil)
+		}
+		threads[n] = append(threads[n], tweet)
+	}
+
+	for _, thread := range threads {
+		SortTweetsOldestFirst(thread)
+	}
+
+	return threads
+}
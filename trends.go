@@ -0,0 +1,251 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WorldwideWOEID is Yahoo! Where On Earth ID of worldwide trends
+const WorldwideWOEID = 1
+
+// ExploreTrendingTimelineID is ID of "Trending" tab of Explore page for GenericTimelineById query
+const ExploreTrendingTimelineID = "VGltZWxpbmU6DAC2CwABAAAACHRyZW5kaW5nAAA="
+
+// tweetVolumeRegex matches tweet volume in trend description, e.g. "12.3K posts" or "1,234 Tweets"
+var tweetVolumeRegex = regexp.MustCompile(`(?i)([\d.,]+)\s*([KM]?)\s+(?:posts|tweets)`)
+
+// TrendSource is the origin of trends, telling whether they belong to the requested location
+type TrendSource string
+
+// Trend sources
+const (
+	TrendSourcePlace   TrendSource = "place"   // Trends of the requested location from v1.1 API
+	TrendSourceExplore TrendSource = "explore" // Trends of Explore page for location chosen by X
+)
+
+// Trend is a trending topic
+type Trend struct {
+	Name        string      // Trend name, e.g. "#GoLang"
+	Query       string      // Search query of the trend
+	URL         string      // Link to search results
+	TweetVolume int         // Tweets in the last 24 hours, 0 if unknown
+	Source      TrendSource // Origin of the trend
+	Context     string      // Scope of Explore trend, e.g. "Trending in United Kingdom"
+}
+
+// TrendsResponse represents response of v1.1 trends/place endpoint
+type TrendsResponse []struct {
+	Trends []struct {
+		Name        string `json:"name"`
+		URL         string `json:"url"`
+		Query       string `json:"query"`
+		TweetVolume *int   `json:"tweet_volume"`
+	} `json:"trends"`
+}
+
+// ExploreTimelineResponse represents response of GraphQL explore timeline with trends
+type ExploreTimelineResponse struct {
+	Data struct {
+		Timeline struct {
+			Timeline struct {
+				Instructions []struct {
+					Type    string `json:"type"`
+					Entries []struct {
+						EntryID string `json:"entryId"`
+						Content struct {
+							EntryType   string            `json:"entryType"`
+							ItemContent *ExploreTrendItem `json:"itemContent"`
+							Items       []struct {
+								Item struct {
+									ItemContent *ExploreTrendItem `json:"itemContent"`
+								} `json:"item"`
+							} `json:"items"`
+						} `json:"content"`
+					} `json:"entries"`
+				} `json:"instructions"`
+			} `json:"timeline"`
+		} `json:"timeline"`
+	} `json:"data"`
+}
+
+// ExploreTrendItem represents trend item of explore timeline
+type ExploreTrendItem struct {
+	Typename string `json:"__typename"`
+	Name     string `json:"name"`
+	TrendURL struct {
+		URL string `json:"url"`
+	} `json:"trend_url"`
+	TrendMetadata struct {
+		DomainContext   string `json:"domain_context"`
+		MetaDescription string `json:"meta_description"`
+	} `json:"trend_metadata"`
+}
+
+// TrendLocation is a location with available trends
+type TrendLocation struct {
+	Name    string `json:"name"`
+	Country string `json:"country"`
+	WOEID   int    `json:"woeid"`
+}
+
+// GetTrends gets trending topics of location with the given WOEID, see WorldwideWOEID.
+// When access to v1.1 trends is denied, e.g. for guest token, trends of Explore page are returned
+// instead, which are for location chosen by X and have TrendSourceExplore source.
+func (c *Client) GetTrends(woeid int) ([]Trend, error) {
+	trends, err := c.getPlaceTrends(woeid)
+	if err != nil {
+		return c.exploreFallback(err)
+	}
+	return trends, nil
+}
+
+// exploreFallback returns trends of Explore page if access to v1.1 trends is denied
+func (c *Client) exploreFallback(legacyErr error) ([]Trend, error) {
+	if !IsAccessBlocked(legacyErr) {
+		return nil, legacyErr
+	}
+	trends, err := c.GetExploreTrends()
+	if err != nil {
+		return nil, errors.Join(legacyErr, err)
+	}
+	return trends, nil
+}
+
+// getPlaceTrends gets trending topics of location from v1.1 trends/place endpoint
+func (c *Client) getPlaceTrends(woeid int) ([]Trend, error) {
+	params := url.Values{}
+	params.Add("id", strconv.Itoa(woeid))
+
+	resp, err := c.makeLegacyAPICall(LegacyTrendsPlacePath, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var trendsResp TrendsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&trendsResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(trendsResp) == 0 {
+		return nil, &NotFoundError{Kind: "trends", ID: strconv.Itoa(woeid)}
+	}
+
+	trends := make([]Trend, 0, len(trendsResp[0].Trends))
+	for _, t := range trendsResp[0].Trends {
+		trend := Trend{Name: t.Name, URL: t.URL, Source: TrendSourcePlace}
+		trend.Query, _ = url.QueryUnescape(t.Query)
+		if t.TweetVolume != nil {
+			trend.TweetVolume = *t.TweetVolume
+		}
+		trends = append(trends, trend)
+	}
+	return trends, nil
+}
+
+// GetTrendsByPlace gets trending topics of a city or country by name ("London", "Japan"),
+// "Worldwide" or numeric WOEID. It falls back to Explore page trends like GetTrends
+// when access to v1.1 trend locations is denied.
+func (c *Client) GetTrendsByPlace(place string) ([]Trend, error) {
+	place = strings.TrimSpace(place)
+	if woeid, err := strconv.Atoi(place); err == nil {
+		return c.GetTrends(woeid)
+	}
+
+	locations, err := c.GetTrendLocations()
+	if err != nil {
+		return c.exploreFallback(err)
+	}
+	for _, location := range locations {
+		if strings.EqualFold(location.Name, place) {
+			return c.GetTrends(location.WOEID)
+		}
+	}
+	return nil, &NotFoundError{Kind: "trends location", ID: place}
+}
+
+// GetTrendLocations gets locations with available trends
+func (c *Client) GetTrendLocations() ([]TrendLocation, error) {
+	resp, err := c.makeLegacyAPICall(LegacyTrendsAvailable, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var locations []TrendLocation
+	if err := json.NewDecoder(resp.Body).Decode(&locations); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	return locations, nil
+}
+
+// GetExploreTrends gets trending topics of "Trending" tab of Explore page, available for guest token.
+// X chooses location of the trends itself, e.g. by client IP address.
+func (c *Client) GetExploreTrends() ([]Trend, error) {
+	variables := map[string]any{
+		"timelineId":                             ExploreTrendingTimelineID,
+		"count":                                  20,
+		"withQuickPromoteEligibilityTweetFields": true,
+	}
+
+	resp, err := c.makeAPICall(GenericTimelineByIDPath, variables, timelineFeatures(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var exploreResp ExploreTimelineResponse
+	if err := json.NewDecoder(resp.Body).Decode(&exploreResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	var trends []Trend
+	for _, instruction := range exploreResp.Data.Timeline.Timeline.Instructions {
+		for _, entry := range instruction.Entries {
+			items := []*ExploreTrendItem{entry.Content.ItemContent}
+			for _, moduleItem := range entry.Content.Items {
+				items = append(items, moduleItem.Item.ItemContent)
+			}
+			for _, item := range items {
+				if item != nil && item.Typename == "TimelineTrend" && item.Name != "" {
+					trends = append(trends, convertExploreTrend(item))
+				}
+			}
+		}
+	}
+	if len(trends) == 0 {
+		return nil, &NotFoundError{Kind: "trends", ID: "explore"}
+	}
+	return trends, nil
+}
+
+// convertExploreTrend converts trend item of explore timeline into Trend
+func convertExploreTrend(item *ExploreTrendItem) Trend {
+	trend := Trend{
+		Name:    item.Name,
+		Query:   item.Name,
+		Source:  TrendSourceExplore,
+		Context: item.TrendMetadata.DomainContext,
+	}
+	if trendURL, err := url.Parse(item.TrendURL.URL); err == nil && trendURL.Query().Get("query") != "" {
+		trend.Query = trendURL.Query().Get("query")
+	}
+	trend.URL = "https://x.com/search?q=" + url.QueryEscape(trend.Query)
+
+	if match := tweetVolumeRegex.FindStringSubmatch(item.TrendMetadata.MetaDescription); match != nil {
+		volume, _ := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+		switch strings.ToUpper(match[2]) {
+		case "K":
+			volume *= 1e3
+		case "M":
+			volume *= 1e6
+		}
+		trend.TweetVolume = int(math.Round(volume))
+	}
+	return trend
+}
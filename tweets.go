@@ -0,0 +1,159 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxTweetsPerRequest is the number of tweet IDs hydrated by a single request of GetTweets
+const MaxTweetsPerRequest = 20
+
+// TweetResultsResponse represents response of TweetResultsByRestIds query.
+// Results are in order of requested IDs.
+type TweetResultsResponse struct {
+	Data struct {
+		TweetResult []struct {
+			Result *TweetResult `json:"result"`
+		} `json:"tweetResult"`
+	} `json:"data"`
+}
+
+// GetTweets gets tweets by IDs, up to MaxTweetsPerRequest per request, e.g. to refresh metrics of stored tweets.
+// It returns tweets and errors keyed by tweet ID; every ID is present in exactly one of the maps.
+// Deleted, withheld and otherwise unavailable tweets are reported as NotFoundError with the reason given by API.
+func (c *Client) GetTweets(ids []string) (map[string]*Tweet, map[string]error) {
+	tweets := make(map[string]*Tweet)
+	errs := make(map[string]error)
+
+	seen := make(map[string]struct{})
+	var unique []string
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
+	}
+
+	fetched := make(map[string]*TweetResult)
+	for start := 0; start < len(unique); start += MaxTweetsPerRequest {
+		batch := unique[start:min(start+MaxTweetsPerRequest, len(unique))]
+		results, err := c.getTweetResults(batch)
+		if err != nil {
+			for _, id := range batch {
+				errs[id] = err
+			}
+			continue
+		}
+
+		for i, id := range batch {
+			var tweetResult *TweetResult
+			if i < len(results) {
+				tweetResult = results[i]
+			}
+			if tweetResult == nil {
+				errs[id] = &NotFoundError{Kind: "tweet", ID: id}
+				continue
+			}
+			if tweetResult.Legacy.FullText == "" {
+				notFoundErr := &NotFoundError{Kind: "tweet", ID: id, Reason: tweetResult.Reason}
+				if tweetResult.Tombstone != nil {
+					notFoundErr.Reason = tweetResult.Tombstone.Text.Text
+				}
+				errs[id] = notFoundErr
+				continue
+			}
+			c.resolveQuotedTweets(tweetResult, c.quoteDepth, fetched)
+			tweet := convertTweetResult(tweetResult)
+			c.runTweetHooks(&tweet)
+			tweets[id] = &tweet
+		}
+	}
+
+	return tweets, errs
+}
+
+// getTweetResults requests processed tweet results of IDs in order of IDs, nil for missing tweets
+func (c *Client) getTweetResults(ids []string) ([]*TweetResult, error) {
+	variables := map[string]any{
+		"tweetIds":               ids,
+		"includePromotedContent": false,
+		"withCommunity":          true,
+		"withVoice":              true,
+	}
+
+	fieldToggles := map[string]any{
+		"withArticlePlainText": false,
+	}
+
+	resp, err := c.makeAPICall(TweetResultsByRestIDsPath, variables, timelineFeatures(), fieldToggles)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tweetsResp TweetResultsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tweetsResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	results := make([]*TweetResult, len(tweetsResp.Data.TweetResult))
+	for i, item := range tweetsResp.Data.TweetResult {
+		if item.Result == nil {
+			continue
+		}
+		tweetResult := unwrapTweetResult(item.Result)
+		processTweetResult(tweetResult, c.render)
+		results[i] = tweetResult
+	}
+	return results, nil
+}
+
+// Metrics are engagement counters of a tweet
+type Metrics struct {
+	Likes    int
+	Retweets int
+	Replies  int
+	Views    int // Zero if hidden
+}
+
+// MetricsDelta is the current state of tweet metrics and their change since the stored copy
+type MetricsDelta struct {
+	Current Metrics
+	Change  Metrics // Current minus stored values
+}
+
+// RefreshMetrics re-fetches tweets with GetTweets and reports current metrics and their changes
+// since the given copies, e.g. to track engagement over time.
+// It returns deltas and errors keyed by tweet ID; every ID is present in exactly one of the maps.
+func (c *Client) RefreshMetrics(tweets []Tweet) (map[string]MetricsDelta, map[string]error) {
+	ids := make([]string, 0, len(tweets))
+	for i := range tweets {
+		ids = append(ids, tweets[i].ID)
+	}
+	current, errs := c.GetTweets(ids)
+
+	deltas := make(map[string]MetricsDelta, len(current))
+	for i := range tweets {
+		stored := &tweets[i]
+		tweet, ok := current[stored.ID]
+		if !ok {
+			continue
+		}
+		before, after := tweetMetrics(stored), tweetMetrics(tweet)
+		deltas[stored.ID] = MetricsDelta{
+			Current: after,
+			Change: Metrics{
+				Likes:    after.Likes - before.Likes,
+				Retweets: after.Retweets - before.Retweets,
+				Replies:  after.Replies - before.Replies,
+				Views:    after.Views - before.Views,
+			},
+		}
+	}
+	return deltas, errs
+}
+
+// tweetMetrics returns metrics of tweet
+func tweetMetrics(tweet *Tweet) Metrics {
+	return Metrics{Likes: tweet.Likes, Retweets: tweet.Retweets, Replies: tweet.Replies, Views: tweet.Views}
+}
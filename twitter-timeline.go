@@ -2,14 +2,21 @@
 package twittertimeline
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"html"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,9 +28,34 @@ const (
 	BaseURL     = "https://api.x.com"
 	UserAgent   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36"
 
+	// Number of tweets requested per timeline page
+	DefaultPageSize = 100
+
+	// Politeness delay between paginated requests and its relative jitter, see WithPageDelay
+	DefaultPageDelay  = time.Second
+	DefaultPageJitter = 0.25
+
+	// Connection pool defaults
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultIdleConnTimeout     = 90 * time.Second
+
 	// GraphQL API endpoints
-	UserByScreenNamePath = "/graphql/x3RLKWW1Tl7JgU7YtGxuzw/UserByScreenName"
-	UserTweetsPath       = "/graphql/bbmwRjH_roUoWsvbgAJY9g/UserTweets"
+	UserByScreenNamePath      = "/graphql/x3RLKWW1Tl7JgU7YtGxuzw/UserByScreenName"
+	UserByRestIDPath          = "/graphql/tD8zKvQzwY3kdx5yz6YmOw/UserByRestId"
+	UserTweetsPath            = "/graphql/bbmwRjH_roUoWsvbgAJY9g/UserTweets"
+	SearchTimelinePath        = "/graphql/gkjsKepM6gl_HmFWoWKfgg/SearchTimeline"
+	ListByRestIDPath          = "/graphql/cIUpT1UjuGgl_oWiY7Snhg/ListByRestId"
+	ListBySlugPath            = "/graphql/K6wihoTiTrzNzSF8y1aeKQ/ListBySlug"
+	AudioSpaceByIDPath        = "/graphql/gpc0LEdR6URXZ7HOo42_bQ/AudioSpaceById"
+	TweetResultsByRestIDsPath = "/graphql/BWy5aoI-WvwbeSiHUIf2Hw/TweetResultsByRestIds"
+	GenericTimelineByIDPath   = "/graphql/CsBUAJDEhZpZzKEO_WtMkg/GenericTimelineById"
+)
+
+// Regexes for entities in tweet text
+var (
+	hashtagRegex = regexp.MustCompile(`#([\p{L}\p{M}\p{N}_]+)`)
+	cashtagRegex = regexp.MustCompile(`\$([A-Za-z][A-Za-z0-9_]*(?:\.[A-Za-z]+)?)`)
+	mentionRegex = regexp.MustCompile(`@(\w+)`)
 )
 
 // Public API structures
@@ -32,6 +64,8 @@ type Tweet struct {
 	ID           string // RestID
 	Text         string // FullText
 	HTML         string // HTML version with links
+	Lang         string // Detected language code, e.g. "en", "und" if undetermined
+	DetectedLang string // Language detected locally when Lang is undetermined, see WithLanguageDetection
 	CreatedAt    string // Creation date
 	PermanentURL string // Permanent link to tweet
 
@@ -43,6 +77,7 @@ type Tweet struct {
 	Likes    int // FavoriteCount
 	Retweets int // RetweetCount
 	Replies  int // ReplyCount
+	Views    int // View count, zero if hidden
 
 	// Tweet types (boolean flags as is)
 	IsPinned  bool // Whether tweet is pinned
@@ -50,11 +85,86 @@ type Tweet struct {
 	IsQuoted  bool // Quote
 	IsReply   bool // Reply
 
+	IsExclusive bool // Subscriber-only tweet, Text holds only preview available to non-subscribers
+
+	QuotedTweet *QuotedTweet // Quoted tweet if it is included in response
+
+	Raw json.RawMessage `json:",omitempty"` // Original JSON of tweet result, see WithRawJSON
+
+	// Conversation
+	ConversationID    string // ID of the tweet that started conversation
+	InReplyToID       string // ID of the replied tweet
+	InReplyToUserID   string // User ID of the replied tweet author
+	InReplyToUsername string // Username of the replied tweet author
+
 	// Media and links
 	Images   []string // Image URLs
+	Media    []Media  // Photos, videos, GIFs and voice tweet audio
 	Hashtags []string // Hashtags (text only)
+	Cashtags []string // Cashtags, e.g. ticker symbols (text only)
 	URLs     []URL    // Links
 	Mentions []string // User mentions (username only)
+
+	UserMentions []Mention // User mentions with IDs and names
+
+	Entities []Entity // Entities with their positions in text, ordered by position
+
+	Community *Community // Community the tweet was posted in, nil for regular tweets
+
+	Restrictions *Restrictions // Limits of reach or availability, nil for unrestricted tweets
+}
+
+// Restrictions describes limited visibility or availability of tweet
+type Restrictions struct {
+	LimitedActions       []string // Actions limited by policy, e.g. "Reply" or "Retweet"
+	LimitedActionsReason string   // Explanation shown with limited actions
+	Interstitial         string   // Warning shown before tweet content, e.g. for rules violation
+	InterstitialType     string   // Interstitial display type, e.g. "EntireTweet" or "NonCompliant"
+	WithheldInCountries  []string // Codes of countries where tweet is withheld
+	WithheldCopyright    bool     // Tweet is withheld due to copyright claim
+}
+
+// QuotedTweet is a tweet quoted by another tweet
+type QuotedTweet struct {
+	Tweet              // Content, empty if the tweet is unavailable
+	Unavailable bool   // Quoted tweet is deleted, from suspended or protected account or otherwise hidden
+	Reason      string // Explanation of unavailability, e.g. "This Post was deleted by the Post author."
+}
+
+// Community is an X Community
+type Community struct {
+	ID   string // Community ID
+	Name string // Community name
+	URL  string // Link to community page
+}
+
+// EntityType is a kind of entity in tweet text
+type EntityType string
+
+// Entity types
+const (
+	EntityHashtag EntityType = "hashtag"
+	EntityCashtag EntityType = "cashtag"
+	EntityURL     EntityType = "url"
+	EntityMention EntityType = "mention"
+	EntityMedia   EntityType = "media"
+)
+
+// Entity represents a hashtag, cashtag, link, mention or media link in tweet text.
+// Start and End are rune offsets in Text, which is escaped API text by default or unescaped one
+// with WithUnescapedText, End is exclusive.
+type Entity struct {
+	Type  EntityType
+	Value string // Hashtag or cashtag text, expanded URL, username or media URL
+	Start int
+	End   int
+}
+
+// Mention represents a user mentioned in a tweet
+type Mention struct {
+	Username string // Username without @
+	UserID   string // User ID
+	Name     string // Display name
 }
 
 type URL struct {
@@ -71,17 +181,7 @@ type GuestTokenResponse struct {
 type UserResponse struct {
 	Data struct {
 		User struct {
-			Result struct {
-				RestID string `json:"rest_id"`
-				ID     string `json:"id"`
-				Legacy struct {
-					UserInfo `json:"legacy"`
-				} `json:"legacy"`
-				Core struct {
-					Name       string `json:"name"`
-					ScreenName string `json:"screen_name"`
-				} `json:"core"`
-			} `json:"result"`
+			Result UserResult `json:"result"`
 		} `json:"user"`
 	} `json:"data"`
 	Errors []struct {
@@ -90,23 +190,117 @@ type UserResponse struct {
 	} `json:"errors"`
 }
 
+// UserResult is a user object of GraphQL API.
+// Newer responses moved some legacy fields into separate objects, so both are kept.
+type UserResult struct {
+	Typename       string   `json:"__typename"`
+	RestID         string   `json:"rest_id"`
+	ID             string   `json:"id"`
+	Reason         string   `json:"reason"` // Reason of UserUnavailable result, e.g. "Suspended"
+	IsBlueVerified bool     `json:"is_blue_verified"`
+	Legacy         UserInfo `json:"legacy"` // Profile fields and statistics
+	Core           struct {
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+		CreatedAt  string `json:"created_at"`
+	} `json:"core"`
+	Avatar struct {
+		ImageURL string `json:"image_url"`
+	} `json:"avatar"`
+	Location struct {
+		Location string `json:"location"`
+	} `json:"location"`
+	Privacy struct {
+		Protected bool `json:"protected"`
+	} `json:"privacy"`
+	Verification struct {
+		Verified     bool   `json:"verified"`
+		VerifiedType string `json:"verified_type"`
+	} `json:"verification"`
+	AffiliatesHighlightedLabel struct {
+		Label *struct {
+			Description string `json:"description"`
+			URL         struct {
+				URL string `json:"url"`
+			} `json:"url"`
+			Badge struct {
+				URL string `json:"url"`
+			} `json:"badge"`
+			UserLabelType string `json:"userLabelType"`
+		} `json:"label"`
+	} `json:"affiliates_highlighted_label"`
+}
+
 type UserInfo struct {
-	Name           string `json:"name"`
-	ScreenName     string `json:"screen_name"`
-	Description    string `json:"description"`
-	FollowersCount int    `json:"followers_count"`
-	FriendsCount   int    `json:"friends_count"`
-	StatusesCount  int    `json:"statuses_count"`
+	Name                 string `json:"name"`
+	ScreenName           string `json:"screen_name"`
+	Description          string `json:"description"`
+	Location             string `json:"location"`
+	URL                  string `json:"url"`
+	CreatedAt            string `json:"created_at"`
+	ProfileImageURLHTTPS string `json:"profile_image_url_https"`
+	ProfileBannerURL     string `json:"profile_banner_url"`
+	Protected            bool   `json:"protected"`
+	Verified             bool   `json:"verified"`
+	VerifiedType         string `json:"verified_type"`
+	FollowersCount       int    `json:"followers_count"`
+	FriendsCount         int    `json:"friends_count"`
+	StatusesCount        int    `json:"statuses_count"`
+	FavouritesCount      int    `json:"favourites_count"`
+	MediaCount           int    `json:"media_count"`
+	ListedCount          int    `json:"listed_count"`
+	Entities             struct {
+		Description struct {
+			Urls []URLEntity `json:"urls"`
+		} `json:"description"`
+		URL struct {
+			Urls []URLEntity `json:"urls"`
+		} `json:"url"`
+	} `json:"entities"`
+}
+
+// HashtagEntity is a hashtag or cashtag in tweet text.
+// Indices are the begin and end rune offsets of the entity in the API text, where &, < and > are escaped.
+type HashtagEntity struct {
+	Text    string `json:"text"`
+	Indices [2]int `json:"indices"`
+}
+
+// URLEntity is a shortened t.co link in tweet text
+type URLEntity struct {
+	URL         string `json:"url"`
+	ExpandedURL string `json:"expanded_url"`
+	DisplayURL  string `json:"display_url"`
+	Indices     [2]int `json:"indices"`
+}
+
+// MentionEntity is a user mention in tweet text
+type MentionEntity struct {
+	ScreenName string `json:"screen_name"`
+	IDStr      string `json:"id_str"`
+	Name       string `json:"name"`
+	Indices    [2]int `json:"indices"`
 }
 
 type MediaEntity struct {
+	URL           string `json:"url"`
 	MediaURLHTTPS string `json:"media_url_https"`
 	Type          string `json:"type"`
+	Indices       [2]int `json:"indices"`
+	VideoInfo     struct {
+		DurationMillis int `json:"duration_millis"`
+		Variants       []struct {
+			Bitrate     int    `json:"bitrate"`
+			ContentType string `json:"content_type"`
+			URL         string `json:"url"`
+		} `json:"variants"`
+	} `json:"video_info"`
 }
 
 type TweetResult struct {
-	RestID string `json:"rest_id"`
-	Core   struct {
+	Typename string `json:"__typename"`
+	RestID   string `json:"rest_id"`
+	Core     struct {
 		UserResults struct {
 			Result struct {
 				Core struct {
@@ -117,8 +311,10 @@ type TweetResult struct {
 	} `json:"core"`
 	Legacy struct {
 		FullText             string `json:"full_text"`
+		Lang                 string `json:"lang"`
 		CreatedAt            string `json:"created_at"`
 		UserIDStr            string `json:"user_id_str"`
+		ConversationIDStr    string `json:"conversation_id_str"`
 		InReplyToStatusIDStr string `json:"in_reply_to_status_id_str"`
 		InReplyToUserIDStr   string `json:"in_reply_to_user_id_str"`
 		InReplyToScreenName  string `json:"in_reply_to_screen_name"`
@@ -126,26 +322,86 @@ type TweetResult struct {
 		QuotedStatusIDStr    string `json:"quoted_status_id_str"`
 		RetweetedStatusIDStr string `json:"retweeted_status_id_str"`
 		Entities             struct {
-			Hashtags []struct {
-				Text string `json:"text"`
-			} `json:"hashtags"`
-			Urls []struct {
-				URL         string `json:"url"`
-				ExpandedURL string `json:"expanded_url"`
-				DisplayURL  string `json:"display_url"`
-			} `json:"urls"`
-			Media []MediaEntity `json:"media"`
+			Hashtags     []HashtagEntity `json:"hashtags"`
+			Symbols      []HashtagEntity `json:"symbols"`
+			Urls         []URLEntity     `json:"urls"`
+			UserMentions []MentionEntity `json:"user_mentions"`
+			Media        []MediaEntity   `json:"media"`
 		} `json:"entities"`
 		ExtendedEntities struct {
 			Media []MediaEntity `json:"media"`
 		} `json:"extended_entities"`
-		FavoriteCount int `json:"favorite_count"`
-		RetweetCount  int `json:"retweet_count"`
-		ReplyCount    int `json:"reply_count"`
+		FavoriteCount       int      `json:"favorite_count"`
+		RetweetCount        int      `json:"retweet_count"`
+		ReplyCount          int      `json:"reply_count"`
+		WithheldInCountries []string `json:"withheld_in_countries"`
+		WithheldCopyright   bool     `json:"withheld_copyright"`
 	} `json:"legacy"`
+	Views struct {
+		Count string `json:"count"`
+	} `json:"views"`
 	RetweetedStatusResult struct {
 		Result *TweetResult `json:"result"`
 	} `json:"retweeted_status_result"`
+	QuotedStatusResult struct {
+		Result *TweetResult `json:"result"`
+	} `json:"quoted_status_result"`
+	// TweetTombstone and TweetUnavailable describe why the tweet can't be shown
+	Tombstone *struct {
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	} `json:"tombstone"`
+	Reason string `json:"reason"`
+	// TweetWithVisibilityResults wraps the tweet and describes its restrictions
+	Tweet                *TweetResult `json:"tweet"`
+	LimitedActionResults struct {
+		LimitedActions []struct {
+			Action string `json:"action"`
+			Prompt struct {
+				Headline struct {
+					Text string `json:"text"`
+				} `json:"headline"`
+				Subtext struct {
+					Text string `json:"text"`
+				} `json:"subtext"`
+			} `json:"prompt"`
+		} `json:"limited_actions"`
+	} `json:"limitedActionResults"`
+	TweetInterstitial *struct {
+		DisplayType string `json:"displayType"`
+		Text        struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	} `json:"tweetInterstitial"`
+	// Subscriber-only (Super Follows) tweet markers
+	ExclusiveTweetInfo *struct {
+		CreatorID string `json:"creator_id"`
+	} `json:"exclusive_tweet_info"`
+	ExclusivityInfo struct {
+		Exclusive bool `json:"exclusive"`
+	} `json:"exclusivityInfo"`
+	// Card attached to tweet, e.g. audio player of voice tweet
+	Card struct {
+		Legacy struct {
+			Name          string `json:"name"`
+			BindingValues []struct {
+				Key   string `json:"key"`
+				Value struct {
+					StringValue string `json:"string_value"`
+					ImageValue  struct {
+						URL string `json:"url"`
+					} `json:"image_value"`
+				} `json:"value"`
+			} `json:"binding_values"`
+		} `json:"legacy"`
+	} `json:"card"`
+	CommunityResults struct {
+		Result *struct {
+			IDStr string `json:"id_str"`
+			Name  string `json:"name"`
+		} `json:"result"`
+	} `json:"community_results"`
 	IsPinned  bool     `json:"-"` // Not from JSON, set by code
 	IsRetweet bool     `json:"-"` // Not from JSON, determined by code
 	IsQuoted  bool     `json:"-"` // Not from JSON, determined by code
@@ -153,12 +409,18 @@ type TweetResult struct {
 	Images    []string `json:"-"` // Not from JSON, extracted from media
 	URL       string   `json:"-"` // Not from JSON, permanent URL to tweet
 	HTML      string   `json:"-"` // Not from JSON, HTML formatted content
+
+	DetectedLang string `json:"-"` // Not from JSON, language detected from text
+
+	Raw json.RawMessage `json:"-"` // Not from JSON, original JSON of this result if requested
 }
 
 type TimelineEntry struct {
 	EntryID string `json:"entryId"`
 	Content struct {
 		EntryType   string `json:"entryType"`
+		CursorType  string `json:"cursorType"`
+		Value       string `json:"value"`
 		ItemContent *struct {
 			TweetResults struct {
 				Result TweetResult `json:"result"`
@@ -195,6 +457,14 @@ type TimelineResponse struct {
 	} `json:"data"`
 }
 
+// TimelinePage represents a single page of a user timeline
+type TimelinePage struct {
+	UserID       string  // Timeline owner
+	Tweets       []Tweet // Tweets on the page
+	TopCursor    string  // Cursor for newer tweets
+	BottomCursor string  // Cursor for older tweets
+}
+
 // userIDCacheEntry represents a cached user ID entry
 type userIDCacheEntry struct {
 	UserID    string
@@ -204,22 +474,163 @@ type userIDCacheEntry struct {
 // Client represents a client for working with Twitter API
 type Client struct {
 	httpClient  *http.Client
+	transport   http.RoundTripper // Base transport wrapped by middlewares
+	middlewares []Middleware
+	jar         *resettableJar
+	tokenMu     sync.Mutex // Guards guest tokens
 	guestToken  string
 	bearerToken string
 	cacheTTL    time.Duration
+	clock       Clock
+
+	// Hooks invoked during fetching
+	onTweet []func(*Tweet)
+	onPage  []func(TimelinePage)
+
+	// Number of timeline pages converted concurrently with fetching
+	pageParallelism int
+
+	// Delay between paginated requests
+	pageDelay  time.Duration
+	pageJitter float64
+
+	// Cap of concurrent requests
+	limiter *requestLimiter
+
+	// Delay before hedged request to HedgeHost, zero if disabled
+	hedgeDelay time.Duration
+
+	// Cache of API responses, nil if disabled
+	responseCache *responseCache
+
+	// Last timelines of users fetched by GetUserTweetsOrStale
+	staleTimelines sync.Map
+
+	// Request counters by endpoint
+	stats clientStats
+
+	// Dialing of outgoing connections
+	dialer     *net.Dialer
+	customDial DialFunc // Replaces dialer if set
+	preferIPv6 bool
+	resolver   *dohResolver // DoH resolver, nil for system resolver
+
+	// Headers are written in browser order
+	headerOrder bool
+
+	// Retries of transient failures shared by all goroutines
+	retryBudget *retryBudget
+
+	// Rendering of tweet text and HTML
+	render renderOptions
+
+	// Order of returned tweets
+	pinnedPosition PinnedPosition
+	newestFirst    bool
+
+	// Depth of quote chains resolved by fetching missing quoted tweets
+	quoteDepth int
+
+	// Keep original JSON of tweets
+	rawJSON bool
+
+	// Logger of requests, retries and parse warnings
+	logger *slog.Logger
+
+	// Debug dump of requests and responses
+	dumper *requestDumper
+
+	// Fallback backends
+	syndicationFallback bool
+	legacyFallback      bool
+	legacyGuestToken    string
+	nitterInstance      string
+
+	// Handler of anti-bot challenges
+	challengeSolver func(*ChallengeError) error
+
+	// GraphQL query ID discovery
+	discovery *operationDiscovery
+
+	// Feature flags learned from API errors
+	featuresMu       sync.Mutex
+	requiredFeatures map[string]bool
+	obsoleteFeatures map[string]bool
+
+	// Shutdown of background goroutines
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// resettableJar is a cookie jar that can be cleared while in concurrent use
+type resettableJar struct {
+	mu  sync.RWMutex
+	jar http.CookieJar
+}
+
+// newResettableJar creates an empty resettable cookie jar
+func newResettableJar() *resettableJar {
+	j := &resettableJar{}
+	j.reset()
+	return j
+}
+
+// reset replaces all stored cookies with an empty jar
+func (j *resettableJar) reset() {
+	jar, _ := cookiejar.New(nil)
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+}
+
+func (j *resettableJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *resettableJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
 }
 
 // Global cache for user IDs to avoid repeated API calls
 var userIDCache sync.Map
 
 // NewClient creates a new Twitter client
-func NewClient() *Client {
+func NewClient(opts ...Option) *Client {
+	jar := newResettableJar()
 	client := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
+			Jar:     jar,
 		},
+		jar:         jar,
 		bearerToken: BearerToken,
 		cacheTTL:    24 * time.Hour, // Cache for 24 hours
+		clock:       realClock{},
+		dialer:      newDialer(),
+		done:        make(chan struct{}),
+
+		pageParallelism: 1,
+		pageDelay:       DefaultPageDelay,
+		pageJitter:      DefaultPageJitter,
+		logger:          discardLogger,
+	}
+
+	// Transport tuned for bursty paginated crawls of a few API hosts
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	transport.IdleConnTimeout = DefaultIdleConnTimeout
+	transport.ForceAttemptHTTP2 = true
+	transport.DialContext = client.dialContext
+	client.transport = transport
+	client.buildTransportChain()
+
+	for _, opt := range opts {
+		opt(client)
 	}
 
 	// Start cache cleanup goroutine
@@ -230,72 +641,195 @@ func NewClient() *Client {
 
 // cleanupCache periodically removes expired entries from the cache
 func (c *Client) cleanupCache() {
-	ticker := time.NewTicker(time.Hour) // Run cleanup every hour
+	ticker := c.clock.Ticker(time.Hour) // Run cleanup every hour
 	defer ticker.Stop()
 
-	for range ticker.C {
-		userIDCache.Range(func(key, value any) bool {
-			entry := value.(*userIDCacheEntry)
-			if time.Since(entry.Timestamp) > c.cacheTTL {
-				userIDCache.Delete(key)
-			}
-			return true
-		})
+	for {
+		select {
+		case <-ticker.Chan():
+			c.removeExpiredCache()
+		case <-c.done:
+			return
+		}
 	}
 }
 
+// removeExpiredCache removes cache entries older than cache TTL
+func (c *Client) removeExpiredCache() {
+	now := c.clock.Now()
+	userIDCache.Range(func(key, value any) bool {
+		entry := value.(*userIDCacheEntry)
+		if now.Sub(entry.Timestamp) > c.cacheTTL {
+			userIDCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// Close stops background goroutines of the client and releases idle connections.
+// The client must not be used after Close. It is safe to call Close multiple times.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.httpClient.CloseIdleConnections()
+	})
+	return nil
+}
+
+// OnTweet registers a hook invoked for every fetched tweet before it is returned by any method,
+// including search, tweet lookups and fallback backends. The hook may modify the tweet in place.
+func (c *Client) OnTweet(fn func(*Tweet)) {
+	c.onTweet = append(c.onTweet, fn)
+}
+
+// OnPage registers a hook invoked for every fetched timeline page
+func (c *Client) OnPage(fn func(TimelinePage)) {
+	c.onPage = append(c.onPage, fn)
+}
+
 // GetGuestToken gets guest token from Twitter API
 func (c *Client) GetGuestToken() error {
-	req, err := http.NewRequest("POST", BaseURL+"/1.1/guest/activate.json", nil)
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	return c.refreshGuestToken()
+}
+
+// refreshGuestToken requests a new guest token. Caller must hold tokenMu.
+func (c *Client) refreshGuestToken() error {
+	guestToken, err := c.activateGuestToken(BaseURL, c.bearerToken)
+	if err != nil {
+		return err
+	}
+
+	c.guestToken = guestToken
+	c.logger.Debug("guest token refreshed", "guest_token", redactToken(guestToken))
+
+	// Reset cookie jar to start fresh with new guest token
+	c.jar.reset()
+
+	return nil
+}
+
+// currentGuestToken returns guest token, requesting it if the client has none yet
+func (c *Client) currentGuestToken() (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.guestToken == "" {
+		if err := c.refreshGuestToken(); err != nil {
+			return "", err
+		}
+	}
+	return c.guestToken, nil
+}
+
+// activateGuestToken requests a new guest token for the bearer token from API at baseURL
+func (c *Client) activateGuestToken(baseURL, bearerToken string) (string, error) {
+	req, err := http.NewRequest("POST", baseURL+"/1.1/guest/activate.json", nil)
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return "", fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	setBrowserHeaders(req)
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", UserAgent)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error executing request: %w", err)
+		return "", fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected response status: %d, body: %s", resp.StatusCode, string(body))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var tokenResp GuestTokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return fmt.Errorf("error decoding response: %w", err)
+		return "", fmt.Errorf("error decoding response: %w", err)
 	}
 
-	c.guestToken = tokenResp.GuestToken
+	return tokenResp.GuestToken, nil
+}
 
-	// Reset cookie jar to start fresh with new guest token
-	if jar, err := cookiejar.New(nil); err == nil {
-		c.httpClient.Jar = jar
+// makeAPICall makes a universal GraphQL API call to Twitter/X, served from response cache if it is enabled
+func (c *Client) makeAPICall(endpoint string, variables map[string]any, features map[string]any, fieldToggles map[string]any) (*http.Response, error) {
+	if c.responseCache == nil {
+		return c.callAPI(endpoint, variables, features, fieldToggles)
 	}
-
-	return nil
+	return c.responseCache.get(cacheKey(endpoint, variables), c.clock.Now(), func() (*http.Response, error) {
+		return c.callAPI(endpoint, variables, features, fieldToggles)
+	})
 }
 
-// makeAPICall makes a universal GraphQL API call to Twitter/X
-func (c *Client) makeAPICall(endpoint string, variables map[string]any, features map[string]any, fieldToggles map[string]any) (*http.Response, error) {
-	if c.guestToken == "" {
-		if err := c.GetGuestToken(); err != nil {
-			return nil, fmt.Errorf("error getting guest token: %w", err)
+// callAPI makes GraphQL API call, retrying it after learning features, solving challenges or transient failures
+func (c *Client) callAPI(endpoint string, variables map[string]any, features map[string]any, fieldToggles map[string]any) (*http.Response, error) {
+	challengeSolved := false
+	transientRetries := 0
+	for {
+		resp, err := c.doAPICall(endpoint, variables, features, fieldToggles)
+		if err == nil {
+			if c.retryBudget != nil {
+				c.retryBudget.success()
+			}
+			return resp, nil
+		}
+
+		// Retry transient failures while shared budget allows, so that outages are not amplified
+		if c.retryBudget != nil && isTransientError(err) {
+			if c.retryBudget.failure() && transientRetries < maxTransientRetries {
+				c.logger.Info("retrying after transient failure", "endpoint", endpoint, "error", err)
+				c.countRetry(endpoint)
+				c.sleep(retryBackoff << transientRetries)
+				transientRetries++
+				continue
+			}
+			return nil, err
+		}
+
+		// Retry with adjusted feature map when API reports missing or obsolete features
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest && c.learnFeatures([]byte(statusErr.Body)) {
+			c.logger.Info("retrying with adjusted features", "endpoint", endpoint)
+			c.countRetry(endpoint)
+			continue
+		}
+
+		// Retry once after user-supplied solver handled anti-bot challenge
+		var challengeErr *ChallengeError
+		if errors.As(err, &challengeErr) && c.challengeSolver != nil && !challengeSolved {
+			challengeSolved = true
+			if solveErr := c.challengeSolver(challengeErr); solveErr != nil {
+				return nil, fmt.Errorf("%w (challenge solver failed: %v)", err, solveErr)
+			}
+			c.logger.Info("retrying after solved challenge", "endpoint", endpoint)
+			c.countRetry(endpoint)
+			continue
 		}
+
+		return nil, err
+	}
+}
+
+// doAPICall makes a single GraphQL API request
+func (c *Client) doAPICall(endpoint string, variables map[string]any, features map[string]any, fieldToggles map[string]any) (*http.Response, error) {
+	guestToken, err := c.currentGuestToken()
+	if err != nil {
+		return nil, fmt.Errorf("error getting guest token: %w", err)
 	}
 
+	operationPath, operationFeatures := c.resolveOperation(endpoint, features)
+	operationFeatures = c.adjustFeatures(operationFeatures)
+
 	variablesJSON, _ := json.Marshal(variables)
-	featuresJSON, _ := json.Marshal(features)
+	featuresJSON, _ := json.Marshal(operationFeatures)
 	fieldTogglesJSON, _ := json.Marshal(fieldToggles)
 
 	// Create URL with parameters
-	apiURL := BaseURL + endpoint
+	apiURL := BaseURL + operationPath
 	params := url.Values{}
 	params.Add("variables", string(variablesJSON))
 	params.Add("features", string(featuresJSON))
@@ -311,14 +845,10 @@ func (c *Client) makeAPICall(endpoint string, variables map[string]any, features
 	}
 
 	// Set common headers
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Language", "en")
+	setBrowserHeaders(req)
 	req.Header.Set("Authorization", "Bearer "+c.bearerToken)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Origin", "https://x.com")
-	req.Header.Set("Referer", "https://x.com/")
-	req.Header.Set("User-Agent", UserAgent)
-	req.Header.Set("X-Guest-Token", c.guestToken)
+	req.Header.Set("X-Guest-Token", guestToken)
 	req.Header.Set("X-Twitter-Active-User", "yes")
 	req.Header.Set("X-Twitter-Client-Language", "en")
 
@@ -328,15 +858,23 @@ func (c *Client) makeAPICall(endpoint string, variables map[string]any, features
 	}
 
 	// Check for rate limiting
-	if resp.StatusCode == 429 {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.logger.Warn("rate limit exceeded", "endpoint", endpoint, "reset", resp.Header.Get("X-Rate-Limit-Reset"))
+		resp.Body.Close()
+		return nil, newRateLimitError(resp)
+	}
+
+	// Check for anti-bot challenge page returned instead of JSON
+	if isHTMLResponse(resp) {
+		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("rate limit exceeded. Please wait and try again later")
+		return nil, newChallengeError(resp, body)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("unexpected response status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return resp, nil
@@ -348,22 +886,7 @@ func (c *Client) GetUserByScreenName(screenName string) (*UserResponse, error) {
 		"screen_name": screenName,
 	}
 
-	features := map[string]any{
-		"responsive_web_grok_bio_auto_translation_is_enabled":               false,
-		"hidden_profile_subscriptions_enabled":                              true,
-		"payments_enabled":                                                  false,
-		"profile_label_improvements_pcf_label_in_post_enabled":              true,
-		"rweb_tipjar_consumption_enabled":                                   true,
-		"verified_phone_label_enabled":                                      false,
-		"subscriptions_verification_info_is_identity_verified_enabled":      true,
-		"subscriptions_verification_info_verified_since_enabled":            true,
-		"highlights_tweets_tab_ui_enabled":                                  true,
-		"responsive_web_twitter_article_notes_tab_enabled":                  true,
-		"subscriptions_feature_can_gift_premium":                            true,
-		"creator_subscriptions_tweet_preview_api_enabled":                   true,
-		"responsive_web_graphql_skip_user_profile_image_extensions_enabled": false,
-		"responsive_web_graphql_timeline_navigation_enabled":                true,
-	}
+	features := userFeatures()
 
 	fieldToggles := map[string]any{
 		"withAuxiliaryUserLabels": true,
@@ -382,12 +905,32 @@ func (c *Client) GetUserByScreenName(screenName string) (*UserResponse, error) {
 
 	// Check if user was found
 	if userResp.Data.User.Result.RestID == "" {
-		return nil, fmt.Errorf("user not found: %s", screenName)
+		return nil, &NotFoundError{Kind: "user", ID: screenName, Reason: userResp.Data.User.Result.Reason}
 	}
 
 	return &userResp, nil
 }
 
+// userFeatures returns feature switches of GraphQL user queries
+func userFeatures() map[string]any {
+	return map[string]any{
+		"responsive_web_grok_bio_auto_translation_is_enabled":               false,
+		"hidden_profile_subscriptions_enabled":                              true,
+		"payments_enabled":                                                  false,
+		"profile_label_improvements_pcf_label_in_post_enabled":              true,
+		"rweb_tipjar_consumption_enabled":                                   true,
+		"verified_phone_label_enabled":                                      false,
+		"subscriptions_verification_info_is_identity_verified_enabled":      true,
+		"subscriptions_verification_info_verified_since_enabled":            true,
+		"highlights_tweets_tab_ui_enabled":                                  true,
+		"responsive_web_twitter_article_notes_tab_enabled":                  true,
+		"subscriptions_feature_can_gift_premium":                            true,
+		"creator_subscriptions_tweet_preview_api_enabled":                   true,
+		"responsive_web_graphql_skip_user_profile_image_extensions_enabled": false,
+		"responsive_web_graphql_timeline_navigation_enabled":                true,
+	}
+}
+
 // GetUserID gets user ID by username with caching for frequently requested users
 func (c *Client) GetUserID(username string) (string, error) {
 	// Normalize username (remove @ if present)
@@ -408,13 +951,13 @@ func (c *Client) GetUserID(username string) (string, error) {
 
 	userID := userResp.Data.User.Result.RestID
 	if userID == "" {
-		return "", fmt.Errorf("user ID not found for username '%s'", username)
+		return "", &NotFoundError{Kind: "user", ID: username}
 	}
 
 	// Cache the result
 	userIDCache.Store(username, &userIDCacheEntry{
 		UserID:    userID,
-		Timestamp: time.Now(),
+		Timestamp: c.clock.Now(),
 	})
 
 	return userID, nil
@@ -422,15 +965,249 @@ func (c *Client) GetUserID(username string) (string, error) {
 
 // GetUserTweets gets user timeline by user ID and returns a list of tweets
 func (c *Client) GetUserTweets(userID string) ([]Tweet, error) {
+	tweets, _, err := c.getUserTweets(userID, "")
+	return tweets, err
+}
+
+// GetUserTweetsIfChanged gets user timeline like GetUserTweets along with ETag fingerprinting its entries.
+// If the entries match etag of a previous call, it returns ErrNotModified without converting tweets,
+// resolving quotes or running hooks. Tweets from fallback backends come with empty ETag.
+func (c *Client) GetUserTweetsIfChanged(userID, etag string) ([]Tweet, string, error) {
+	return c.getUserTweets(userID, etag)
+}
+
+// GetUserTweetsOrStale gets user timeline like GetUserTweets. If upstream fails with rate limit or
+// transient error (see WithRetryBudget), it returns the last timeline of the user successfully fetched by this method
+// no more than maxStale ago, along with its age. Age is zero for fresh timelines.
+func (c *Client) GetUserTweetsOrStale(userID string, maxStale time.Duration) ([]Tweet, time.Duration, error) {
+	tweets, _, err := c.getUserTweets(userID, "")
+	now := c.clock.Now()
+	if err == nil {
+		c.staleTimelines.Store(userID, &staleTimeline{tweets: tweets, fetched: now})
+		return tweets, 0, nil
+	}
+
+	if _, rateLimited := IsRateLimited(err); !rateLimited && !isTransientError(err) {
+		return nil, 0, err
+	}
+	value, ok := c.staleTimelines.Load(userID)
+	if !ok {
+		return nil, 0, err
+	}
+	stale := value.(*staleTimeline)
+	age := now.Sub(stale.fetched)
+	if age > maxStale {
+		return nil, 0, err
+	}
+	c.logger.Warn("serving stale timeline", "user_id", userID, "age", age, "error", err)
+	return slices.Clone(stale.tweets), age, nil
+}
+
+// staleTimeline is the last successfully fetched timeline of user
+type staleTimeline struct {
+	tweets  []Tweet
+	fetched time.Time
+}
+
+// getUserTweets gets the first page of user timeline and its ETag, returning ErrNotModified
+// if it matches non-empty etag
+func (c *Client) getUserTweets(userID, etag string) ([]Tweet, string, error) {
+	var page TimelinePage
+
+	collector, err := c.requestUserTweets(userID, "", DefaultPageSize)
+	if err != nil {
+		backends := c.fallbackBackends()
+		if len(backends) == 0 || !IsAccessBlocked(err) {
+			return nil, "", err
+		}
+		tweets, fallbackErr := c.getFallbackUserTweets(userID, backends)
+		if fallbackErr != nil {
+			return nil, "", fmt.Errorf("%w (%v)", err, fallbackErr)
+		}
+		if c.newestFirst {
+			SortTweets(tweets)
+		}
+		page = TimelinePage{UserID: userID, Tweets: tweets}
+	} else {
+		if etag != "" && collector.etag() == etag {
+			return nil, etag, ErrNotModified
+		}
+		c.resolveCollectorQuotes(collector)
+		page = TimelinePage{
+			UserID:       userID,
+			Tweets:       collector.tweets(),
+			TopCursor:    collector.topCursor,
+			BottomCursor: collector.bottomCursor,
+		}
+		etag = collector.etag()
+	}
+
+	c.runHooks(&page)
+
+	return page.Tweets, etag, nil
+}
+
+// GetPinnedTweet gets pinned tweet of user, requesting as few timeline entries as possible.
+// It returns nil without error if the user has no pinned tweet.
+func (c *Client) GetPinnedTweet(userID string) (*Tweet, error) {
+	// Pin entry is returned regardless of requested count
+	collector, err := c.fetchUserTweets(userID, "", 1)
+	if err != nil {
+		return nil, err
+	}
+	if collector.pinned == nil {
+		return nil, nil
+	}
+
+	tweet := convertTweetResult(collector.pinned)
+	c.runTweetHooks(&tweet)
+	return &tweet, nil
+}
+
+// fallbackBackend is an alternative source of user timelines
+type fallbackBackend struct {
+	name  string
+	fetch func(userID string) ([]Tweet, error)
+}
+
+// fallbackBackends returns enabled fallback backends in the order they should be tried
+func (c *Client) fallbackBackends() []fallbackBackend {
+	var backends []fallbackBackend
+	if c.syndicationFallback {
+		backends = append(backends, fallbackBackend{"syndication", c.getSyndicationUserTweets})
+	}
+	if c.legacyFallback {
+		backends = append(backends, fallbackBackend{"legacy API", c.getLegacyUserTweets})
+	}
+	if c.nitterInstance != "" {
+		backends = append(backends, fallbackBackend{"nitter", c.getNitterUserTweets})
+	}
+	return backends
+}
+
+// getFallbackUserTweets tries fallback backends in order until one of them succeeds
+func (c *Client) getFallbackUserTweets(userID string, backends []fallbackBackend) ([]Tweet, error) {
+	var errs []string
+	for _, backend := range backends {
+		tweets, err := backend.fetch(userID)
+		if err == nil {
+			return tweets, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s fallback failed: %v", backend.name, err))
+	}
+	return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// runHooks invokes registered hooks for the fetched page
+func (c *Client) runHooks(page *TimelinePage) {
+	for i := range page.Tweets {
+		c.runTweetHooks(&page.Tweets[i])
+	}
+	for _, fn := range c.onPage {
+		fn(*page)
+	}
+}
+
+// runTweetHooks invokes registered OnTweet hooks for the fetched tweet
+func (c *Client) runTweetHooks(tweet *Tweet) {
+	for _, fn := range c.onTweet {
+		fn(tweet)
+	}
+}
+
+// fetchUserTweets requests a page of user timeline from GraphQL API and resolves quote chains.
+// Empty cursor requests the first page.
+func (c *Client) fetchUserTweets(userID, cursor string, count int) (*timelineCollector, error) {
+	collector, err := c.requestUserTweets(userID, cursor, count)
+	if err != nil {
+		return nil, err
+	}
+	c.resolveCollectorQuotes(collector)
+	return collector, nil
+}
+
+// requestUserTweets requests and decodes a page of user timeline from GraphQL API
+func (c *Client) requestUserTweets(userID, cursor string, count int) (*timelineCollector, error) {
 	variables := map[string]any{
 		"userId":                                 userID,
-		"count":                                  100,
+		"count":                                  count,
 		"includePromotedContent":                 true,
 		"withQuickPromoteEligibilityTweetFields": true,
 		"withVoice":                              true,
 	}
+	if cursor != "" {
+		variables["cursor"] = cursor
+	}
+
+	features := timelineFeatures()
+
+	fieldToggles := map[string]any{
+		"withArticlePlainText": false,
+	}
+
+	resp, err := c.makeAPICall(UserTweetsPath, variables, features, fieldToggles)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Decode entries one by one to avoid holding the whole timeline in memory
+	collector := &timelineCollector{render: c.render, pinnedPosition: c.pinnedPosition, newestFirst: c.newestFirst, logger: c.logger}
+	decode := decodeTimeline
+	if c.rawJSON {
+		decode = decodeTimelineRaw
+	}
+	if err := decode(resp.Body, collector.addEntry); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return collector, nil
+}
+
+// resolveCollectorQuotes resolves quote chains of collected tweets down to configured depth
+func (c *Client) resolveCollectorQuotes(collector *timelineCollector) {
+	if c.quoteDepth == 0 {
+		return
+	}
+	fetched := make(map[string]*TweetResult)
+	for _, tweetResult := range collector.tweetResults {
+		c.resolveQuotedTweets(tweetResult, c.quoteDepth, fetched)
+	}
+	if collector.pinned != nil {
+		c.resolveQuotedTweets(collector.pinned, c.quoteDepth, fetched)
+	}
+}
+
+// resolveQuotedTweets follows quote chain of the tweet down to the given depth, fetching quoted tweets
+// missing from response by ID. Quoted tweets which can't be fetched are left unresolved.
+// The fetched map caches tweets quoted several times.
+func (c *Client) resolveQuotedTweets(tweetResult *TweetResult, depth int, fetched map[string]*TweetResult) {
+	for ; depth > 0 && tweetResult != nil; depth-- {
+		// Retweet is converted to the original tweet, so resolve its quotes
+		if tweetResult.RetweetedStatusResult.Result != nil {
+			tweetResult = tweetResult.RetweetedStatusResult.Result
+		}
+
+		quoted := tweetResult.QuotedStatusResult.Result
+		if quoted == nil {
+			quotedID := tweetResult.Legacy.QuotedStatusIDStr
+			if quotedID == "" {
+				return
+			}
+			var ok bool
+			if quoted, ok = fetched[quotedID]; !ok {
+				quoted, _ = c.getSyndicationTweetResult(quotedID)
+				fetched[quotedID] = quoted
+			}
+			tweetResult.QuotedStatusResult.Result = quoted
+		}
+		tweetResult = quoted
+	}
+}
 
-	features := map[string]any{
+// timelineFeatures returns feature switches of GraphQL timeline queries
+func timelineFeatures() map[string]any {
+	return map[string]any{
 		"rweb_video_screen_enabled":                                               false,
 		"payments_enabled":                                                        false,
 		"profile_label_improvements_pcf_label_in_post_enabled":                    true,
@@ -464,31 +1241,27 @@ func (c *Client) GetUserTweets(userID string) ([]Tweet, error) {
 		"responsive_web_grok_image_annotation_enabled":                            true,
 		"responsive_web_enhance_cards_enabled":                                    false,
 	}
+}
 
-	fieldToggles := map[string]any{
-		"withArticlePlainText": false,
+// processTweetResult processes a single tweet result by extracting images, setting URL, and generating HTML
+func processTweetResult(tweetResult *TweetResult, opts renderOptions) {
+	if tweetResult.Legacy.FullText == "" {
+		return
 	}
 
-	resp, err := c.makeAPICall(UserTweetsPath, variables, features, fieldToggles)
-	if err != nil {
-		return nil, err
+	// Process the retweeted status to ensure it has all necessary fields
+	if tweetResult.RetweetedStatusResult.Result != nil {
+		tweetResult.RetweetedStatusResult.Result = unwrapTweetResult(tweetResult.RetweetedStatusResult.Result)
+		processTweetResult(tweetResult.RetweetedStatusResult.Result, opts)
 	}
-	defer resp.Body.Close()
-
-	var timelineResp TimelineResponse
-	if err := json.NewDecoder(resp.Body).Decode(&timelineResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if tweetResult.QuotedStatusResult.Result != nil {
+		tweetResult.QuotedStatusResult.Result = unwrapTweetResult(tweetResult.QuotedStatusResult.Result)
+		processTweetResult(tweetResult.QuotedStatusResult.Result, opts)
 	}
 
-	// Extract tweets from the timeline response
-	tweets := extractTweetsFromTimeline(&timelineResp)
-	return tweets, nil
-}
-
-// processTweetResult processes a single tweet result by extracting images, setting URL, and generating HTML
-func processTweetResult(tweetResult *TweetResult) {
-	if tweetResult.Legacy.FullText == "" {
-		return
+	if opts.trimMediaLinks {
+		tweetResult.Legacy.FullText = trimMediaLinks(tweetResult.Legacy.FullText,
+			tweetResult.Legacy.Entities.Media, tweetResult.Legacy.ExtendedEntities.Media)
 	}
 
 	// Determine tweet type
@@ -498,6 +1271,9 @@ func processTweetResult(tweetResult *TweetResult) {
 
 	// Extract images from tweet media entities
 	var images []string
+	if n := len(tweetResult.Legacy.ExtendedEntities.Media); n > 0 {
+		images = make([]string, 0, n)
+	}
 	// First check extended_entities for media (preferred source)
 	for _, media := range tweetResult.Legacy.ExtendedEntities.Media {
 		if media.Type == "photo" && media.MediaURLHTTPS != "" {
@@ -517,82 +1293,93 @@ func processTweetResult(tweetResult *TweetResult) {
 	// Set the permanent URL for a tweet
 	screenName := tweetResult.Core.UserResults.Result.Core.ScreenName
 	if screenName != "" {
-		tweetResult.URL = fmt.Sprintf("https://x.com/%s/status/%s", screenName, tweetResult.RestID)
+		tweetResult.URL = "https://x.com/" + screenName + "/status/" + tweetResult.RestID
 	}
 
-	// Generate HTML content with links and images
-	text := html.EscapeString(tweetResult.Legacy.FullText)
-
-	// Replace URLs with HTML links
-	for _, url := range tweetResult.Legacy.Entities.Urls {
-		expandedURL := url.ExpandedURL
-		if expandedURL == "" {
-			expandedURL = url.URL
+	// Generate HTML content with links and images.
+	// API text comes with &, < and > already escaped, so it is unescaped first to avoid double escaping.
+	rawText := tweetResult.Legacy.FullText
+	plainText := html.UnescapeString(rawText)
+	text := html.EscapeString(plainText)
+
+	// Link entities at their index ranges in API text, falling back to matching entity texts
+	// for sources without valid indices
+	if linked, ok := linkEntitiesByIndices(rawText, tweetResult); ok {
+		text = linked
+		if opts.unescapeText {
+			unescapeEntityIndices(tweetResult, rawText)
 		}
-		htmlLink := fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`,
-			html.EscapeString(expandedURL),
-			html.EscapeString(url.DisplayURL))
-		text = strings.ReplaceAll(text, url.URL, htmlLink)
+	} else {
+		text = linkEntitiesByText(text, tweetResult)
 	}
-
-	// Replace hashtags with HTML links
-	hashtagRegex := regexp.MustCompile(`#(\w+)`)
-	for _, hashtag := range tweetResult.Legacy.Entities.Hashtags {
-		hashtagText := "#" + hashtag.Text
-		hashtagLink := fmt.Sprintf(`<a href="https://x.com/hashtag/%s" target="_blank">%s</a>`,
-			html.EscapeString(hashtag.Text),
-			html.EscapeString(hashtagText))
-		text = hashtagRegex.ReplaceAllStringFunc(text, func(match string) string {
-			if strings.EqualFold(match, hashtagText) {
-				return hashtagLink
-			}
-			return match
-		})
-	}
-
-	// Replace mentions with HTML links
-	mentionRegex := regexp.MustCompile(`@(\w+)`)
-	text = mentionRegex.ReplaceAllStringFunc(text, func(match string) string {
-		username := strings.TrimPrefix(match, "@")
-		return fmt.Sprintf(`<a href="https://x.com/%s" target="_blank">%s</a>`,
-			html.EscapeString(username),
-			html.EscapeString(match))
-	})
+	text = renderLineBreaks(text, opts.lineBreaks)
 
 	// Add images at the end
-	for _, imageURL := range tweetResult.Images {
-		text += fmt.Sprintf(`<br><a href="%s" target="_blank"><img src="%s" alt="Tweet image" style="max-width: 500px; height: auto;"></a>`,
-			html.EscapeString(imageURL),
-			html.EscapeString(imageURL))
+	if len(tweetResult.Images) > 0 {
+		buf := getBuffer()
+		buf.WriteString(text)
+		for _, imageURL := range tweetResult.Images {
+			escapedURL := html.EscapeString(imageURL)
+			buf.WriteString(`<br><a href="`)
+			buf.WriteString(escapedURL)
+			buf.WriteString(`" target="_blank"><img src="`)
+			buf.WriteString(escapedURL)
+			buf.WriteString(`" alt="Tweet image" style="max-width: 500px; height: auto;"></a>`)
+		}
+		text = buf.String()
+		putBuffer(buf)
 	}
 
 	tweetResult.HTML = text
+	if opts.detectLanguage && (tweetResult.Legacy.Lang == "und" || tweetResult.Legacy.Lang == "") {
+		tweetResult.DetectedLang = detectLanguage(plainText)
+	}
+	if opts.unescapeText {
+		tweetResult.Legacy.FullText = plainText
+	}
 }
 
 // convertTweetResult converts TweetResult to public Tweet structure
 func convertTweetResult(tweetResult *TweetResult) Tweet {
-	// Store original retweet flag
+	// Store original retweet flag and JSON
 	originalIsRetweet := tweetResult.IsRetweet
+	raw := tweetResult.Raw
 
 	// Check if this is a retweet and replace with original tweet if available
 	if tweetResult.Legacy.RetweetedStatusIDStr != "" || tweetResult.RetweetedStatusResult.Result != nil {
 		originalIsRetweet = true
 		if tweetResult.RetweetedStatusResult.Result != nil {
-			// Process the retweeted status to ensure it has all necessary fields
-			processTweetResult(tweetResult.RetweetedStatusResult.Result)
 			// Replace the current tweet with the retweeted one
 			tweetResult = tweetResult.RetweetedStatusResult.Result
 		}
 	}
 
+	// View count comes as string and is missing for tweets older than view counting
+	views, _ := strconv.Atoi(tweetResult.Views.Count)
+
 	// Extract hashtags as strings
 	var hashtags []string
+	if n := len(tweetResult.Legacy.Entities.Hashtags); n > 0 {
+		hashtags = make([]string, 0, n)
+	}
 	for _, hashtag := range tweetResult.Legacy.Entities.Hashtags {
 		hashtags = append(hashtags, hashtag.Text)
 	}
 
+	// Extract cashtags as strings
+	var cashtags []string
+	if n := len(tweetResult.Legacy.Entities.Symbols); n > 0 {
+		cashtags = make([]string, 0, n)
+	}
+	for _, symbol := range tweetResult.Legacy.Entities.Symbols {
+		cashtags = append(cashtags, symbol.Text)
+	}
+
 	// Extract URLs
 	var urls []URL
+	if n := len(tweetResult.Legacy.Entities.Urls); n > 0 {
+		urls = make([]URL, 0, n)
+	}
 	for _, url := range tweetResult.Legacy.Entities.Urls {
 		urls = append(urls, URL{
 			Short:    url.URL,
@@ -601,13 +1388,27 @@ func convertTweetResult(tweetResult *TweetResult) Tweet {
 		})
 	}
 
-	// Extract mentions from text using regex
+	// Extract mentions from entities, falling back to regex for sources without entities
 	var mentions []string
-	mentionRegex := regexp.MustCompile(`@(\w+)`)
-	matches := mentionRegex.FindAllStringSubmatch(tweetResult.Legacy.FullText, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			mentions = append(mentions, match[1])
+	var userMentions []Mention
+	if n := len(tweetResult.Legacy.Entities.UserMentions); n > 0 {
+		mentions = make([]string, 0, n)
+		userMentions = make([]Mention, 0, n)
+		for _, mention := range tweetResult.Legacy.Entities.UserMentions {
+			mentions = append(mentions, mention.ScreenName)
+			userMentions = append(userMentions, Mention{
+				Username: mention.ScreenName,
+				UserID:   mention.IDStr,
+				Name:     mention.Name,
+			})
+		}
+	} else {
+		matches := mentionRegex.FindAllStringSubmatch(tweetResult.Legacy.FullText, -1)
+		for _, match := range matches {
+			if len(match) > 1 {
+				mentions = append(mentions, match[1])
+				userMentions = append(userMentions, Mention{Username: match[1]})
+			}
 		}
 	}
 
@@ -615,6 +1416,8 @@ func convertTweetResult(tweetResult *TweetResult) Tweet {
 		ID:           tweetResult.RestID,
 		Text:         tweetResult.Legacy.FullText,
 		HTML:         tweetResult.HTML,
+		Lang:         tweetResult.Legacy.Lang,
+		DetectedLang: tweetResult.DetectedLang,
 		CreatedAt:    tweetResult.Legacy.CreatedAt,
 		PermanentURL: tweetResult.URL,
 		Username:     tweetResult.Core.UserResults.Result.Core.ScreenName,
@@ -622,66 +1425,278 @@ func convertTweetResult(tweetResult *TweetResult) Tweet {
 		Likes:        tweetResult.Legacy.FavoriteCount,
 		Retweets:     tweetResult.Legacy.RetweetCount,
 		Replies:      tweetResult.Legacy.ReplyCount,
+		Views:        views,
 		IsPinned:     tweetResult.IsPinned,
 		IsRetweet:    originalIsRetweet,
 		IsQuoted:     tweetResult.IsQuoted,
 		IsReply:      tweetResult.IsReply,
+		IsExclusive:  tweetResult.ExclusiveTweetInfo != nil || tweetResult.ExclusivityInfo.Exclusive,
+		QuotedTweet:  convertQuotedTweet(tweetResult.QuotedStatusResult.Result),
+		Raw:          raw,
+
+		ConversationID:    tweetResult.Legacy.ConversationIDStr,
+		InReplyToID:       tweetResult.Legacy.InReplyToStatusIDStr,
+		InReplyToUserID:   tweetResult.Legacy.InReplyToUserIDStr,
+		InReplyToUsername: tweetResult.Legacy.InReplyToScreenName,
+
 		Images:       tweetResult.Images,
+		Media:        tweetMedia(tweetResult),
 		Hashtags:     hashtags,
+		Cashtags:     cashtags,
 		URLs:         urls,
 		Mentions:     mentions,
+		UserMentions: userMentions,
+		Entities:     tweetEntities(tweetResult),
+		Community:    tweetCommunity(tweetResult),
+		Restrictions: tweetRestrictions(tweetResult),
+	}
+}
+
+// convertQuotedTweet converts quoted tweet result, reporting tombstones as unavailable tweets.
+// It returns nil if the quoted tweet is not included in response.
+func convertQuotedTweet(tweetResult *TweetResult) *QuotedTweet {
+	if tweetResult == nil {
+		return nil
+	}
+	if tweetResult.Legacy.FullText == "" {
+		quoted := &QuotedTweet{Unavailable: true, Reason: tweetResult.Reason}
+		if tweetResult.Tombstone != nil {
+			quoted.Reason = tweetResult.Tombstone.Text.Text
+		}
+		return quoted
+	}
+	return &QuotedTweet{Tweet: convertTweetResult(tweetResult)}
+}
+
+// unwrapTweetResult returns the tweet wrapped into TweetWithVisibilityResults
+// with its restrictions moved to it, or the tweet result itself if it is not wrapped
+func unwrapTweetResult(tweetResult *TweetResult) *TweetResult {
+	if tweetResult.Tweet == nil {
+		return tweetResult
+	}
+	tweet := tweetResult.Tweet
+	tweet.LimitedActionResults = tweetResult.LimitedActionResults
+	tweet.TweetInterstitial = tweetResult.TweetInterstitial
+	if tweet.ExclusiveTweetInfo == nil {
+		tweet.ExclusiveTweetInfo = tweetResult.ExclusiveTweetInfo
+	}
+	if tweet.Raw == nil {
+		tweet.Raw = tweetResult.Raw
+	}
+	return tweet
+}
+
+// tweetRestrictions returns restrictions of tweet or nil if there are none
+func tweetRestrictions(tweetResult *TweetResult) *Restrictions {
+	limitedActions := tweetResult.LimitedActionResults.LimitedActions
+	interstitial := tweetResult.TweetInterstitial
+	legacy := &tweetResult.Legacy
+	if len(limitedActions) == 0 && interstitial == nil && len(legacy.WithheldInCountries) == 0 && !legacy.WithheldCopyright {
+		return nil
+	}
+
+	restrictions := &Restrictions{
+		WithheldInCountries: legacy.WithheldInCountries,
+		WithheldCopyright:   legacy.WithheldCopyright,
+	}
+	for _, limitedAction := range limitedActions {
+		restrictions.LimitedActions = append(restrictions.LimitedActions, limitedAction.Action)
+		if restrictions.LimitedActionsReason == "" {
+			restrictions.LimitedActionsReason = firstNonEmpty(limitedAction.Prompt.Subtext.Text, limitedAction.Prompt.Headline.Text)
+		}
+	}
+	if interstitial != nil {
+		restrictions.Interstitial = interstitial.Text.Text
+		restrictions.InterstitialType = interstitial.DisplayType
+	}
+	return restrictions
+}
+
+// tweetCommunity returns community of tweet or nil if it was not posted in a community
+func tweetCommunity(tweetResult *TweetResult) *Community {
+	community := tweetResult.CommunityResults.Result
+	if community == nil || community.IDStr == "" {
+		return nil
+	}
+	return &Community{
+		ID:   community.IDStr,
+		Name: community.Name,
+		URL:  "https://x.com/i/communities/" + community.IDStr,
 	}
 }
 
 // extractTweetsFromTimeline extracts tweets from timeline response
 func extractTweetsFromTimeline(timeline *TimelineResponse) []Tweet {
-	var tweetResults []TweetResult
-
-	for _, instruction := range timeline.Data.User.Result.Timeline.Timeline.Instructions {
-		if instruction.Type == "TimelineAddEntries" {
-			for _, entry := range instruction.Entries {
-				// Process regular tweets
-				if strings.Contains(entry.EntryID, "tweet-") && entry.Content.ItemContent != nil {
-					tweetResult := entry.Content.ItemContent.TweetResults.Result
-					processTweetResult(&tweetResult)
-					if tweetResult.Legacy.FullText != "" {
-						tweetResults = append(tweetResults, tweetResult)
-					}
-				}
+	return collectTimeline(timeline).tweets()
+}
 
-				// Process profile-conversation entries
-				if strings.Contains(entry.EntryID, "profile-conversation-") &&
-					entry.Content.EntryType == "TimelineTimelineModule" &&
-					entry.Content.Items != nil {
-
-					for _, item := range *entry.Content.Items {
-						if strings.Contains(item.EntryID, "tweet-") {
-							tweetResult := item.Item.ItemContent.TweetResults.Result
-							processTweetResult(&tweetResult)
-							if tweetResult.Legacy.FullText != "" {
-								tweetResults = append(tweetResults, tweetResult)
-							}
-						}
-					}
-				}
-			}
-		} else if instruction.Type == "TimelinePinEntry" && instruction.Entry != nil {
-			if strings.Contains(instruction.Entry.EntryID, "tweet-") && instruction.Entry.Content.ItemContent != nil {
-				tweetResult := instruction.Entry.Content.ItemContent.TweetResults.Result
-				tweetResult.IsPinned = true
-				processTweetResult(&tweetResult)
-				if tweetResult.Legacy.FullText != "" {
-					tweetResults = append(tweetResults, tweetResult)
+// collectTimeline collects tweets and cursors from all instructions of timeline response
+func collectTimeline(timeline *TimelineResponse) *timelineCollector {
+	collector := &timelineCollector{}
+	instructions := timeline.Data.User.Result.Timeline.Timeline.Instructions
+	for i := range instructions {
+		instruction := &instructions[i]
+		for j := range instruction.Entries {
+			collector.addEntry(instruction.Type, &instruction.Entries[j])
+		}
+		if instruction.Entry != nil {
+			collector.addEntry(instruction.Type, instruction.Entry)
+		}
+	}
+	return collector
+}
+
+// timelineCollector accumulates tweets and cursors from timeline entries
+type timelineCollector struct {
+	render         renderOptions
+	pinnedPosition PinnedPosition
+	newestFirst    bool
+	pinned         *TweetResult
+	tweetResults   []*TweetResult // Regular entries without the pinned tweet
+	topCursor      string
+	bottomCursor   string
+	logger         *slog.Logger // Optional logger of skipped entries
+	digest         hash.Hash    // Fingerprint of entry IDs, see etag
+}
+
+// addEntry processes a single entry of timeline instruction with the given type.
+// Tweet results are processed in place and referenced, not copied.
+func (tc *timelineCollector) addEntry(instructionType string, entry *TimelineEntry) {
+	// Pagination cursors
+	if entry.Content.EntryType == "TimelineTimelineCursor" {
+		if entry.Content.CursorType == "Top" && tc.topCursor == "" {
+			tc.topCursor = entry.Content.Value
+		}
+		if entry.Content.CursorType == "Bottom" && tc.bottomCursor == "" {
+			tc.bottomCursor = entry.Content.Value
+		}
+		return
+	}
+
+	tc.fingerprint(instructionType, entry)
+
+	if instructionType == "TimelineAddEntries" {
+		// Process regular tweets
+		if strings.Contains(entry.EntryID, "tweet-") && entry.Content.ItemContent != nil {
+			tc.add(&entry.Content.ItemContent.TweetResults.Result, false)
+		}
+
+		// Process profile-conversation entries
+		if strings.Contains(entry.EntryID, "profile-conversation-") &&
+			entry.Content.EntryType == "TimelineTimelineModule" &&
+			entry.Content.Items != nil {
+
+			items := *entry.Content.Items
+			for i := range items {
+				if strings.Contains(items[i].EntryID, "tweet-") {
+					tc.add(&items[i].Item.ItemContent.TweetResults.Result, false)
 				}
 			}
 		}
+	} else if instructionType == "TimelinePinEntry" {
+		if strings.Contains(entry.EntryID, "tweet-") && entry.Content.ItemContent != nil {
+			tc.add(&entry.Content.ItemContent.TweetResults.Result, true)
+		}
 	}
+}
 
-	// Convert TweetResults to public Tweet structures
-	var tweets []Tweet
-	for _, tweetResult := range tweetResults {
-		tweets = append(tweets, convertTweetResult(&tweetResult))
+// add processes tweet result and collects it if it has content
+func (tc *timelineCollector) add(tweetResult *TweetResult, pinned bool) {
+	tweetResult = unwrapTweetResult(tweetResult)
+	if pinned {
+		tweetResult.IsPinned = true
+	}
+	processTweetResult(tweetResult, tc.render)
+	if tweetResult.Legacy.FullText == "" {
+		if tc.logger != nil {
+			tc.logger.Debug("skipping tweet without text", "id", tweetResult.RestID, "typename", tweetResult.Typename)
+		}
+		return
+	}
+	if pinned {
+		tc.pinned = tweetResult
+		return
 	}
+	tc.tweetResults = append(tc.tweetResults, tweetResult)
+}
 
+// fingerprint adds IDs of entry and its module items to digest. Promoted entries vary between requests, so they are skipped.
+func (tc *timelineCollector) fingerprint(instructionType string, entry *TimelineEntry) {
+	if strings.HasPrefix(entry.EntryID, "promoted-") {
+		return
+	}
+	if tc.digest == nil {
+		tc.digest = sha256.New()
+	}
+	io.WriteString(tc.digest, instructionType+":"+entry.EntryID+"\n")
+	if entry.Content.Items != nil {
+		for _, item := range *entry.Content.Items {
+			io.WriteString(tc.digest, item.EntryID+"\n")
+		}
+	}
+}
+
+// etag returns fingerprint of collected entries
+func (tc *timelineCollector) etag() string {
+	if tc.digest == nil {
+		tc.digest = sha256.New()
+	}
+	return fmt.Sprintf(`"%x"`, tc.digest.Sum(nil)[:16])
+}
+
+// results returns collected tweet results with the pinned tweet placed according to pinnedPosition
+// or sorted newest first. Regular entry duplicating the pinned tweet is dropped.
+func (tc *timelineCollector) results() []*TweetResult {
+	if tc.newestFirst {
+		sortTweetResults(tc.tweetResults)
+	}
+	if tc.pinned == nil || tc.pinnedPosition == PinnedExcluded {
+		return tc.tweetResults
+	}
+
+	results := make([]*TweetResult, 0, len(tc.tweetResults)+1)
+	inserted := false
+	for _, tweetResult := range tc.tweetResults {
+		if tweetResult.RestID == tc.pinned.RestID {
+			continue
+		}
+		if !inserted && ((tc.pinnedPosition == PinnedFirst && !tc.newestFirst) || compareTweetIDs(tweetResult.RestID, tc.pinned.RestID) < 0) {
+			results = append(results, tc.pinned)
+			inserted = true
+		}
+		results = append(results, tweetResult)
+	}
+	if !inserted {
+		results = append(results, tc.pinned)
+	}
+	return results
+}
+
+// tweets converts collected TweetResults to public Tweet structures
+func (tc *timelineCollector) tweets() []Tweet {
+	results := tc.results()
+	if len(results) == 0 {
+		return nil
+	}
+	tweets := make([]Tweet, 0, len(results))
+	for _, tweetResult := range results {
+		tweets = append(tweets, convertTweetResult(tweetResult))
+	}
 	return tweets
 }
+
+// tweetsWithEntryIDs converts collected TweetResults like tweets and also maps tweet IDs to IDs
+// of their timeline entries. They differ for retweets, which are converted to the original tweet.
+func (tc *timelineCollector) tweetsWithEntryIDs() ([]Tweet, map[string]string) {
+	results := tc.results()
+	tweets := make([]Tweet, 0, len(results))
+	entryIDs := make(map[string]string, len(results))
+	for _, tweetResult := range results {
+		tweet := convertTweetResult(tweetResult)
+		if _, ok := entryIDs[tweet.ID]; !ok {
+			entryIDs[tweet.ID] = tweetResult.RestID
+		}
+		tweets = append(tweets, tweet)
+	}
+	return tweets, entryIDs
+}
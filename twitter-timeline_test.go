@@ -1,28 +1,34 @@
 package twittertimeline
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
 
-// Test constants - using known public accounts
-const (
-	// Elon Musk's account - very active and public
-	TestUserID   = "44196397"
-	TestUsername = "elonmusk"
-
-	// Known active account with various tweet types
-	TestUserID2   = "783214" // Twitter account
-	TestUsername2 = "Twitter"
-
-	// Invalid IDs for error testing
-	InvalidUserID   = "999999999999999999"
-	InvalidUsername = "thisusernameshouldnotexist123456789"
-)
-
 func TestNewClient(t *testing.T) {
 	client := NewClient()
 
@@ -41,266 +47,3379 @@ func TestNewClient(t *testing.T) {
 	if client.cacheTTL != 24*time.Hour {
 		t.Error("Cache TTL not set correctly")
 	}
+
+	transport, ok := client.transport.(*http.Transport)
+	if !ok {
+		t.Fatal("Base transport is not *http.Transport")
+	}
+	if transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || transport.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Error("Connection pool not tuned")
+	}
 }
 
-func TestGetGuestToken(t *testing.T) {
-	client := NewClient()
+func TestConnectionPoolOptions(t *testing.T) {
+	client := NewClient(WithConnectionPool(200, time.Minute), WithHTTP2(false))
+	defer client.Close()
+
+	transport := client.transport.(*http.Transport)
+	if transport.MaxIdleConnsPerHost != 200 || transport.MaxIdleConns < 200 {
+		t.Errorf("Max idle connections not set: %d/%d", transport.MaxIdleConnsPerHost, transport.MaxIdleConns)
+	}
+	if transport.IdleConnTimeout != time.Minute {
+		t.Errorf("Idle timeout not set: %v", transport.IdleConnTimeout)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("HTTP/2 not disabled")
+	}
+}
+
+// roundTripFunc allows to use a function as http.RoundTripper in offline tests
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
 
-	err := client.GetGuestToken()
+// newTestClient creates a client that answers guest token requests locally
+// and serves the given body for all other API calls
+func newTestClient(status int, body string, opts ...Option) *Client {
+	client := NewClient(opts...)
+	client.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		respBody := body
+		respStatus := status
+		if strings.HasSuffix(req.URL.Path, "/guest/activate.json") {
+			respBody = `{"guest_token":"1234567890"}`
+			respStatus = http.StatusOK
+		}
+		return &http.Response{
+			StatusCode: respStatus,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(respBody)),
+			Request:    req,
+		}, nil
+	})
+	client.buildTransportChain()
+	return client
+}
+
+const testTimelineJSON = `{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[
+{"type":"TimelinePinEntry","entry":{"entryId":"tweet-100","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"rest_id":"100","core":{"user_results":{"result":{"core":{"screen_name":"test"}}}},"legacy":{"full_text":"Pinned #news","created_at":"Mon Jan 01 00:00:00 +0000 2024","user_id_str":"42","favorite_count":5,"entities":{"hashtags":[{"text":"news"}]}}}}}}}},
+{"type":"TimelineAddEntries","entries":[
+{"entryId":"tweet-200","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"rest_id":"200","core":{"user_results":{"result":{"core":{"screen_name":"test"}}}},"legacy":{"full_text":"Hello @world","created_at":"Tue Jan 02 00:00:00 +0000 2024","user_id_str":"42","favorite_count":10,"retweet_count":2,"reply_count":1}}}}}},
+{"entryId":"cursor-top-1","content":{"entryType":"TimelineTimelineCursor","value":"TOP","cursorType":"Top"}},
+{"entryId":"cursor-bottom-1","content":{"entryType":"TimelineTimelineCursor","value":"BOTTOM","cursorType":"Bottom"}}
+]}]}}}}}}`
+
+func TestHooks(t *testing.T) {
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+
+	var seen []string
+	client.OnTweet(func(tweet *Tweet) {
+		seen = append(seen, tweet.ID)
+		tweet.Text = strings.ToUpper(tweet.Text)
+	})
+	var pages []TimelinePage
+	client.OnPage(func(page TimelinePage) {
+		pages = append(pages, page)
+	})
+
+	tweets, err := client.GetUserTweets("42")
 	if err != nil {
-		t.Fatalf("GetGuestToken() failed: %v", err)
+		t.Fatalf("GetUserTweets() failed: %v", err)
 	}
 
-	if client.guestToken == "" {
-		t.Error("Guest token is empty after acquisition")
+	if len(seen) != len(tweets) {
+		t.Errorf("OnTweet called %d times, expected %d", len(seen), len(tweets))
+	}
+	if tweets[1].Text != "HELLO @WORLD" {
+		t.Errorf("OnTweet modification not applied: %s", tweets[1].Text)
 	}
 
-	// Test that guest token looks valid (should be alphanumeric)
-	matched, _ := regexp.MatchString(`^[a-zA-Z0-9]+$`, client.guestToken)
-	if !matched {
-		t.Errorf("Guest token format seems invalid: %s", client.guestToken)
+	if len(pages) != 1 {
+		t.Fatalf("OnPage called %d times, expected 1", len(pages))
+	}
+	if pages[0].UserID != "42" || len(pages[0].Tweets) != 2 {
+		t.Errorf("Unexpected page: %+v", pages[0])
+	}
+	if pages[0].TopCursor != "TOP" || pages[0].BottomCursor != "BOTTOM" {
+		t.Errorf("Unexpected cursors: top=%q bottom=%q", pages[0].TopCursor, pages[0].BottomCursor)
 	}
 }
 
-func TestGetUserTweets_ValidUserID(t *testing.T) {
-	client := NewClient()
+func TestTweetHooksOfAllFetchers(t *testing.T) {
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body string
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/SearchTimeline"):
+			body = searchTweetsPageJSON("", "1", "2")
+		case strings.HasSuffix(req.URL.Path, "/TweetResultsByRestIds"):
+			body = tweetResultsJSON([]string{"3", "4"})
+		default:
+			return graphQLTransport.RoundTrip(req)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+
+	var seen []string
+	client.OnTweet(func(tweet *Tweet) {
+		seen = append(seen, tweet.ID)
+		tweet.Text = strings.ToUpper(tweet.Text)
+	})
 
-	tweets, err := client.GetUserTweets(TestUserID)
+	found, err := client.SearchTweets("golang", SearchOptions{MaxPages: 1})
 	if err != nil {
-		t.Fatalf("GetUserTweets() failed for valid user ID: %v", err)
+		t.Fatalf("SearchTweets() failed: %v", err)
+	}
+	if !reflect.DeepEqual(seen, []string{"1", "2"}) || found[0].Text != "TWEET 1 #GOLANG" {
+		t.Errorf("OnTweet not applied to search results: seen %v, text %q", seen, found[0].Text)
 	}
 
-	if len(tweets) == 0 {
-		t.Error("No tweets returned for active user")
+	seen = nil
+	tweets, errs := client.GetTweets([]string{"3", "4"})
+	if len(errs) != 0 {
+		t.Fatalf("GetTweets() failed: %v", errs)
 	}
+	sort.Strings(seen)
+	if !reflect.DeepEqual(seen, []string{"3", "4"}) || tweets["3"].Text != "TWEET 3" {
+		t.Errorf("OnTweet not applied to looked up tweets: seen %v, text %q", seen, tweets["3"].Text)
+	}
+}
 
-	// Test first tweet structure
-	tweet := tweets[0]
+func TestClose(t *testing.T) {
+	client := NewClient()
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	// Second call must not panic
+	if err := client.Close(); err != nil {
+		t.Fatalf("second Close() failed: %v", err)
+	}
+
+	select {
+	case <-client.done:
+	default:
+		t.Error("done channel is not closed")
+	}
+}
+
+func TestSyndicationFallback(t *testing.T) {
+	syndicationPage := `<html><script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"timeline":{"entries":[
+{"type":"tweet","entry_id":"tweet-300","content":{"tweet":{"id_str":"300","full_text":"From #syndication","created_at":"Wed Jan 03 00:00:00 +0000 2024","favorite_count":7,"entities":{"hashtags":[{"text":"syndication"}]},"user":{"id_str":"42","screen_name":"test"}}}}
+]}}}}</script></html>`
+
+	client := newTestClient(http.StatusForbidden, `{"errors":[{"message":"Forbidden"}]}`)
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, SyndicationTimelinePath) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(syndicationPage)),
+				Request:    req,
+			}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	if _, err := client.GetUserTweets("42"); err == nil || !IsAccessBlocked(err) {
+		t.Fatalf("Expected access blocked error without fallback, got %v", err)
+	}
 
-	// Basic fields
-	if tweet.ID == "" {
-		t.Error("Tweet ID is empty")
+	WithSyndicationFallback()(client)
+	tweets, err := client.GetUserTweets("42")
+	if err != nil {
+		t.Fatalf("GetUserTweets() with fallback failed: %v", err)
 	}
-	if tweet.Text == "" {
-		t.Error("Tweet text is empty")
+	if len(tweets) != 1 {
+		t.Fatalf("Expected 1 tweet, got %d", len(tweets))
 	}
-	if tweet.CreatedAt == "" {
-		t.Error("Tweet CreatedAt is empty")
+
+	tweet := tweets[0]
+	if tweet.ID != "300" || tweet.Username != "test" || tweet.UserID != "42" || tweet.Likes != 7 {
+		t.Errorf("Unexpected tweet: %+v", tweet)
 	}
-	if tweet.Username == "" {
-		t.Error("Tweet Username is empty")
+	if tweet.PermanentURL != "https://x.com/test/status/300" {
+		t.Errorf("Unexpected permanent URL: %s", tweet.PermanentURL)
 	}
-	if tweet.UserID == "" {
-		t.Error("Tweet UserID is empty")
+	if !strings.Contains(tweet.HTML, "https://x.com/hashtag/syndication") {
+		t.Errorf("Hashtag not linked in HTML: %s", tweet.HTML)
+	}
+}
+
+func TestSyndicationToken(t *testing.T) {
+	token := syndicationToken("1234567890123456789")
+	if token == "" || strings.ContainsAny(token, "0.") {
+		t.Errorf("Invalid syndication token: %q", token)
 	}
+}
 
-	// Check PermanentURL format
-	if tweet.PermanentURL != "" {
-		expectedPrefix := fmt.Sprintf("https://x.com/%s/status/", tweet.Username)
-		if !strings.HasPrefix(tweet.PermanentURL, expectedPrefix) {
-			t.Errorf("PermanentURL format incorrect: %s", tweet.PermanentURL)
+func TestNitterFallback(t *testing.T) {
+	rss := `<?xml version="1.0" encoding="UTF-8"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/" version="2.0"><channel>
+<item><title>Hello from #nitter</title><dc:creator>@test</dc:creator><description><![CDATA[<p>Hello</p><img src="https://nitter.example/pic/media%2FAbc.jpg" />]]></description><pubDate>Thu, 04 Jan 2024 00:00:00 GMT</pubDate><link>https://nitter.example/test/status/400#m</link></item>
+<item><title>RT by @test: Original text</title><dc:creator>@other</dc:creator><description></description><pubDate>Thu, 04 Jan 2024 00:00:00 GMT</pubDate><link>https://nitter.example/other/status/500#m</link></item>
+</channel></rss>`
+
+	client := newTestClient(http.StatusForbidden, `{"errors":[{"message":"Forbidden"}]}`)
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Host == "nitter.example" && req.URL.Path == "/i/user/42":
+			return &http.Response{
+				StatusCode: http.StatusFound,
+				Header:     http.Header{"Location": []string{"/test"}},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		case req.URL.Host == "nitter.example" && req.URL.Path == "/test":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		case req.URL.Host == "nitter.example" && req.URL.Path == "/test/rss":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(rss)), Request: req}, nil
 		}
-		if !strings.HasSuffix(tweet.PermanentURL, tweet.ID) {
-			t.Errorf("PermanentURL doesn't end with tweet ID: %s", tweet.PermanentURL)
+		return graphQLTransport.RoundTrip(req)
+	})
+	WithNitterFallback("https://nitter.example/")(client)
+
+	tweets, err := client.GetUserTweets("42")
+	if err != nil {
+		t.Fatalf("GetUserTweets() with Nitter fallback failed: %v", err)
+	}
+	if len(tweets) != 2 {
+		t.Fatalf("Expected 2 tweets, got %d", len(tweets))
+	}
+
+	if tweets[0].ID != "400" || tweets[0].Username != "test" || tweets[0].PermanentURL != "https://x.com/test/status/400" {
+		t.Errorf("Unexpected tweet: %+v", tweets[0])
+	}
+	if len(tweets[0].Images) != 1 || tweets[0].Images[0] != "https://pbs.twimg.com/media/Abc.jpg" {
+		t.Errorf("Unexpected images: %v", tweets[0].Images)
+	}
+	if len(tweets[0].Hashtags) != 1 || tweets[0].Hashtags[0] != "nitter" {
+		t.Errorf("Unexpected hashtags: %v", tweets[0].Hashtags)
+	}
+	if !tweets[1].IsRetweet || tweets[1].Text != "Original text" || tweets[1].Username != "other" {
+		t.Errorf("Retweet parsed incorrectly: %+v", tweets[1])
+	}
+}
+
+func TestQueryIDDiscovery(t *testing.T) {
+	webPage := `<html><script src="https://abs.twimg.com/responsive-web/client-web/main.abc123.js"></script></html>`
+	mainScript := `e.exports={queryId:"NEWQUERYID",operationName:"UserTweets",operationType:"query",metadata:{featureSwitches:["payments_enabled","brand_new_feature"],fieldToggles:[]}}`
+
+	var requestedPath string
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Host == "x.com":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(webPage)), Request: req}, nil
+		case req.URL.Host == "abs.twimg.com":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(mainScript)), Request: req}, nil
+		case strings.HasPrefix(req.URL.Path, "/graphql/"):
+			requestedPath = req.URL.Path
+			if !strings.Contains(req.URL.Query().Get("features"), `"brand_new_feature":false`) {
+				t.Errorf("Discovered feature switch not added: %s", req.URL.Query().Get("features"))
+			}
 		}
+		return graphQLTransport.RoundTrip(req)
+	})
+	WithQueryIDDiscovery(time.Hour)(client)
+
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
 	}
+	if requestedPath != "/graphql/NEWQUERYID/UserTweets" {
+		t.Errorf("Discovered query ID not used: %s", requestedPath)
+	}
+}
 
-	// HTML should be generated
-	if tweet.HTML == "" {
-		t.Error("HTML content is empty")
+func TestQueryIDDiscoveryFailure(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := newTestClient(http.StatusOK, testTimelineJSON, WithClock(clock))
+	defer client.Close()
+	graphQLTransport := client.httpClient.Transport
+	webRequests := 0
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "x.com" {
+			webRequests++
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+	WithQueryIDDiscovery(time.Hour)(client)
+
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+	if webRequests == 0 {
+		t.Fatal("Discovery was not attempted")
 	}
+	attempted := webRequests
 
-	// Statistics should be non-negative
-	if tweet.Likes < 0 {
-		t.Error("Likes count is negative")
+	// Failed discovery is not repeated within TTL
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("second GetUserTweets() failed: %v", err)
 	}
-	if tweet.Retweets < 0 {
-		t.Error("Retweets count is negative")
+	if webRequests != attempted {
+		t.Errorf("Failed discovery refetched within TTL: %d requests, expected %d", webRequests, attempted)
 	}
-	if tweet.Replies < 0 {
-		t.Error("Replies count is negative")
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("third GetUserTweets() failed: %v", err)
+	}
+	if webRequests == attempted {
+		t.Error("Discovery was not retried after TTL")
 	}
 }
 
-func TestGetUserTweets_InvalidUserID(t *testing.T) {
-	client := NewClient()
+func TestFeatureAutoSync(t *testing.T) {
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	graphQLTransport := client.httpClient.Transport
+	attempts := 0
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			attempts++
+			features := req.URL.Query().Get("features")
+			if !strings.Contains(features, `"new_required_feature":false`) {
+				body := `{"errors":[{"message":"The following features cannot be null: new_required_feature","code":336}]}`
+				return &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+			}
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
 
-	tweets, err := client.GetUserTweets(InvalidUserID)
-	// The API might not always return an error for invalid ID,
-	// it might just return empty results
+	tweets, err := client.GetUserTweets("42")
 	if err != nil {
-		t.Logf("Got expected error for invalid user ID: %v", err)
+		t.Fatalf("GetUserTweets() failed: %v", err)
 	}
-
 	if len(tweets) == 0 {
-		t.Log("Got empty tweets for invalid user ID (expected)")
-	} else {
-		t.Logf("Got %d tweets for invalid user ID", len(tweets))
+		t.Error("No tweets returned after feature sync")
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	// Learned features are reused by subsequent calls
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("second GetUserTweets() failed: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Learned feature not reused, attempts: %d", attempts)
 	}
 }
 
-func TestGetUserID_ValidUsername(t *testing.T) {
-	client := NewClient()
+func TestLegacyAPIFallback(t *testing.T) {
+	legacyTimeline := `[{"id_str":"600","full_text":"RT @orig: Legacy text","created_at":"Fri Jan 05 00:00:00 +0000 2024","user":{"id_str":"42","screen_name":"test"},
+"retweeted_status":{"id_str":"599","full_text":"Legacy text","created_at":"Fri Jan 05 00:00:00 +0000 2024","favorite_count":3,"user":{"id_str":"7","screen_name":"orig"}}}]`
 
-	userID, err := client.GetUserID(TestUsername)
+	client := newTestClient(http.StatusForbidden, `{"errors":[{"message":"Forbidden"}]}`)
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == LegacyUserTimelinePath {
+			if req.Header.Get("Authorization") != "Bearer "+AndroidBearerToken {
+				t.Errorf("Android bearer token not used")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(legacyTimeline)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+	WithLegacyAPIFallback()(client)
+
+	tweets, err := client.GetUserTweets("42")
 	if err != nil {
-		t.Fatalf("GetUserID() failed for valid username: %v", err)
+		t.Fatalf("GetUserTweets() with legacy fallback failed: %v", err)
+	}
+	if len(tweets) != 1 {
+		t.Fatalf("Expected 1 tweet, got %d", len(tweets))
 	}
+	if !tweets[0].IsRetweet || tweets[0].ID != "599" || tweets[0].Username != "orig" || tweets[0].Likes != 3 {
+		t.Errorf("Retweet parsed incorrectly: %+v", tweets[0])
+	}
+}
+
+func TestDNSOverHTTPS(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "resolved")
+	}))
+	defer target.Close()
+
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "api.example.test" {
+			t.Errorf("Unexpected DoH query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/dns-json")
+		fmt.Fprint(w, `{"Status":0,"Answer":[{"name":"api.example.test","type":1,"TTL":60,"data":"127.0.0.1"}]}`)
+	}))
+	defer doh.Close()
+
+	client := NewClient(WithDNSOverHTTPS(doh.URL))
+	defer client.Close()
 
-	if userID == "" {
-		t.Error("UserID is empty")
+	_, port, _ := strings.Cut(strings.TrimPrefix(target.URL, "http://"), ":")
+	resp, err := client.httpClient.Get("http://api.example.test:" + port)
+	if err != nil {
+		t.Fatalf("Request via DoH resolver failed: %v", err)
 	}
+	defer resp.Body.Close()
 
-	// Should return the expected user ID
-	if userID != TestUserID {
-		t.Errorf("Expected user ID %s, got %s", TestUserID, userID)
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "resolved" {
+		t.Errorf("Unexpected response: %s", body)
 	}
 }
 
-func TestGetUserID_InvalidUsername(t *testing.T) {
-	client := NewClient()
+func TestDNSOverHTTPSDualStack(t *testing.T) {
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/dns-json")
+		switch r.URL.Query().Get("type") {
+		case "1":
+			fmt.Fprint(w, `{"Status":0,"Answer":[{"type":1,"TTL":60,"data":"192.0.2.1"},{"type":1,"TTL":60,"data":"192.0.2.2"}]}`)
+		case "28":
+			fmt.Fprint(w, `{"Status":0,"Answer":[{"type":28,"TTL":30,"data":"2001:db8::1"}]}`)
+		}
+	}))
+	defer doh.Close()
 
-	userID, err := client.GetUserID(InvalidUsername)
-	if err == nil {
-		t.Error("Expected error for invalid username")
+	resolver := newDoHResolver(doh.URL)
+	addrs, err := resolver.lookup(context.Background(), "api.example.test")
+	if err != nil {
+		t.Fatalf("lookup() failed: %v", err)
+	}
+	if len(addrs) != 3 {
+		t.Fatalf("Expected addresses of both families, got %v", addrs)
+	}
+	if ttl := time.Until(resolver.cache["api.example.test"].expires); ttl > 30*time.Second {
+		t.Errorf("Expected minimal TTL of both families, got %s", ttl)
 	}
 
-	if userID != "" {
-		t.Error("Should return empty user ID for invalid username")
+	if ordered := orderAddrs(addrs, true); !reflect.DeepEqual(ordered, []string{"2001:db8::1", "192.0.2.1", "192.0.2.2"}) {
+		t.Errorf("Unexpected IPv6-first order: %v", ordered)
+	}
+	if ordered := orderAddrs(addrs, false); !reflect.DeepEqual(ordered, []string{"192.0.2.1", "2001:db8::1", "192.0.2.2"}) {
+		t.Errorf("Unexpected IPv4-first order: %v", ordered)
 	}
 }
 
-func TestIntegration_FullWorkflow(t *testing.T) {
-	client := NewClient()
+func TestWithLocalAddr(t *testing.T) {
+	var remoteAddr string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+	}))
+	defer server.Close()
 
-	// Use Twitter's official account for diverse tweets
-	tweets, err := client.GetUserTweets(TestUserID2)
+	client := NewClient(WithLocalAddr("127.0.0.1"), WithPreferIPv6())
+	defer client.Close()
+
+	resp, err := client.httpClient.Get(server.URL)
 	if err != nil {
-		t.Fatalf("GetUserTweets() failed: %v", err)
-	}
-	if len(tweets) == 0 {
-		t.Fatal("No tweets returned")
+		t.Fatalf("Request from local address failed: %v", err)
 	}
+	resp.Body.Close()
 
-	foundTweetWithImages := false
-	foundTweetWithHashtags := false
-	foundTweetWithURLs := false
-	foundTweetWithMentions := false
+	if host, _, _ := net.SplitHostPort(remoteAddr); host != "127.0.0.1" {
+		t.Errorf("Expected request from 127.0.0.1, got %s", remoteAddr)
+	}
 
-	foundPinned := false
-	foundRetweet := false
-	foundReply := false
-	foundQuoted := false
+	unbound := NewClient(WithLocalAddr("no-such-interface"))
+	defer unbound.Close()
+	if unbound.dialer.LocalAddr != nil {
+		t.Errorf("Unknown local address should be ignored, got %v", unbound.dialer.LocalAddr)
+	}
+}
 
-	numericRegex := regexp.MustCompile(`^\d+$`)
+func TestIPv6Fallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
 
-	for i, tweet := range tweets {
-		if tweet.ID == "" {
-			t.Errorf("Tweet %d has empty ID", i)
-		}
-		if tweet.Text == "" {
-			t.Errorf("Tweet %d has empty text", i)
-		}
-		if tweet.Username == "" {
-			t.Errorf("Tweet %d has empty username", i)
-		}
-		if tweet.UserID != TestUserID2 {
-			t.Errorf("Tweet %d has wrong user ID: expected %s, got %s", i, TestUserID2, tweet.UserID)
+	// 100::1 belongs to discard-only prefix and is never reachable
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/dns-json")
+		switch r.URL.Query().Get("type") {
+		case "1":
+			fmt.Fprint(w, `{"Status":0,"Answer":[{"type":1,"TTL":60,"data":"127.0.0.1"}]}`)
+		case "28":
+			fmt.Fprint(w, `{"Status":0,"Answer":[{"type":28,"TTL":60,"data":"100::1"}]}`)
 		}
-		if tweet.PermanentURL == "" {
-			t.Errorf("Tweet %d has empty permanent URL", i)
-		}
-		if tweet.HTML == "" {
-			t.Errorf("Tweet %d has empty HTML", i)
+	}))
+	defer doh.Close()
+
+	const fallbackDelay = 200 * time.Millisecond
+	var mu sync.Mutex
+	var dialed []string
+	client := NewClient(
+		WithDNSOverHTTPS(doh.URL),
+		WithPreferIPv6(),
+		WithDialTimeout(10*time.Second, fallbackDelay),
+		WithDialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, address)
+			mu.Unlock()
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		}),
+	)
+	defer client.Close()
+	// Warm up DoH cache so that only dialing is timed
+	client.resolver.lookup(context.Background(), "api.example.test")
+
+	start := time.Now()
+	resp, err := client.httpClient.Get("http://api.example.test:" + port)
+	if err != nil {
+		t.Fatalf("Request with IPv6 fallback failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > fallbackDelay+time.Second {
+		t.Errorf("Fallback to IPv4 took %s, expected about %s", elapsed, fallbackDelay)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) != 2 || dialed[0] != net.JoinHostPort("100::1", port) || dialed[1] != net.JoinHostPort("127.0.0.1", port) {
+		t.Errorf("Expected IPv6 dial before IPv4 one, got %v", dialed)
+	}
+}
+
+func TestWithDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var dialed []string
+	client := NewClient(
+		WithDialTimeout(5*time.Second, -1),
+		WithDialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialed = append(dialed, address)
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		}),
+	)
+	defer client.Close()
+
+	if client.dialer.Timeout != 5*time.Second || client.dialer.FallbackDelay != -1 {
+		t.Errorf("Dialer not tuned: timeout %v, fallback delay %v", client.dialer.Timeout, client.dialer.FallbackDelay)
+	}
+
+	resp, err := client.httpClient.Get("http://api.example.test/")
+	if err != nil {
+		t.Fatalf("Request via custom dialer failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(dialed) != 1 || dialed[0] != "api.example.test:80" {
+		t.Errorf("Expected custom dial of api.example.test:80, got %v", dialed)
+	}
+}
+
+func TestChallengeDetection(t *testing.T) {
+	challengePage := `<!DOCTYPE html><html><head><title>Just a moment...</title></head></html>`
+
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	graphQLTransport := client.httpClient.Transport
+	challenged := true
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") && challenged {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Header:     http.Header{"Content-Type": []string{"text/html; charset=UTF-8"}},
+				Body:       io.NopCloser(strings.NewReader(challengePage)),
+				Request:    req,
+			}, nil
 		}
+		return graphQLTransport.RoundTrip(req)
+	})
 
-		// --- Structure tests ---
-		// URL parsing
-		if len(tweet.URLs) > 0 {
-			foundTweetWithURLs = true
-			for _, url := range tweet.URLs {
-				if url.Short == "" || url.Display == "" {
-					t.Error("URL structure incomplete")
-				}
+	_, err := client.GetUserTweets("42")
+	if !errors.Is(err, ErrChallenge) {
+		t.Fatalf("Expected ErrChallenge, got %v", err)
+	}
+	var challengeErr *ChallengeError
+	if !errors.As(err, &challengeErr) || !strings.Contains(challengeErr.Snippet, "Just a moment") {
+		t.Errorf("Challenge snippet not provided: %v", err)
+	}
+
+	solverCalls := 0
+	WithChallengeSolver(func(*ChallengeError) error {
+		solverCalls++
+		challenged = false
+		return nil
+	})(client)
+
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("GetUserTweets() after solving challenge failed: %v", err)
+	}
+	if solverCalls != 1 {
+		t.Errorf("Expected 1 solver call, got %d", solverCalls)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+
+	var order []string
+	client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			order = append(order, "first")
+			req.Header.Set("X-Custom", "value")
+			return next.RoundTrip(req)
+		})
+	}, func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			order = append(order, "second")
+			if req.Header.Get("X-Custom") != "value" {
+				t.Error("Header from previous middleware not set")
 			}
-		}
-		// Hashtag extraction
-		if len(tweet.Hashtags) > 0 {
-			foundTweetWithHashtags = true
-			for _, hashtag := range tweet.Hashtags {
-				if hashtag == "" {
-					t.Error("Empty hashtag found")
-				}
-				if strings.HasPrefix(hashtag, "#") {
-					t.Error("Hashtag should not include # symbol")
+			return next.RoundTrip(req)
+		})
+	})
+
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+	// Guest token and timeline requests
+	if strings.Join(order, ",") != "first,second,first,second" {
+		t.Errorf("Unexpected middleware order: %v", order)
+	}
+}
+
+func TestBrowserHeaders(t *testing.T) {
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	client.Use(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if !strings.Contains(UserAgent, "Chrome/") {
+				return next.RoundTrip(req)
+			}
+			version := regexp.MustCompile(`Chrome/(\d+)`).FindStringSubmatch(UserAgent)[1]
+			if !strings.Contains(req.Header.Get("Sec-Ch-Ua"), `"Google Chrome";v="`+version+`"`) {
+				t.Errorf("sec-ch-ua does not match User-Agent: %s", req.Header.Get("Sec-Ch-Ua"))
+			}
+			for _, header := range []string{"Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest", "Sec-Ch-Ua-Platform"} {
+				if req.Header.Get(header) == "" {
+					t.Errorf("Header %s not set", header)
 				}
 			}
+			return next.RoundTrip(req)
+		})
+	})
+
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+}
+
+func TestWithHeaderOrder(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	defer listener.Close()
+
+	// Raw server reports header names in the order they arrived on the wire
+	heads := make(chan []string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
 		}
-		// Mention extraction
-		if len(tweet.Mentions) > 0 {
-			foundTweetWithMentions = true
-			for _, mention := range tweet.Mentions {
-				if mention == "" {
-					t.Error("Empty mention found")
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			var names []string
+			var length int
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
 				}
-				if strings.HasPrefix(mention, "@") {
-					t.Error("Mention should not include @ symbol")
+				line = strings.TrimRight(line, "\r\n")
+				if line == "" {
+					break
 				}
-			}
-		}
-		// Image extraction
-		if len(tweet.Images) > 0 {
-			foundTweetWithImages = true
-			for _, image := range tweet.Images {
-				if !strings.HasPrefix(image, "https://") {
-					t.Error("Image URL should be HTTPS")
+				name, value, _ := strings.Cut(line, ":")
+				if name == "Content-Length" {
+					length, _ = strconv.Atoi(strings.TrimSpace(value))
 				}
+				names = append(names, name)
 			}
+			io.CopyN(io.Discard, reader, int64(length))
+			heads <- names
+			fmt.Fprint(conn, "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok")
 		}
+	}()
 
-		// Tweet type flags consistency
-		typeCount := 0
-		if tweet.IsRetweet {
-			typeCount++
-		}
-		if tweet.IsReply {
-			typeCount++
+	client := NewClient(WithHeaderOrder())
+	defer client.Close()
+
+	// The second request reuses the connection after a request with body
+	for _, body := range []string{"a=1", ""} {
+		req, _ := http.NewRequest("POST", "http://"+listener.Addr().String()+"/", strings.NewReader(body))
+		setBrowserHeaders(req)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("X-Guest-Token", "1")
+		req.Header.Set("X-Custom", "1")
+		resp, err := client.httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request with header order failed: %v", err)
 		}
-		if tweet.IsQuoted {
-			typeCount++
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		names := <-heads
+		var known []string
+		for _, name := range names {
+			if _, ok := headerRank[strings.ToLower(name)]; ok {
+				known = append(known, name)
+			}
 		}
-		// UserID numeric
-		matched := numericRegex.Match([]byte(tweet.UserID))
-		if !matched {
-			t.Errorf("UserID should be numeric: %s", tweet.UserID)
+		sorted := sort.SliceIsSorted(known, func(i, j int) bool {
+			return headerRank[strings.ToLower(known[i])] < headerRank[strings.ToLower(known[j])]
+		})
+		if !sorted || names[0] != "Host" || names[len(names)-1] != "X-Custom" {
+			t.Errorf("Headers not in browser order: %v", names)
 		}
-		// Tweet ID numeric
-		matched = numericRegex.Match([]byte(tweet.ID))
-		if !matched {
-			t.Errorf("Tweet ID should be numeric: %s", tweet.ID)
+	}
+}
+
+func TestProcessTweetResult_HTML(t *testing.T) {
+	tweetResult := &TweetResult{RestID: "1"}
+	tweetResult.Legacy.FullText = "#Go and #golang by @gopher, not #other"
+	tweetResult.Legacy.Entities.Hashtags = []HashtagEntity{{Text: "go"}, {Text: "golang"}}
+
+	processTweetResult(tweetResult, renderOptions{})
+
+	for _, expected := range []string{
+		`<a href="https://x.com/hashtag/go" target="_blank">#go</a>`,
+		`<a href="https://x.com/hashtag/golang" target="_blank">#golang</a>`,
+		`<a href="https://x.com/gopher" target="_blank">@gopher</a>`,
+		`not #other`,
+	} {
+		if !strings.Contains(tweetResult.HTML, expected) {
+			t.Errorf("HTML does not contain %q: %s", expected, tweetResult.HTML)
 		}
+	}
+}
+
+func TestDecodeTimeline(t *testing.T) {
+	var timelineResp TimelineResponse
+	if err := json.Unmarshal([]byte(testTimelineJSON), &timelineResp); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	expected := collectTimeline(&timelineResp)
+
+	collector := &timelineCollector{}
+	if err := decodeTimeline(strings.NewReader(testTimelineJSON), collector.addEntry); err != nil {
+		t.Fatalf("decodeTimeline() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(collector.tweets(), expected.tweets()) {
+		t.Errorf("Streaming decode differs from full decode:\n%+v\n%+v", collector.tweets(), expected.tweets())
+	}
+	if collector.topCursor != expected.topCursor || collector.bottomCursor != expected.bottomCursor {
+		t.Errorf("Cursors differ: %q/%q vs %q/%q", collector.topCursor, collector.bottomCursor, expected.topCursor, expected.bottomCursor)
+	}
+
+	// Instruction type after the entry and unrelated keys
+	reordered := `{"errors":[],"data":{"user":{"result":{"__typename":"User","timeline":{"timeline":{"instructions":[
+{"entry":{"entryId":"tweet-1","content":{"itemContent":{"tweet_results":{"result":{"rest_id":"1","legacy":{"full_text":"pinned"}}}}}},"type":"TimelinePinEntry"}]}}}}}}`
+	collector = &timelineCollector{}
+	if err := decodeTimeline(strings.NewReader(reordered), collector.addEntry); err != nil {
+		t.Fatalf("decodeTimeline() failed on reordered keys: %v", err)
+	}
+	tweets := collector.tweets()
+	if len(tweets) != 1 || !tweets[0].IsPinned {
+		t.Errorf("Pinned tweet not decoded: %+v", tweets)
+	}
+
+	// Response without timeline
+	collector = &timelineCollector{}
+	if err := decodeTimeline(strings.NewReader(`{"data":{"user":{}}}`), collector.addEntry); err != nil {
+		t.Errorf("decodeTimeline() failed on empty response: %v", err)
+	}
+}
 
-		// --- Type detection ---
-		if tweet.IsPinned {
-			foundPinned = true
-			t.Logf("Found pinned tweet: %s", tweet.ID)
+// timelinePageJSON builds timeline response with tweets of the given IDs and bottom cursor
+func timelinePageJSON(bottomCursor string, ids ...string) string {
+	var entries []string
+	for _, id := range ids {
+		entries = append(entries, fmt.Sprintf(`{"entryId":"tweet-%s","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"rest_id":"%s","core":{"user_results":{"result":{"core":{"screen_name":"test"}}}},"legacy":{"full_text":"Tweet %s","user_id_str":"42"}}}}}}`, id, id, id))
+	}
+	entries = append(entries, fmt.Sprintf(`{"entryId":"cursor-bottom-%s","content":{"entryType":"TimelineTimelineCursor","value":"%s","cursorType":"Bottom"}}`, bottomCursor, bottomCursor))
+	return `{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[` +
+		strings.Join(entries, ",") + `]}]}}}}}}`
+}
+
+func TestGetAllUserTweets(t *testing.T) {
+	pages := map[string]string{
+		"":   timelinePageJSON("c1", "10", "9"),
+		"c1": timelinePageJSON("c2", "9", "8", "7"),
+		"c2": timelinePageJSON("c3", "6"),
+		"c3": timelinePageJSON("c3"),
+	}
+
+	client := newTestClient(http.StatusOK, "")
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			var variables map[string]any
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			cursor, _ := variables["cursor"].(string)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(pages[cursor])), Request: req}, nil
 		}
-		if tweet.IsRetweet {
-			foundRetweet = true
-			t.Logf("Found retweet: %s", tweet.ID)
+		return graphQLTransport.RoundTrip(req)
+	})
+	WithPageParallelism(3)(client)
+	WithPageDelay(0, 0)(client)
+
+	pageCount := 0
+	client.OnPage(func(TimelinePage) { pageCount++ })
+
+	tweets, err := client.GetAllUserTweets("42", 0)
+	if err != nil {
+		t.Fatalf("GetAllUserTweets() failed: %v", err)
+	}
+	var ids []string
+	for _, tweet := range tweets {
+		ids = append(ids, tweet.ID)
+	}
+	if strings.Join(ids, ",") != "10,9,8,7,6" {
+		t.Errorf("Unexpected tweets order: %v", ids)
+	}
+	if pageCount != 4 {
+		t.Errorf("Expected 4 pages, got %d", pageCount)
+	}
+
+	tweets, err = client.GetAllUserTweets("42", 2)
+	if err != nil {
+		t.Fatalf("GetAllUserTweets() with page limit failed: %v", err)
+	}
+	if len(tweets) != 4 {
+		t.Errorf("Expected 4 tweets from 2 pages, got %d", len(tweets))
+	}
+
+	// Retweet keeps position of its own entry as in GetUserTweets, not of the original tweet
+	pages["c1"] = strings.Replace(pages["c1"], `"legacy":{"full_text":"Tweet 8","user_id_str":"42"}`,
+		`"legacy":{"full_text":"RT","user_id_str":"42","retweeted_status_id_str":"3"},"retweeted_status_result":{"result":{"rest_id":"3","legacy":{"full_text":"Tweet 3"}}}`, 1)
+	WithNewestFirst()(client)
+	tweets, err = client.GetAllUserTweets("42", 0)
+	if err != nil {
+		t.Fatalf("GetAllUserTweets() newest first failed: %v", err)
+	}
+	if ids := tweetIDs(tweets); strings.Join(ids, ",") != "10,9,3,7,6" {
+		t.Errorf("Unexpected newest first order: %v", ids)
+	}
+}
+
+func TestGetTimelines(t *testing.T) {
+	client := newTestClient(http.StatusOK, "")
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			var variables map[string]any
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			userID, _ := variables["userId"].(string)
+			if userID == "3" {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("not found")), Request: req}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(timelinePageJSON("c", userID+"00"))), Request: req}, nil
 		}
-		if tweet.IsReply {
-			foundReply = true
-			t.Logf("Found reply: %s", tweet.ID)
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	results, errs := client.GetTimelines([]string{"1", "2", "3", "4"}, 2)
+	if len(results) != 3 || len(errs) != 1 {
+		t.Fatalf("Expected 3 results and 1 error, got %d and %d", len(results), len(errs))
+	}
+	if errs["3"] == nil {
+		t.Error("Expected error for user 3")
+	}
+	if tweets := results["2"]; len(tweets) != 1 || tweets[0].ID != "200" {
+		t.Errorf("Unexpected tweets for user 2: %+v", tweets)
+	}
+}
+
+// loadTimelineFixture reads recorded timeline response from twittertest fixtures
+func loadTimelineFixture(tb testing.TB) *TimelineResponse {
+	tb.Helper()
+	data, err := os.ReadFile("twittertest/testdata/user_tweets.json")
+	if err != nil {
+		tb.Fatalf("Failed to read fixture: %v", err)
+	}
+	var timelineResp TimelineResponse
+	if err := json.Unmarshal(data, &timelineResp); err != nil {
+		tb.Fatalf("Failed to decode fixture: %v", err)
+	}
+	return &timelineResp
+}
+
+// fixtureTweetResults returns all tweet results from the timeline fixture
+func fixtureTweetResults(tb testing.TB) []TweetResult {
+	var results []TweetResult
+	for _, instruction := range loadTimelineFixture(tb).Data.User.Result.Timeline.Timeline.Instructions {
+		for _, entry := range instruction.Entries {
+			if entry.Content.ItemContent != nil {
+				results = append(results, entry.Content.ItemContent.TweetResults.Result)
+			}
 		}
-		if tweet.IsQuoted {
-			foundQuoted = true
-			t.Logf("Found quoted tweet: %s", tweet.ID)
+	}
+	return results
+}
+
+func TestTimelineFixture(t *testing.T) {
+	tweets := extractTweetsFromTimeline(loadTimelineFixture(t))
+	if len(tweets) != 43 {
+		t.Fatalf("Expected 43 tweets in fixture, got %d", len(tweets))
+	}
+	if !tweets[0].IsPinned {
+		t.Error("First tweet should be pinned")
+	}
+}
+
+func BenchmarkProcessTweetResult(b *testing.B) {
+	results := fixtureTweetResults(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tweetResult := results[i%len(results)]
+		processTweetResult(&tweetResult, renderOptions{})
+	}
+}
+
+func BenchmarkConvertTweetResult(b *testing.B) {
+	results := fixtureTweetResults(b)
+	for i := range results {
+		processTweetResult(&results[i], renderOptions{})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		convertTweetResult(&results[i%len(results)])
+	}
+}
+
+func BenchmarkExtractTweetsFromTimeline(b *testing.B) {
+	timelineResp := loadTimelineFixture(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		extractTweetsFromTimeline(timelineResp)
+	}
+}
+
+func BenchmarkDecodeTimeline(b *testing.B) {
+	data, err := os.ReadFile("twittertest/testdata/user_tweets.json")
+	if err != nil {
+		b.Fatalf("Failed to read fixture: %v", err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		collector := &timelineCollector{}
+		if err := decodeTimeline(bytes.NewReader(data), collector.addEntry); err != nil {
+			b.Fatal(err)
 		}
+		collector.tweets()
+	}
+}
+
+// raceEnabled is set when tests run with the race detector
+var raceEnabled bool
+
+func TestAllocationBudget(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not meaningful with race detector")
+	}
+	timelineResp := loadTimelineFixture(t)
+
+	// Budget for the 43-tweet fixture; update it deliberately when the pipeline changes
+	const maxAllocs = 1025
+	allocs := testing.AllocsPerRun(20, func() {
+		extractTweetsFromTimeline(timelineResp)
+	})
+	if allocs > maxAllocs {
+		t.Errorf("extractTweetsFromTimeline() allocations: %.0f, budget: %d", allocs, maxAllocs)
+	}
+}
+
+func TestConvertTweetResult_Mentions(t *testing.T) {
+	var tweetResult TweetResult
+	err := json.Unmarshal([]byte(`{"rest_id":"1","legacy":{"full_text":"@alice mail me at bob@example.com","entities":{
+"user_mentions":[{"screen_name":"alice","id_str":"11","name":"Alice"}]}}}`), &tweetResult)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
 	}
+	processTweetResult(&tweetResult, renderOptions{})
+	tweet := convertTweetResult(&tweetResult)
 
-	t.Logf("Found tweets with: images=%v, hashtags=%v, URLs=%v, mentions=%v",
-		foundTweetWithImages, foundTweetWithHashtags, foundTweetWithURLs, foundTweetWithMentions)
-	t.Logf("Tweet types found: pinned=%v, retweet=%v, reply=%v, quoted=%v",
-		foundPinned, foundRetweet, foundReply, foundQuoted)
+	if !reflect.DeepEqual(tweet.Mentions, []string{"alice"}) {
+		t.Errorf("Unexpected mentions: %v", tweet.Mentions)
+	}
+	expected := []Mention{{Username: "alice", UserID: "11", Name: "Alice"}}
+	if !reflect.DeepEqual(tweet.UserMentions, expected) {
+		t.Errorf("Unexpected user mentions: %+v", tweet.UserMentions)
+	}
+}
+
+func TestCashtags(t *testing.T) {
+	var tweetResult TweetResult
+	err := json.Unmarshal([]byte(`{"rest_id":"1","legacy":{"full_text":"Buying $TSLA and $BRK.B, not $5 or $AAPL.","entities":{
+"symbols":[{"text":"TSLA"},{"text":"BRK.B"}]}}}`), &tweetResult)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	processTweetResult(&tweetResult, renderOptions{})
+	tweet := convertTweetResult(&tweetResult)
+
+	if !reflect.DeepEqual(tweet.Cashtags, []string{"TSLA", "BRK.B"}) {
+		t.Errorf("Unexpected cashtags: %v", tweet.Cashtags)
+	}
+	for _, expected := range []string{
+		`<a href="https://x.com/search?q=%24TSLA&amp;src=cashtag_click" target="_blank">$TSLA</a>`,
+		`<a href="https://x.com/search?q=%24BRK.B&amp;src=cashtag_click" target="_blank">$BRK.B</a>`,
+		`not $5 or $AAPL.`,
+	} {
+		if !strings.Contains(tweet.HTML, expected) {
+			t.Errorf("HTML does not contain %q: %s", expected, tweet.HTML)
+		}
+	}
+}
+
+func TestDisplayText(t *testing.T) {
+	const data = `{"rest_id":"1","legacy":{"full_text":"Sunset https://t.co/link https://t.co/media","entities":{
+"urls":[{"url":"https://t.co/link","expanded_url":"https://example.com","display_url":"example.com"}],
+"media":[{"url":"https://t.co/media","media_url_https":"https://pbs.twimg.com/media/a.jpg","type":"photo"}]},
+"extended_entities":{"media":[{"url":"https://t.co/media","media_url_https":"https://pbs.twimg.com/media/a.jpg","type":"photo"}]}}}`
+
+	tests := []struct {
+		name string
+		opts renderOptions
+		text string
+	}{
+		{"Default", renderOptions{}, "Sunset https://t.co/link https://t.co/media"},
+		{"Trimmed", renderOptions{trimMediaLinks: true}, "Sunset https://t.co/link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tweetResult TweetResult
+			if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			processTweetResult(&tweetResult, tt.opts)
+			tweet := convertTweetResult(&tweetResult)
+
+			if tweet.Text != tt.text {
+				t.Errorf("Expected text %q, got %q", tt.text, tweet.Text)
+			}
+			if len(tweet.Images) != 1 {
+				t.Errorf("Expected 1 image, got %d", len(tweet.Images))
+			}
+			if tt.opts.trimMediaLinks && strings.Contains(tweet.HTML, "https://t.co/media") {
+				t.Errorf("HTML contains media link: %s", tweet.HTML)
+			}
+			if !strings.Contains(tweet.HTML, `<a href="https://example.com" target="_blank">example.com</a>`) {
+				t.Errorf("HTML does not contain link: %s", tweet.HTML)
+			}
+		})
+	}
+
+	client := NewClient(WithDisplayText())
+	defer client.Close()
+	if !client.render.trimMediaLinks {
+		t.Error("WithDisplayText did not enable media link trimming")
+	}
+}
+
+func TestUnescapedText(t *testing.T) {
+	const data = `{"rest_id":"1","legacy":{"full_text":"Tom &amp; Jerry &lt;3 @user"}}`
+
+	tests := []struct {
+		name string
+		opts renderOptions
+		text string
+	}{
+		{"Default", renderOptions{}, "Tom &amp; Jerry &lt;3 @user"},
+		{"Unescaped", renderOptions{unescapeText: true}, "Tom & Jerry <3 @user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tweetResult TweetResult
+			if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			processTweetResult(&tweetResult, tt.opts)
+			tweet := convertTweetResult(&tweetResult)
+
+			if tweet.Text != tt.text {
+				t.Errorf("Expected text %q, got %q", tt.text, tweet.Text)
+			}
+			expectedHTML := `Tom &amp; Jerry &lt;3 <a href="https://x.com/user" target="_blank">@user</a>`
+			if tweet.HTML != expectedHTML {
+				t.Errorf("Expected HTML %q, got %q", expectedHTML, tweet.HTML)
+			}
+		})
+	}
+}
+
+func TestUnicodeEntities(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name: "Indices",
+			data: `{"rest_id":"1","legacy":{"full_text":"🎉 &amp; #日本語 #Привет @snake_case_ https://t.co/x","entities":{
+"hashtags":[{"text":"日本語","indices":[4,8]},{"text":"Привет","indices":[9,16]}],
+"user_mentions":[{"screen_name":"snake_case_","id_str":"7","name":"Snake","indices":[17,29]}],
+"urls":[{"url":"https://t.co/x","expanded_url":"https://example.com","display_url":"example.com","indices":[30,44]}]}}}`,
+			expected: []string{
+				`🎉 &amp; <a href="https://x.com/hashtag/%E6%97%A5%E6%9C%AC%E8%AA%9E" target="_blank">#日本語</a> `,
+				`<a href="https://x.com/hashtag/%D0%9F%D1%80%D0%B8%D0%B2%D0%B5%D1%82" target="_blank">#Привет</a> `,
+				`<a href="https://x.com/snake_case_" target="_blank">@snake_case_</a> `,
+				`<a href="https://example.com" target="_blank">example.com</a>`,
+			},
+		},
+		{
+			name: "WithoutIndices",
+			data: `{"rest_id":"1","legacy":{"full_text":"#日本語 and #Привет","entities":{
+"hashtags":[{"text":"日本語"},{"text":"Привет"}]}}}`,
+			expected: []string{
+				`<a href="https://x.com/hashtag/%E6%97%A5%E6%9C%AC%E8%AA%9E" target="_blank">#日本語</a> and `,
+				`<a href="https://x.com/hashtag/%D0%9F%D1%80%D0%B8%D0%B2%D0%B5%D1%82" target="_blank">#Привет</a>`,
+			},
+		},
+		{
+			name: "MismatchedIndices",
+			data: `{"rest_id":"1","legacy":{"full_text":"Hello #go","entities":{
+"hashtags":[{"text":"go","indices":[0,3]}]}}}`,
+			expected: []string{
+				`Hello <a href="https://x.com/hashtag/go" target="_blank">#go</a>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tweetResult TweetResult
+			if err := json.Unmarshal([]byte(tt.data), &tweetResult); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			processTweetResult(&tweetResult, renderOptions{})
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(tweetResult.HTML, expected) {
+					t.Errorf("HTML does not contain %q: %s", expected, tweetResult.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestLineBreaks(t *testing.T) {
+	const data = `{"rest_id":"1","legacy":{"full_text":"First line\nsecond line\n\n\nNext #go\n","entities":{
+"hashtags":[{"text":"go","indices":[30,33]}]}}}`
+	const link = `<a href="https://x.com/hashtag/go" target="_blank">#go</a>`
+
+	tests := []struct {
+		name string
+		mode LineBreakMode
+		html string
+	}{
+		{"BR", LineBreakBR, "First line<br>second line<br><br><br>Next " + link + "<br>"},
+		{"Paragraphs", LineBreakParagraphs, "<p>First line<br>second line</p><p>Next " + link + "</p>"},
+		{"None", LineBreakNone, "First line\nsecond line\n\n\nNext " + link + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tweetResult TweetResult
+			if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			processTweetResult(&tweetResult, renderOptions{lineBreaks: tt.mode})
+
+			if tweetResult.HTML != tt.html {
+				t.Errorf("Expected HTML %q, got %q", tt.html, tweetResult.HTML)
+			}
+		})
+	}
+}
+
+func TestTweetEntities(t *testing.T) {
+	const data = `{"rest_id":"1","legacy":{"full_text":"@bob 🎉 #go $TSLA https://t.co/x https://t.co/m","entities":{
+"hashtags":[{"text":"go","indices":[7,10]}],
+"symbols":[{"text":"TSLA","indices":[11,16]}],
+"urls":[{"url":"https://t.co/x","expanded_url":"https://example.com","display_url":"example.com","indices":[17,31]}],
+"user_mentions":[{"screen_name":"bob","id_str":"7","name":"Bob","indices":[0,4]}],
+"media":[{"url":"https://t.co/m","media_url_https":"https://pbs.twimg.com/media/a.jpg","type":"photo","indices":[32,46]}]},
+"extended_entities":{"media":[
+{"url":"https://t.co/m","media_url_https":"https://pbs.twimg.com/media/a.jpg","type":"photo","indices":[32,46]},
+{"url":"https://t.co/m","media_url_https":"https://pbs.twimg.com/media/b.jpg","type":"photo","indices":[32,46]}]}}}`
+
+	expected := []Entity{
+		{Type: EntityMention, Value: "bob", Start: 0, End: 4},
+		{Type: EntityHashtag, Value: "go", Start: 7, End: 10},
+		{Type: EntityCashtag, Value: "TSLA", Start: 11, End: 16},
+		{Type: EntityURL, Value: "https://example.com", Start: 17, End: 31},
+		{Type: EntityMedia, Value: "https://pbs.twimg.com/media/a.jpg", Start: 32, End: 46},
+	}
+
+	for _, opts := range []renderOptions{{}, {trimMediaLinks: true}} {
+		var tweetResult TweetResult
+		if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		processTweetResult(&tweetResult, opts)
+		tweet := convertTweetResult(&tweetResult)
+
+		want := expected
+		if opts.trimMediaLinks {
+			want = expected[:len(expected)-1]
+		}
+		if !reflect.DeepEqual(tweet.Entities, want) {
+			t.Errorf("Unexpected entities with %+v: %+v", opts, tweet.Entities)
+		}
+
+		runes := []rune(tweet.Text)
+		for _, entity := range tweet.Entities {
+			if entity.Type == EntityHashtag && string(runes[entity.Start:entity.End]) != "#go" {
+				t.Errorf("Hashtag offsets point to %q", string(runes[entity.Start:entity.End]))
+			}
+		}
+	}
+}
+
+func TestEscapedEntityIndices(t *testing.T) {
+	// Indices count escaped characters of API text, e.g. "&amp;" takes 5 runes
+	const data = `{"rest_id":"1","legacy":{"full_text":"Q&amp;A &lt;3 with @bob https://t.co/x","entities":{
+"urls":[{"url":"https://t.co/x","expanded_url":"https://example.com","display_url":"example.com","indices":[24,38]}],
+"user_mentions":[{"screen_name":"bob","id_str":"7","name":"Bob","indices":[19,23]}]}}}`
+
+	expectedHTML := `Q&amp;A &lt;3 with <a href="https://x.com/bob" target="_blank">@bob</a> ` +
+		`<a href="https://example.com" target="_blank">example.com</a>`
+
+	for _, opts := range []renderOptions{{}, {unescapeText: true}} {
+		var tweetResult TweetResult
+		if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		processTweetResult(&tweetResult, opts)
+		tweet := convertTweetResult(&tweetResult)
+
+		if tweet.HTML != expectedHTML {
+			t.Errorf("Unexpected HTML with %+v:\n%s", opts, tweet.HTML)
+		}
+
+		runes := []rune(tweet.Text)
+		expected := map[EntityType]string{EntityMention: "@bob", EntityURL: "https://t.co/x"}
+		if len(tweet.Entities) != len(expected) {
+			t.Fatalf("Unexpected entities with %+v: %+v", opts, tweet.Entities)
+		}
+		for _, entity := range tweet.Entities {
+			if text := string(runes[entity.Start:entity.End]); text != expected[entity.Type] {
+				t.Errorf("%s offsets with %+v point to %q in %q", entity.Type, opts, text, tweet.Text)
+			}
+		}
+	}
+}
+
+func TestPinnedPosition(t *testing.T) {
+	// Pinned tweet 8 is also a regular entry, pinned tweet 5 is older than the page
+	pinEntry := func(id string) string {
+		return `{"type":"TimelinePinEntry","entry":{"entryId":"tweet-` + id + `","content":{"itemContent":{"tweet_results":{"result":{"rest_id":"` + id + `","legacy":{"full_text":"Pinned"}}}}}}}`
+	}
+	page := timelinePageJSON("c1", "10", "9", "8", "7")
+
+	tests := []struct {
+		position PinnedPosition
+		pinned   string
+		ids      []string
+	}{
+		{PinnedFirst, "8", []string{"8", "10", "9", "7"}},
+		{PinnedChronological, "8", []string{"10", "9", "8", "7"}},
+		{PinnedExcluded, "8", []string{"10", "9", "8", "7"}},
+		{PinnedFirst, "5", []string{"5", "10", "9", "8", "7"}},
+		{PinnedChronological, "5", []string{"10", "9", "8", "7", "5"}},
+		{PinnedExcluded, "5", []string{"10", "9", "8", "7"}},
+	}
+
+	for _, tt := range tests {
+		data := strings.Replace(page, `"instructions":[`, `"instructions":[`+pinEntry(tt.pinned)+`,`, 1)
+		collector := &timelineCollector{pinnedPosition: tt.position}
+		if err := decodeTimeline(strings.NewReader(data), collector.addEntry); err != nil {
+			t.Fatalf("decodeTimeline() failed: %v", err)
+		}
+
+		var ids []string
+		for _, tweet := range collector.tweets() {
+			ids = append(ids, tweet.ID)
+			if tweet.IsPinned != (tweet.ID == tt.pinned && tt.position != PinnedExcluded) {
+				t.Errorf("Position %d: unexpected IsPinned %v of tweet %s", tt.position, tweet.IsPinned, tweet.ID)
+			}
+		}
+		if !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("Position %d, pinned %s: expected %v, got %v", tt.position, tt.pinned, tt.ids, ids)
+		}
+	}
+
+	if compareTweetIDs("999", "1000") >= 0 || compareTweetIDs("1001", "1000") <= 0 || compareTweetIDs("7", "7") != 0 {
+		t.Error("compareTweetIDs() compares IDs incorrectly")
+	}
+}
+
+func TestSortTweets(t *testing.T) {
+	tweets := []Tweet{{ID: "999"}, {ID: "1001"}, {ID: "1000"}, {ID: "1002"}}
+
+	SortTweets(tweets)
+	if ids := tweetIDs(tweets); !reflect.DeepEqual(ids, []string{"1002", "1001", "1000", "999"}) {
+		t.Errorf("SortTweets() order: %v", ids)
+	}
+
+	SortTweetsOldestFirst(tweets)
+	if ids := tweetIDs(tweets); !reflect.DeepEqual(ids, []string{"999", "1000", "1001", "1002"}) {
+		t.Errorf("SortTweetsOldestFirst() order: %v", ids)
+	}
+
+	// Conversation entries are grouped out of order, pinned tweet takes its position
+	data := strings.Replace(timelinePageJSON("c1", "10", "7", "9", "8"), `"instructions":[`,
+		`"instructions":[{"type":"TimelinePinEntry","entry":{"entryId":"tweet-5","content":{"itemContent":{"tweet_results":{"result":{"rest_id":"5","legacy":{"full_text":"Pinned"}}}}}}},`, 1)
+	collector := &timelineCollector{newestFirst: true}
+	if err := decodeTimeline(strings.NewReader(data), collector.addEntry); err != nil {
+		t.Fatalf("decodeTimeline() failed: %v", err)
+	}
+	if ids := tweetIDs(collector.tweets()); !reflect.DeepEqual(ids, []string{"10", "9", "8", "7", "5"}) {
+		t.Errorf("Newest first order: %v", ids)
+	}
+}
+
+// tweetIDs returns IDs of tweets in order
+func tweetIDs(tweets []Tweet) []string {
+	ids := make([]string, 0, len(tweets))
+	for _, tweet := range tweets {
+		ids = append(ids, tweet.ID)
+	}
+	return ids
+}
+
+const testUserJSON = `{"data":{"user":{"result":{"__typename":"User","rest_id":"783214","is_blue_verified":true,
+"core":{"name":"X","screen_name":"X","created_at":"Tue Feb 20 14:35:54 +0000 2007"},
+"avatar":{"image_url":"https://pbs.twimg.com/profile_images/1/x_normal.jpg"},
+"location":{"location":"everywhere"},
+"privacy":{"protected":false},
+"verification":{"verified":true,"verified_type":"Business"},
+"affiliates_highlighted_label":{"label":{"url":{"url":"https://twitter.com/XCorp","urlType":"DeepLink"},
+"badge":{"url":"https://pbs.twimg.com/profile_images/2/badge.jpg"},"description":"X Corp","userLabelType":"BusinessLabel","userLabelDisplayType":"Badge"}},
+"legacy":{"description":"news &amp; updates https://t.co/bio","url":"https://t.co/web",
+"profile_banner_url":"https://pbs.twimg.com/profile_banners/783214/1",
+"followers_count":100,"friends_count":5,"statuses_count":15000,"favourites_count":7,"media_count":3,"listed_count":9,
+"entities":{"description":{"urls":[{"url":"https://t.co/bio","expanded_url":"https://about.x.com","display_url":"about.x.com","indices":[15,31]}]},
+"url":{"urls":[{"url":"https://t.co/web","expanded_url":"https://x.com","display_url":"x.com"}]}}}}}}}`
+
+func TestRenderBio(t *testing.T) {
+	description := "Go team @golang &amp; #gophers, $GOOG fan. Mail me@example.com https://t.co/go#top"
+	urls := []URLEntity{{URL: "https://t.co/go#top", ExpandedURL: "https://go.dev/#top", DisplayURL: "go.dev/#top", Indices: [2]int{63, 82}}}
+
+	expected := `Go team <a href="https://x.com/golang" target="_blank">@golang</a> &amp; ` +
+		`<a href="https://x.com/hashtag/gophers" target="_blank">#gophers</a>, ` +
+		`<a href="https://x.com/search?q=%24GOOG&amp;src=cashtag_click" target="_blank">$GOOG</a> fan. Mail me@example.com ` +
+		`<a href="https://go.dev/#top" target="_blank">go.dev/#top</a>`
+	if bioHTML := renderBio(description, urls); bioHTML != expected {
+		t.Errorf("Unexpected bio HTML:\n%s\nexpected:\n%s", bioHTML, expected)
+	}
+}
+
+func TestGetUserProfile(t *testing.T) {
+	client := newTestClient(http.StatusOK, testUserJSON)
+	defer client.Close()
+
+	user, err := client.GetUserProfile("@X")
+	if err != nil {
+		t.Fatalf("GetUserProfile() failed: %v", err)
+	}
+
+	expected := &User{
+		ID:             "783214",
+		Username:       "X",
+		Name:           "X",
+		Bio:            "news & updates https://about.x.com",
+		BioHTML:        `news &amp; updates <a href="https://about.x.com" target="_blank">about.x.com</a>`,
+		BioURLs:        []URL{{Short: "https://t.co/bio", Expanded: "https://about.x.com", Display: "about.x.com"}},
+		Location:       "everywhere",
+		Website:        "https://x.com",
+		CreatedAt:      "Tue Feb 20 14:35:54 +0000 2007",
+		AvatarURL:      "https://pbs.twimg.com/profile_images/1/x.jpg",
+		BannerURL:      "https://pbs.twimg.com/profile_banners/783214/1",
+		Followers:      100,
+		Following:      5,
+		Tweets:         15000,
+		Likes:          7,
+		Media:          3,
+		Listed:         9,
+		IsVerified:     true,
+		IsBlueVerified: true,
+		VerifiedType:   VerifiedTypeBusiness,
+		Affiliate: &Affiliate{
+			Name:      "X Corp",
+			URL:       "https://twitter.com/XCorp",
+			BadgeURL:  "https://pbs.twimg.com/profile_images/2/badge.jpg",
+			LabelType: "BusinessLabel",
+		},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("Unexpected user:\n%+v\nexpected:\n%+v", user, expected)
+	}
+
+	// User ID is cached for GetUserID
+	if userID, err := client.GetUserID("x"); err != nil || userID != "783214" {
+		t.Errorf("GetUserID() = %q, %v", userID, err)
+	}
+	userIDCache.Delete("x")
+
+	// Statistics are available in raw response too
+	userResp, err := client.GetUserByScreenName("X")
+	if err != nil {
+		t.Fatalf("GetUserByScreenName() failed: %v", err)
+	}
+	if legacy := userResp.Data.User.Result.Legacy; legacy.FollowersCount != 100 || legacy.FriendsCount != 5 || legacy.StatusesCount != 15000 {
+		t.Errorf("Unexpected statistics: %+v", legacy)
+	}
+
+	// Legacy-only fields
+	var result UserResult
+	if err := json.Unmarshal([]byte(`{"rest_id":"1","legacy":{"name":"Old","screen_name":"old","location":"here",
+"profile_image_url_https":"https://pbs.twimg.com/a_normal.jpg","protected":true,"verified":true}}`), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	legacyUser := convertUserResult(&result)
+	if legacyUser.Username != "old" || legacyUser.Name != "Old" || legacyUser.Location != "here" ||
+		legacyUser.AvatarURL != "https://pbs.twimg.com/a.jpg" || !legacyUser.IsProtected || !legacyUser.IsVerified ||
+		legacyUser.IsBlueVerified || legacyUser.Affiliate != nil {
+		t.Errorf("Legacy fields not converted: %+v", legacyUser)
+	}
+}
+
+func TestGetUserByID(t *testing.T) {
+	client := newTestClient(http.StatusOK, testUserJSON)
+	defer client.Close()
+	defer userIDCache.Delete("x")
+
+	var variables map[string]any
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/UserByRestId") {
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	user, err := client.GetUserByID("783214")
+	if err != nil {
+		t.Fatalf("GetUserByID() failed: %v", err)
+	}
+	if variables["userId"] != "783214" {
+		t.Errorf("Unexpected variables: %v", variables)
+	}
+	if user.ID != "783214" || user.Username != "X" || user.Name != "X" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	// Resolved handle is cached for GetUserID
+	if value, ok := userIDCache.Load("x"); !ok || value.(*userIDCacheEntry).UserID != "783214" {
+		t.Error("User ID not cached")
+	}
+
+	notFound := newTestClient(http.StatusOK, `{"data":{"user":{}}}`)
+	defer notFound.Close()
+	if _, err := notFound.GetUserByID("1"); err == nil {
+		t.Error("Expected error for unknown user")
+	}
+}
+
+// searchUsersPageJSON builds people search response with users of the given IDs and bottom cursor
+func searchUsersPageJSON(bottomCursor string, ids ...string) string {
+	var entries []string
+	for _, id := range ids {
+		entries = append(entries, fmt.Sprintf(`{"entryId":"user-%s","content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineUser","user_results":{"result":{"rest_id":"%s","core":{"screen_name":"user%s","name":"User %s"},"legacy":{"followers_count":1}}}}}}`, id, id, id, id))
+	}
+	entries = append(entries, fmt.Sprintf(`{"entryId":"cursor-bottom-%s","content":{"entryType":"TimelineTimelineCursor","value":"%s","cursorType":"Bottom"}}`, bottomCursor, bottomCursor))
+	return `{"data":{"search_by_raw_query":{"search_timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[` +
+		strings.Join(entries, ",") + `]}]}}}}}`
+}
+
+func TestSearchUsers(t *testing.T) {
+	pages := map[string]string{
+		"":   searchUsersPageJSON("c1", "1", "2"),
+		"c1": searchUsersPageJSON("c2", "2", "3"),
+		"c2": searchUsersPageJSON("c3"),
+	}
+
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/SearchTimeline") {
+			var variables map[string]any
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			if variables["rawQuery"] != "gopher" || variables["product"] != "People" {
+				t.Errorf("Unexpected variables: %v", variables)
+			}
+			cursor, _ := variables["cursor"].(string)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(pages[cursor])), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	users, err := client.SearchUsers("gopher", 0)
+	if err != nil {
+		t.Fatalf("SearchUsers() failed: %v", err)
+	}
+	var ids []string
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2", "3"}) {
+		t.Errorf("Unexpected users: %v", ids)
+	}
+	if users[0].Username != "user1" || users[0].Name != "User 1" || users[0].Followers != 1 {
+		t.Errorf("User not converted: %+v", users[0])
+	}
+
+	users, err = client.SearchUsers("gopher", 1)
+	if err != nil || len(users) != 2 {
+		t.Errorf("SearchUsers() with page limit returned %d users, %v", len(users), err)
+	}
+}
+
+func searchTweetsPageJSON(bottomCursor string, ids ...string) string {
+	var entries []string
+	for _, id := range ids {
+		entries = append(entries, fmt.Sprintf(`{"entryId":"tweet-%s","content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineTweet","tweet_results":{"result":{"__typename":"Tweet","rest_id":"%s","legacy":{"full_text":"Tweet %s #golang","id_str":"%s"}}}}}}`, id, id, id, id))
+	}
+	entries = append(entries, fmt.Sprintf(`{"entryId":"cursor-bottom-%s","content":{"entryType":"TimelineTimelineCursor","value":"%s","cursorType":"Bottom"}}`, bottomCursor, bottomCursor))
+	return `{"data":{"search_by_raw_query":{"search_timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[` +
+		strings.Join(entries, ",") + `]}]}}}}}`
+}
+
+func TestGetHashtagTweets(t *testing.T) {
+	pages := map[string]string{
+		"":   searchTweetsPageJSON("c1", "3", "2"),
+		"c1": searchTweetsPageJSON("c2", "2", "1"),
+		"c2": searchTweetsPageJSON("c3"),
+	}
+
+	client := newTestClient(http.StatusOK, "", WithPageDelay(0, 0))
+	defer client.Close()
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/SearchTimeline") {
+			var variables map[string]any
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			if variables["rawQuery"] != "#golang lang:en -filter:retweets" || variables["product"] != "Latest" {
+				t.Errorf("Unexpected variables: %v", variables)
+			}
+			cursor, _ := variables["cursor"].(string)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(pages[cursor])), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	tweets, err := client.GetHashtagTweets("#golang", SearchOptions{Lang: "en", ExcludeRetweets: true})
+	if err != nil {
+		t.Fatalf("GetHashtagTweets() failed: %v", err)
+	}
+	var ids []string
+	for _, tweet := range tweets {
+		ids = append(ids, tweet.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"3", "2", "1"}) {
+		t.Errorf("Unexpected tweets: %v", ids)
+	}
+	if tweets[0].Text != "Tweet 3 #golang" {
+		t.Errorf("Tweet not converted: %+v", tweets[0])
+	}
+
+	tweets, err = client.GetHashtagTweets("golang", SearchOptions{MaxPages: 1, Lang: "en", ExcludeRetweets: true})
+	if err != nil || len(tweets) != 2 {
+		t.Errorf("GetHashtagTweets() with page limit returned %d tweets, %v", len(tweets), err)
+	}
+
+	for _, tag := range []string{"", "#", "go lang", "golang OR rust"} {
+		if _, err := client.GetHashtagTweets(tag, SearchOptions{}); err == nil {
+			t.Errorf("Expected error for hashtag %q", tag)
+		}
+	}
+}
+
+func TestProfileImages(t *testing.T) {
+	for input, expected := range map[string]string{
+		"https://pbs.twimg.com/profile_images/1/x_normal.jpg":     "https://pbs.twimg.com/profile_images/1/x.jpg",
+		"https://pbs.twimg.com/profile_images/1/x_400x400.png":    "https://pbs.twimg.com/profile_images/1/x.png",
+		"https://pbs.twimg.com/profile_images/1/my_normal_bigger": "https://pbs.twimg.com/profile_images/1/my_normal",
+		"https://pbs.twimg.com/profile_images/1/x.jpg":            "https://pbs.twimg.com/profile_images/1/x.jpg",
+		"": "",
+	} {
+		if got := originalAvatarURL(input); got != expected {
+			t.Errorf("originalAvatarURL(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+
+	user := &User{
+		AvatarURL: "https://pbs.twimg.com/profile_images/1/x.jpg",
+		BannerURL: "https://pbs.twimg.com/profile_banners/783214/1",
+	}
+	if got := user.AvatarURLSize(Avatar400); got != "https://pbs.twimg.com/profile_images/1/x_400x400.jpg" {
+		t.Errorf("AvatarURLSize() = %q", got)
+	}
+	if got := user.BannerURLSize(BannerLarge); got != "https://pbs.twimg.com/profile_banners/783214/1/1500x500" {
+		t.Errorf("BannerURLSize() = %q", got)
+	}
+	if user.AvatarURLSize(AvatarOriginal) != user.AvatarURL || user.BannerURLSize(BannerOriginal) != user.BannerURL {
+		t.Error("Original size URLs differ")
+	}
+
+	client := newTestClient(http.StatusOK, "image data")
+	defer client.Close()
+	var buf bytes.Buffer
+	if n, err := client.DownloadMedia(user.AvatarURL, &buf); err != nil || n != 10 || buf.String() != "image data" {
+		t.Errorf("DownloadMedia() = %d, %v, %q", n, err, buf.String())
+	}
+
+	notFound := newTestClient(http.StatusNotFound, "")
+	defer notFound.Close()
+	var statusErr *StatusError
+	if _, err := notFound.DownloadMedia(user.AvatarURL, io.Discard); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected StatusError, got %v", err)
+	}
+}
+
+func TestGetRelationship(t *testing.T) {
+	client := newTestClient(http.StatusOK, `{"relationship":{
+"source":{"id_str":"1","screen_name":"alice","following":true,"followed_by":false,"blocking":null,"muting":null},
+"target":{"id_str":"2","screen_name":"bob","following":false,"followed_by":true}}}`)
+	defer client.Close()
+
+	var query url.Values
+	transport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == LegacyFriendshipShowPath {
+			query = req.URL.Query()
+			if req.Header.Get("Authorization") != "Bearer "+AndroidBearerToken {
+				t.Errorf("Unexpected authorization: %s", req.Header.Get("Authorization"))
+			}
+		}
+		return transport.RoundTrip(req)
+	})
+
+	relationship, err := client.GetRelationship("1", "2")
+	if err != nil {
+		t.Fatalf("GetRelationship() failed: %v", err)
+	}
+	if query.Get("source_id") != "1" || query.Get("target_id") != "2" {
+		t.Errorf("Unexpected query: %v", query)
+	}
+	expected := &Relationship{
+		SourceID:       "1",
+		SourceUsername: "alice",
+		TargetID:       "2",
+		TargetUsername: "bob",
+		Following:      true,
+	}
+	if !reflect.DeepEqual(relationship, expected) {
+		t.Errorf("Unexpected relationship: %+v", relationship)
+	}
+
+	forbidden := newTestClient(http.StatusForbidden, `{"errors":[{"code":200,"message":"Forbidden."}]}`)
+	defer forbidden.Close()
+	var statusErr *StatusError
+	if _, err := forbidden.GetRelationship("1", "2"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected StatusError, got %v", err)
+	}
+}
+
+func TestGetTrends(t *testing.T) {
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+
+	transport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body string
+		switch req.URL.Path {
+		case LegacyTrendsAvailable:
+			body = `[{"name":"Worldwide","country":"","woeid":1},{"name":"London","country":"United Kingdom","woeid":44418}]`
+		case LegacyTrendsPlacePath:
+			if req.URL.Query().Get("id") != "44418" {
+				t.Errorf("Unexpected WOEID: %s", req.URL.RawQuery)
+			}
+			body = `[{"trends":[
+{"name":"#GoLang","url":"http://twitter.com/search?q=%23GoLang","query":"%23GoLang","tweet_volume":12345},
+{"name":"Gophers","url":"http://twitter.com/search?q=Gophers","query":"Gophers","tweet_volume":null}]}]`
+		default:
+			return transport.RoundTrip(req)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+
+	trends, err := client.GetTrendsByPlace("london")
+	if err != nil {
+		t.Fatalf("GetTrendsByPlace() failed: %v", err)
+	}
+	expected := []Trend{
+		{Name: "#GoLang", Query: "#GoLang", URL: "http://twitter.com/search?q=%23GoLang", TweetVolume: 12345, Source: TrendSourcePlace},
+		{Name: "Gophers", Query: "Gophers", URL: "http://twitter.com/search?q=Gophers", Source: TrendSourcePlace},
+	}
+	if !reflect.DeepEqual(trends, expected) {
+		t.Errorf("Unexpected trends: %+v", trends)
+	}
+
+	if _, err := client.GetTrendsByPlace("Atlantis"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown place, got %v", err)
+	}
+}
+
+func TestGetExploreTrends(t *testing.T) {
+	explore, err := os.ReadFile("twittertest/testdata/explore_trending.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	// v1.1 trends are forbidden for guest token, so Explore page is used instead
+	client := newTestClient(http.StatusForbidden, `{"errors":[{"message":"Forbidden","code":200}]}`)
+	defer client.Close()
+	transport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/GenericTimelineById") {
+			if !strings.Contains(req.URL.Query().Get("variables"), ExploreTrendingTimelineID) {
+				t.Errorf("Unexpected timeline ID: %s", req.URL.Query().Get("variables"))
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(explore)), Request: req}, nil
+		}
+		return transport.RoundTrip(req)
+	})
+
+	expected := []Trend{
+		{Name: "#GoLang", Query: "#GoLang", URL: "https://x.com/search?q=%23GoLang", TweetVolume: 12300,
+			Source: TrendSourceExplore, Context: "Trending in Technology"},
+		{Name: "Gophers", Query: "Gophers", URL: "https://x.com/search?q=Gophers",
+			Source: TrendSourceExplore, Context: "Trending in United Kingdom"},
+		{Name: "Concurrency", Query: "Concurrency", URL: "https://x.com/search?q=Concurrency", TweetVolume: 1234,
+			Source: TrendSourceExplore, Context: "Programming · Trending"},
+	}
+	trends, err := client.GetTrends(WorldwideWOEID)
+	if err != nil {
+		t.Fatalf("GetTrends() with explore fallback failed: %v", err)
+	}
+	if !reflect.DeepEqual(trends, expected) {
+		t.Errorf("Unexpected trends: %+v", trends)
+	}
+
+	trends, err = client.GetTrendsByPlace("London")
+	if err != nil {
+		t.Fatalf("GetTrendsByPlace() with explore fallback failed: %v", err)
+	}
+	if !reflect.DeepEqual(trends, expected) {
+		t.Errorf("Unexpected trends: %+v", trends)
+	}
+
+	// Both sources failing report both errors
+	failing := newTestClient(http.StatusForbidden, `{"errors":[{"message":"Forbidden","code":200}]}`)
+	defer failing.Close()
+	if _, err := failing.GetTrends(WorldwideWOEID); err == nil {
+		t.Error("Expected error when both trend sources fail")
+	}
+
+	// Other failures are not hidden by trends of another location
+	limited := newTestClient(http.StatusTooManyRequests, `{}`)
+	defer limited.Close()
+	explored := false
+	limitedTransport := limited.httpClient.Transport
+	limited.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/GenericTimelineById") {
+			explored = true
+		}
+		return limitedTransport.RoundTrip(req)
+	})
+	if _, err := limited.GetTrends(23424977); err == nil || explored {
+		t.Errorf("Expected error without explore fallback, got %v (explored: %v)", err, explored)
+	}
+}
+
+func TestGetPinnedTweet(t *testing.T) {
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	defer client.Close()
+	WithPinnedPosition(PinnedExcluded)(client)
+
+	var count float64
+	transport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/UserTweets") {
+			var variables map[string]any
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			count, _ = variables["count"].(float64)
+		}
+		return transport.RoundTrip(req)
+	})
+
+	tweet, err := client.GetPinnedTweet("42")
+	if err != nil {
+		t.Fatalf("GetPinnedTweet() failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected count 1, got %v", count)
+	}
+	if tweet == nil || tweet.ID != "100" || !tweet.IsPinned {
+		t.Errorf("Unexpected pinned tweet: %+v", tweet)
+	}
+
+	noPin := newTestClient(http.StatusOK, timelinePageJSON("c1", "2", "1"))
+	defer noPin.Close()
+	if tweet, err := noPin.GetPinnedTweet("42"); err != nil || tweet != nil {
+		t.Errorf("Expected no pinned tweet, got %+v, %v", tweet, err)
+	}
+}
+
+func TestParseTweetURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		username string
+		tweetID  string
+		err      bool
+	}{
+		{"https://x.com/golang/status/1234567890", "golang", "1234567890", false},
+		{"twitter.com/golang/status/1234567890?s=20", "golang", "1234567890", false},
+		{"https://mobile.twitter.com/golang/status/1234567890/photo/1", "golang", "1234567890", false},
+		{"https://fxtwitter.com/golang/status/1234567890", "golang", "1234567890", false},
+		{"https://vxtwitter.com/golang/statuses/1234567890#m", "golang", "1234567890", false},
+		{"https://fixupx.com/golang/status/1234567890", "golang", "1234567890", false},
+		{"https://x.com/i/status/1234567890", "", "1234567890", false},
+		{"https://twitter.com/i/web/status/1234567890", "", "1234567890", false},
+		{"https://x.com/golang", "", "", true},
+		{"https://x.com/golang/status/abc", "", "", true},
+		{"https://x.com/golang/status/123abc", "", "", true},
+		{"https://example.com/golang/status/1234567890", "", "", true},
+	}
+
+	for _, tt := range tests {
+		username, tweetID, err := ParseTweetURL(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseTweetURL(%q) error = %v, expected error: %v", tt.input, err, tt.err)
+			continue
+		}
+		if username != tt.username || tweetID != tt.tweetID {
+			t.Errorf("ParseTweetURL(%q) = %q, %q, expected %q, %q", tt.input, username, tweetID, tt.username, tt.tweetID)
+		}
+	}
+
+	for _, input := range []string{"golang", "@golang", "https://x.com/home", "https://x.com/i/lists/123"} {
+		if _, err := ParseProfileURL(input); err == nil {
+			t.Errorf("Expected ParseProfileURL error for %q", input)
+		}
+	}
+	if ref, err := ParseProfileURL("https://fixvx.com/golang/status/1"); err != nil || ref.Username != "golang" {
+		t.Errorf("ParseProfileURL() = %+v, %v", ref, err)
+	}
+}
+
+func TestParseUserInput(t *testing.T) {
+	tests := []struct {
+		input string
+		ref   UserRef
+		err   bool
+	}{
+		{"elonmusk", UserRef{Username: "elonmusk"}, false},
+		{"@elonmusk", UserRef{Username: "elonmusk"}, false},
+		{" 44196397 ", UserRef{ID: "44196397"}, false},
+		{"@123", UserRef{Username: "123"}, false},
+		{"x.com/golang", UserRef{Username: "golang"}, false},
+		{"https://twitter.com/golang", UserRef{Username: "golang"}, false},
+		{"https://www.x.com/golang/status/1234567890?s=20", UserRef{Username: "golang"}, false},
+		{"HTTPS://Mobile.Twitter.com/golang/", UserRef{Username: "golang"}, false},
+		{"https://x.com/i/user/783214", UserRef{ID: "783214"}, false},
+		{"https://fxtwitter.com/golang", UserRef{Username: "golang"}, false},
+		{"https://x.com/home", UserRef{}, true},
+		{"https://x.com/i/lists/123", UserRef{}, true},
+		{"https://example.com/golang", UserRef{}, true},
+		{"not a user", UserRef{}, true},
+		{"@waytoolongusername", UserRef{}, true},
+		{"", UserRef{}, true},
+	}
+
+	for _, tt := range tests {
+		ref, err := ParseUserInput(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseUserInput(%q) error = %v, expected error: %v", tt.input, err, tt.err)
+			continue
+		}
+		if ref != tt.ref {
+			t.Errorf("ParseUserInput(%q) = %+v, expected %+v", tt.input, ref, tt.ref)
+		}
+	}
+
+	client := newTestClient(http.StatusOK, testUserJSON)
+	defer client.Close()
+	defer userIDCache.Delete("x")
+
+	ref, err := client.ResolveUser("https://x.com/X")
+	if err != nil || ref.ID != "783214" || ref.Username != "X" {
+		t.Errorf("ResolveUser() = %+v, %v", ref, err)
+	}
+	ref, err = client.ResolveUser("44196397")
+	if err != nil || ref.ID != "44196397" || ref.Username != "" {
+		t.Errorf("ResolveUser() = %+v, %v", ref, err)
+	}
+}
+
+func TestBuildThreads(t *testing.T) {
+	tweets := []Tweet{
+		{ID: "13", UserID: "1", InReplyToID: "12", InReplyToUserID: "1"},
+		{ID: "20", UserID: "1"},
+		{ID: "12", UserID: "1", InReplyToID: "10", InReplyToUserID: "1"},
+		{ID: "11", UserID: "1", InReplyToID: "5", InReplyToUserID: "2"}, // Reply to other user
+		{ID: "10", UserID: "1"},
+		{ID: "13", UserID: "1", InReplyToID: "12", InReplyToUserID: "1"}, // Duplicate
+		{ID: "9", UserID: "1", InReplyToID: "8", InReplyToUserID: "1"},   // Parent is missing
+	}
+
+	var threads [][]string
+	for _, thread := range BuildThreads(tweets) {
+		threads = append(threads, tweetIDs(thread))
+	}
+
+	expected := [][]string{{"10", "12", "13"}, {"20"}, {"11"}, {"9"}}
+	if !reflect.DeepEqual(threads, expected) {
+		t.Errorf("BuildThreads() = %v, expected %v", threads, expected)
+	}
+
+	// Reply cycle does not hang
+	cycle := []Tweet{
+		{ID: "1", UserID: "1", InReplyToID: "2", InReplyToUserID: "1"},
+		{ID: "2", UserID: "1", InReplyToID: "1", InReplyToUserID: "1"},
+	}
+	if threads := BuildThreads(cycle); len(threads) == 0 {
+		t.Error("BuildThreads() returned no threads for reply cycle")
+	}
+}
+
+func TestTweetCommunity(t *testing.T) {
+	var tweetResult TweetResult
+	err := json.Unmarshal([]byte(`{"rest_id":"1","legacy":{"full_text":"Shipped!"},
+"community_results":{"result":{"__typename":"Community","id_str":"1493446837214187523","name":"Build in Public"}}}`), &tweetResult)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	processTweetResult(&tweetResult, renderOptions{})
+	tweet := convertTweetResult(&tweetResult)
+
+	expected := &Community{
+		ID:   "1493446837214187523",
+		Name: "Build in Public",
+		URL:  "https://x.com/i/communities/1493446837214187523",
+	}
+	if !reflect.DeepEqual(tweet.Community, expected) {
+		t.Errorf("Unexpected community: %+v", tweet.Community)
+	}
+
+	for _, tweet := range extractTweetsFromTimeline(loadTimelineFixture(t)) {
+		if tweet.Community != nil {
+			t.Errorf("Tweet %s has unexpected community: %+v", tweet.ID, tweet.Community)
+		}
+	}
+}
+
+func TestTweetRestrictions(t *testing.T) {
+	data := `{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[
+{"entryId":"tweet-2","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"__typename":"TweetWithVisibilityResults",
+"tweet":{"rest_id":"2","legacy":{"full_text":"Restricted","withheld_in_countries":["DE","FR"]}},
+"limitedActionResults":{"limited_actions":[{"action":"Reply","prompt":{"__typename":"CtaLimitedActionPrompt",
+"headline":{"text":"Who can reply?"},"subtext":{"text":"People @test mentioned can reply"}}},{"action":"Retweet"}]},
+"tweetInterstitial":{"__typename":"ContextualTweetInterstitial","displayType":"NonCompliant","text":{"text":"This Post violated the X Rules."}}}}}}},
+{"entryId":"tweet-1","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"rest_id":"1","legacy":{"full_text":"Regular"}}}}}}
+]}]}}}}}}`
+
+	collector := &timelineCollector{}
+	if err := decodeTimeline(strings.NewReader(data), collector.addEntry); err != nil {
+		t.Fatalf("decodeTimeline() failed: %v", err)
+	}
+	tweets := collector.tweets()
+	if len(tweets) != 2 {
+		t.Fatalf("Expected 2 tweets, got %d", len(tweets))
+	}
+
+	expected := &Restrictions{
+		LimitedActions:       []string{"Reply", "Retweet"},
+		LimitedActionsReason: "People @test mentioned can reply",
+		Interstitial:         "This Post violated the X Rules.",
+		InterstitialType:     "NonCompliant",
+		WithheldInCountries:  []string{"DE", "FR"},
+	}
+	if tweets[0].ID != "2" || tweets[0].Text != "Restricted" {
+		t.Errorf("Wrapped tweet not unwrapped: %+v", tweets[0])
+	}
+	if !reflect.DeepEqual(tweets[0].Restrictions, expected) {
+		t.Errorf("Unexpected restrictions: %+v", tweets[0].Restrictions)
+	}
+	if tweets[1].Restrictions != nil {
+		t.Errorf("Regular tweet has restrictions: %+v", tweets[1].Restrictions)
+	}
+}
+
+func TestExclusiveTweets(t *testing.T) {
+	for name, data := range map[string]string{
+		"ExclusiveTweetInfo": `{"rest_id":"1","legacy":{"full_text":"Subscribers only preview"},"exclusive_tweet_info":{"creator_id":"42"}}`,
+		"ExclusivityInfo":    `{"rest_id":"1","legacy":{"full_text":"Subscribers only preview"},"exclusivityInfo":{"exclusive":true}}`,
+	} {
+		var tweetResult TweetResult
+		if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		processTweetResult(&tweetResult, renderOptions{})
+		tweet := convertTweetResult(&tweetResult)
+		if !tweet.IsExclusive || tweet.Text != "Subscribers only preview" {
+			t.Errorf("%s: exclusive tweet not detected: %+v", name, tweet)
+		}
+	}
+
+	for _, tweet := range extractTweetsFromTimeline(loadTimelineFixture(t)) {
+		if tweet.IsExclusive {
+			t.Errorf("Tweet %s detected as exclusive", tweet.ID)
+		}
+	}
+}
+
+func TestQuotedTweet(t *testing.T) {
+	tests := []struct {
+		name     string
+		quoted   string
+		expected *QuotedTweet
+	}{
+		{
+			name:     "Available",
+			quoted:   `{"__typename":"Tweet","rest_id":"2","legacy":{"full_text":"Quoted &amp; text"}}`,
+			expected: &QuotedTweet{Tweet: Tweet{ID: "2", Text: "Quoted &amp; text"}},
+		},
+		{
+			name:     "Tombstone",
+			quoted:   `{"__typename":"TweetTombstone","tombstone":{"__typename":"TextTombstone","text":{"text":"This Post was deleted by the Post author."}}}`,
+			expected: &QuotedTweet{Unavailable: true, Reason: "This Post was deleted by the Post author."},
+		},
+		{
+			name:     "Unavailable",
+			quoted:   `{"__typename":"TweetUnavailable","reason":"Suspended"}`,
+			expected: &QuotedTweet{Unavailable: true, Reason: "Suspended"},
+		},
+		{
+			name:     "WithVisibilityResults",
+			quoted:   `{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"2","legacy":{"full_text":"Quoted"}}}`,
+			expected: &QuotedTweet{Tweet: Tweet{ID: "2", Text: "Quoted"}},
+		},
+	}
+
+	for _, tt := range tests {
+		data := `{"rest_id":"1","legacy":{"full_text":"Quote","is_quote_status":true},"quoted_status_result":{"result":` + tt.quoted + `}}`
+		var tweetResult TweetResult
+		if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+			t.Fatalf("%s: Unmarshal failed: %v", tt.name, err)
+		}
+		processTweetResult(&tweetResult, renderOptions{})
+		tweet := convertTweetResult(&tweetResult)
+		if !tweet.IsQuoted || tweet.QuotedTweet == nil {
+			t.Fatalf("%s: quoted tweet not parsed: %+v", tt.name, tweet)
+		}
+		quoted := tweet.QuotedTweet
+		if quoted.Unavailable != tt.expected.Unavailable || quoted.Reason != tt.expected.Reason ||
+			quoted.ID != tt.expected.ID || quoted.Text != tt.expected.Text {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, quoted)
+		}
+	}
+
+	for _, tweet := range extractTweetsFromTimeline(loadTimelineFixture(t)) {
+		if tweet.QuotedTweet != nil {
+			t.Errorf("Tweet %s has quoted tweet not included in response", tweet.ID)
+		}
+	}
+}
+
+func TestQuoteDepth(t *testing.T) {
+	timeline := `{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[
+{"entryId":"tweet-4","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"rest_id":"4",
+"legacy":{"full_text":"Quote of quote","is_quote_status":true,"quoted_status_id_str":"3"},
+"quoted_status_result":{"result":{"rest_id":"3","legacy":{"full_text":"Quote","is_quote_status":true,"quoted_status_id_str":"2"}}}}}}}}
+]}]}}}}}}`
+	syndicationTweets := map[string]string{
+		"2": `{"id_str":"2","text":"Quote","quoted_status_id_str":"1","user":{"id_str":"42","screen_name":"test"}}`,
+		"1": `{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post is from a suspended account."}}}`,
+	}
+
+	client := newTestClient(http.StatusOK, timeline)
+	defer client.Close()
+	var fetched []string
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == SyndicationTweetPath {
+			id := req.URL.Query().Get("id")
+			fetched = append(fetched, id)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(syndicationTweets[id])),
+				Request:    req,
+			}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	tweets, err := client.GetUserTweets("42")
+	if err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+	if len(fetched) != 0 || tweets[0].QuotedTweet == nil || tweets[0].QuotedTweet.QuotedTweet != nil {
+		t.Errorf("Expected only quote included in response, fetched %v", fetched)
+	}
+
+	WithQuoteDepth(3)(client)
+	tweets, err = client.GetUserTweets("42")
+	if err != nil {
+		t.Fatalf("GetUserTweets() with quote depth failed: %v", err)
+	}
+	if !reflect.DeepEqual(fetched, []string{"2", "1"}) {
+		t.Errorf("Expected quoted tweets 2 and 1 fetched, got %v", fetched)
+	}
+	level1 := tweets[0].QuotedTweet
+	if level1 == nil || level1.ID != "3" {
+		t.Fatalf("Unexpected first level quote: %+v", level1)
+	}
+	level2 := level1.QuotedTweet
+	if level2 == nil || level2.ID != "2" || level2.Text != "Quote" {
+		t.Fatalf("Unexpected second level quote: %+v", level2)
+	}
+	level3 := level2.QuotedTweet
+	if level3 == nil || !level3.Unavailable || level3.Reason != "This Post is from a suspended account." {
+		t.Errorf("Unexpected third level quote: %+v", level3)
+	}
+
+	fetched = nil
+	WithQuoteDepth(2)(client)
+	tweets, _ = client.GetUserTweets("42")
+	if !reflect.DeepEqual(fetched, []string{"2"}) || tweets[0].QuotedTweet.QuotedTweet.QuotedTweet != nil {
+		t.Errorf("Expected quote chain limited to depth 2, fetched %v", fetched)
+	}
+}
+
+func TestTweetMedia(t *testing.T) {
+	data := `{"rest_id":"1","legacy":{"full_text":"Media","extended_entities":{"media":[
+{"type":"photo","media_url_https":"https://pbs.twimg.com/media/photo.jpg"},
+{"type":"video","media_url_https":"https://pbs.twimg.com/video_thumb.jpg","video_info":{"duration_millis":15000,"variants":[
+{"content_type":"application/x-mpegURL","url":"https://video.twimg.com/video.m3u8"},
+{"bitrate":832000,"content_type":"video/mp4","url":"https://video.twimg.com/video_832.mp4"},
+{"bitrate":2176000,"content_type":"video/mp4","url":"https://video.twimg.com/video_2176.mp4"}]}},
+{"type":"video","media_url_https":"https://pbs.twimg.com/voice_thumb.jpg","video_info":{"duration_millis":140000,"variants":[
+{"content_type":"application/x-mpegURL","url":"https://video.twimg.com/voice.m3u8"},
+{"bitrate":64000,"content_type":"audio/mp4","url":"https://video.twimg.com/voice.m4a"}]}}]}},
+"card":{"legacy":{"name":"player","binding_values":[
+{"key":"player_stream_url","value":{"string_value":"https://example.com/voice.mp3"}},
+{"key":"player_stream_content_type","value":{"string_value":"audio/mpeg"}},
+{"key":"player_image","value":{"image_value":{"url":"https://pbs.twimg.com/card_small.jpg"}}},
+{"key":"player_image_large","value":{"image_value":{"url":"https://pbs.twimg.com/card_large.jpg"}}},
+{"key":"content_duration_seconds","value":{"string_value":"42"}}]}}}`
+
+	var tweetResult TweetResult
+	if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	processTweetResult(&tweetResult, renderOptions{})
+	tweet := convertTweetResult(&tweetResult)
+
+	expected := []Media{
+		{Type: MediaPhoto, URL: "https://pbs.twimg.com/media/photo.jpg"},
+		{Type: MediaVideo, URL: "https://video.twimg.com/video_2176.mp4", PreviewURL: "https://pbs.twimg.com/video_thumb.jpg", Duration: 15 * time.Second},
+		{Type: MediaAudio, URL: "https://video.twimg.com/voice.m4a", PreviewURL: "https://pbs.twimg.com/voice_thumb.jpg", Duration: 140 * time.Second},
+		{Type: MediaAudio, URL: "https://example.com/voice.mp3", PreviewURL: "https://pbs.twimg.com/card_large.jpg", Duration: 42 * time.Second},
+	}
+	if !reflect.DeepEqual(tweet.Media, expected) {
+		t.Errorf("Unexpected media:\n got %+v\nwant %+v", tweet.Media, expected)
+	}
+	if !reflect.DeepEqual(tweet.Images, []string{"https://pbs.twimg.com/media/photo.jpg"}) {
+		t.Errorf("Unexpected images: %v", tweet.Images)
+	}
+}
+
+func TestGetListByURL(t *testing.T) {
+	listJSON := `{"data":{"list":{"id_str":"123","name":"Go","description":"Gophers","member_count":50,"subscriber_count":7,"mode":"Public",
+"user_results":{"result":{"rest_id":"42","legacy":{"screen_name":"owner","name":"Owner"}}}}}}`
+
+	client := newTestClient(http.StatusOK, listJSON)
+	defer client.Close()
+	var requests []string
+	transport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/graphql/") {
+			requests = append(requests, path.Base(req.URL.Path)+" "+req.URL.Query().Get("variables"))
+		}
+		return transport.RoundTrip(req)
+	})
+
+	expected := []string{
+		`ListByRestId {"listId":"123"}`,
+		`ListByRestId {"listId":"123"}`,
+		`ListBySlug {"listSlug":"golang","screenName":"owner"}`,
+	}
+	for i, input := range []string{"https://x.com/i/lists/123", "123", "twitter.com/owner/lists/golang"} {
+		list, err := client.GetListByURL(input)
+		if err != nil {
+			t.Fatalf("GetListByURL(%q) failed: %v", input, err)
+		}
+		if requests[i] != expected[i] {
+			t.Errorf("GetListByURL(%q) requested %s, want %s", input, requests[i], expected[i])
+		}
+		if list.ID != "123" || list.Name != "Go" || list.Members != 50 || list.Subscribers != 7 || list.IsPrivate ||
+			list.URL != "https://x.com/i/lists/123" || list.Owner == nil || list.Owner.Username != "owner" {
+			t.Errorf("Unexpected list: %+v", list)
+		}
+	}
+
+	for _, input := range []string{"https://x.com/owner", "https://x.com/i/lists/abc", "x.com/owner/status/1"} {
+		if _, err := client.GetListByURL(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+
+	notFound := newTestClient(http.StatusOK, `{"data":{}}`)
+	defer notFound.Close()
+	if _, err := notFound.GetListInfo("1"); err == nil {
+		t.Error("Expected error for missing list")
+	}
+}
+
+func TestGetSpace(t *testing.T) {
+	spaceJSON := `{"data":{"audioSpace":{"metadata":{"rest_id":"1YqKDqWqdPLGV","state":"Ended","title":"Go talk",
+"scheduled_start":0,"started_at":1700000000000,"ended_at":"1700003600000","total_live_listeners":120,"total_replay_watched":30,
+"is_space_available_for_replay":true,"creator_results":{"result":{"rest_id":"42","legacy":{"screen_name":"host","name":"Host"}}}},
+"participants":{"total":3,"admins":[{"twitter_screen_name":"host","display_name":"Host","user_results":{"rest_id":"42"}}],
+"speakers":[{"twitter_screen_name":"guest","display_name":"Guest","user_results":{"rest_id":"43"}}]}}}}`
+
+	client := newTestClient(http.StatusOK, spaceJSON)
+	defer client.Close()
+
+	space, err := client.GetSpace("1YqKDqWqdPLGV")
+	if err != nil {
+		t.Fatalf("GetSpace() failed: %v", err)
+	}
+	if space.ID != "1YqKDqWqdPLGV" || space.Title != "Go talk" || space.State != "Ended" || space.URL != "https://x.com/i/spaces/1YqKDqWqdPLGV" ||
+		space.Listeners != 120 || space.Replays != 30 || !space.Replayable || space.Creator == nil || space.Creator.Username != "host" {
+		t.Errorf("Unexpected space: %+v", space)
+	}
+	if !space.ScheduledStart.IsZero() || !space.StartedAt.Equal(time.UnixMilli(1700000000000)) || space.EndedAt.Sub(space.StartedAt) != time.Hour {
+		t.Errorf("Unexpected space times: scheduled %v, started %v, ended %v", space.ScheduledStart, space.StartedAt, space.EndedAt)
+	}
+	expectedSpeakers := []SpaceParticipant{{UserID: "43", Username: "guest", Name: "Guest"}}
+	if len(space.Admins) != 1 || space.Admins[0].Username != "host" || !reflect.DeepEqual(space.Speakers, expectedSpeakers) {
+		t.Errorf("Unexpected participants: admins %+v, speakers %+v", space.Admins, space.Speakers)
+	}
+
+	notFound := newTestClient(http.StatusOK, `{"data":{"audioSpace":{}}}`)
+	defer notFound.Close()
+	if _, err := notFound.GetSpace("1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+// tweetResultsJSON returns TweetResultsByRestIds response with tweets of IDs,
+// a tombstone for ID "0" and no result for ID "99"
+func tweetResultsJSON(ids []string) string {
+	var results []string
+	for _, id := range ids {
+		switch {
+		case id == "0":
+			results = append(results, `{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post was deleted by the Post author."}}}}`)
+		case id == "99":
+			results = append(results, `{}`)
+		default:
+			results = append(results, fmt.Sprintf(`{"result":{"__typename":"Tweet","rest_id":"%s","legacy":{"full_text":"Tweet %s","favorite_count":%s}}}`, id, id, id))
+		}
+	}
+	return `{"data":{"tweetResult":[` + strings.Join(results, ",") + `]}}`
+}
+
+func TestGetTweets(t *testing.T) {
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+
+	var batches [][]string
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/TweetResultsByRestIds") {
+			var variables struct {
+				TweetIDs []string `json:"tweetIds"`
+			}
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			batches = append(batches, variables.TweetIDs)
+			body := tweetResultsJSON(variables.TweetIDs)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	ids := []string{"0", "99"}
+	for i := 1; i <= MaxTweetsPerRequest; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	ids = append(ids, "5")
+
+	tweets, errs := client.GetTweets(ids)
+	if len(batches) != 2 || len(batches[0]) != MaxTweetsPerRequest || len(batches[1]) != 2 {
+		t.Errorf("Expected 2 batches, got %v", batches)
+	}
+	if len(tweets) != MaxTweetsPerRequest || len(errs) != 2 {
+		t.Fatalf("Expected %d tweets and 2 errors, got %d and %v", MaxTweetsPerRequest, len(tweets), errs)
+	}
+	if tweet := tweets["5"]; tweet == nil || tweet.ID != "5" || tweet.Text != "Tweet 5" || tweet.Likes != 5 {
+		t.Errorf("Unexpected tweet: %+v", tweet)
+	}
+
+	var notFoundErr *NotFoundError
+	if !errors.As(errs["0"], &notFoundErr) || notFoundErr.Reason != "This Post was deleted by the Post author." {
+		t.Errorf("Expected NotFoundError with tombstone reason, got %v", errs["0"])
+	}
+	if !errors.Is(errs["99"], ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing tweet, got %v", errs["99"])
+	}
+
+	failing := newTestClient(http.StatusInternalServerError, "", WithRetryBudget(0, 0))
+	defer failing.Close()
+	_, errs = failing.GetTweets([]string{"1", "2"})
+	var statusErr *StatusError
+	if len(errs) != 2 || !errors.As(errs["2"], &statusErr) {
+		t.Errorf("Expected request error for every ID, got %v", errs)
+	}
+}
+
+func TestRefreshMetrics(t *testing.T) {
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/TweetResultsByRestIds") {
+			var variables struct {
+				TweetIDs []string `json:"tweetIds"`
+			}
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			body := tweetResultsJSON(variables.TweetIDs)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	stored := []Tweet{{ID: "7", Likes: 2, Retweets: 1}, {ID: "0", Likes: 10}}
+	deltas, errs := client.RefreshMetrics(stored)
+	expected := map[string]MetricsDelta{
+		"7": {Current: Metrics{Likes: 7}, Change: Metrics{Likes: 5, Retweets: -1}},
+	}
+	if !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("Unexpected deltas: %+v", deltas)
+	}
+	if len(errs) != 1 || !errors.Is(errs["0"], ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for deleted tweet, got %v", errs)
+	}
+}
+
+func TestCheckTweets(t *testing.T) {
+	results := map[string]string{
+		"1": `{"result":{"__typename":"Tweet","rest_id":"1","legacy":{"full_text":"Available"}}}`,
+		"2": `{"result":{"__typename":"Tweet","rest_id":"2","legacy":{"full_text":"Withheld","withheld_in_countries":["DE"]}}}`,
+		"3": `{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post was deleted by the Post author. Learn more"}}}}`,
+		"4": `{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post is from a suspended account. Learn more"}}}}`,
+		"5": `{"result":{"__typename":"TweetUnavailable","reason":"Protected"}}`,
+		"6": `{"result":{"__typename":"TweetUnavailable","reason":"NsfwLoggedOut"}}`,
+		"7": `{}`,
+	}
+
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/TweetResultsByRestIds") {
+			var variables struct {
+				TweetIDs []string `json:"tweetIds"`
+			}
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			var items []string
+			for _, id := range variables.TweetIDs {
+				items = append(items, results[id])
+			}
+			body := `{"data":{"tweetResult":[` + strings.Join(items, ",") + `]}}`
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	statuses := client.CheckTweets([]string{"1", "2", "3", "4", "5", "6", "7"})
+	expected := map[string]Status{
+		"1": StatusExists,
+		"2": StatusWithheld,
+		"3": StatusDeleted,
+		"4": StatusSuspended,
+		"5": StatusProtected,
+		"6": StatusUnavailable,
+		"7": StatusNotFound,
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+
+	failing := newTestClient(http.StatusTooManyRequests, "")
+	defer failing.Close()
+	if statuses := failing.CheckTweets([]string{"1"}); statuses["1"] != StatusUnknown {
+		t.Errorf("Expected StatusUnknown for failed check, got %v", statuses)
+	}
+}
+
+func TestCheckUser(t *testing.T) {
+	userJSON := func(id, username string) string {
+		return fmt.Sprintf(`{"data":{"user":{"result":{"__typename":"User","rest_id":"%s","core":{"screen_name":"%s"}}}}}`, id, username)
+	}
+	suspendedJSON := `{"data":{"user":{"result":{"__typename":"UserUnavailable","reason":"Suspended","message":"User is suspended"}}}}`
+	byScreenName := map[string]string{
+		"active": userJSON("1", "Active"),
+		"banned": suspendedJSON,
+		"taken":  userJSON("5", "taken"),
+	}
+	byID := map[string]string{
+		"2": userJSON("2", "newname"),
+		"4": userJSON("4", "moved"),
+		"6": suspendedJSON,
+	}
+
+	client := newTestClient(http.StatusOK, "")
+	defer client.Close()
+	defer userIDCache.Delete("newname")
+	defer userIDCache.Delete("moved")
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var variables map[string]any
+		json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+		var body string
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/UserByScreenName"):
+			body = byScreenName[variables["screen_name"].(string)]
+		case strings.HasSuffix(req.URL.Path, "/UserByRestId"):
+			body = byID[variables["userId"].(string)]
+		default:
+			return graphQLTransport.RoundTrip(req)
+		}
+		if body == "" {
+			body = `{"data":{}}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+
+	// Known IDs of previously resolved usernames
+	known := map[string]string{"active": "1", "oldname": "2", "gone": "3", "taken": "4", "banned2": "6"}
+	for username, userID := range known {
+		userIDCache.Store(username, &userIDCacheEntry{UserID: userID, Timestamp: time.Now()})
+		defer userIDCache.Delete(username)
+	}
+
+	tests := []struct {
+		username string
+		expected Status
+	}{
+		{"@active", StatusActive},
+		{"banned", StatusSuspended},
+		{"ghost", StatusNotFound},
+		{"oldname", StatusRenamed},
+		{"gone", StatusDeactivated},
+		{"taken", StatusRenamed},
+		{"banned2", StatusSuspended},
+	}
+	for _, tt := range tests {
+		status, err := client.CheckUser(tt.username)
+		if err != nil {
+			t.Errorf("CheckUser(%q) failed: %v", tt.username, err)
+			continue
+		}
+		if status != tt.expected {
+			t.Errorf("CheckUser(%q) = %s, expected %s", tt.username, status, tt.expected)
+		}
+	}
+
+	// Stored IDs work with cold cache and give the current username
+	byIDTests := []struct {
+		username, userID string
+		status           Status
+		current          string
+	}{
+		{"active", "1", StatusActive, "Active"},
+		{"stored", "4", StatusRenamed, "moved"},
+		{"lost", "3", StatusDeactivated, ""},
+		{"nobody", "", StatusNotFound, ""},
+	}
+	for _, tt := range byIDTests {
+		status, current, err := client.CheckUserByID(tt.username, tt.userID)
+		if err != nil || status != tt.status || current != tt.current {
+			t.Errorf("CheckUserByID(%q, %q) = %s, %q, %v, expected %s, %q",
+				tt.username, tt.userID, status, current, err, tt.status, tt.current)
+		}
+	}
+
+	failing := newTestClient(http.StatusTooManyRequests, "")
+	defer failing.Close()
+	if status, err := failing.CheckUser("unseen"); err == nil || status != StatusUnknown {
+		t.Errorf("Expected StatusUnknown with error, got %s, %v", status, err)
+	}
+}
+
+func TestTweetViews(t *testing.T) {
+	var tweetResult TweetResult
+	data := `{"rest_id":"1","legacy":{"full_text":"Viewed"},"views":{"count":"12345","state":"EnabledWithCount"}}`
+	if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if tweet := convertTweetResult(&tweetResult); tweet.Views != 12345 {
+		t.Errorf("Expected 12345 views, got %d", tweet.Views)
+	}
+}
+
+func TestRawJSON(t *testing.T) {
+	client := newTestClient(http.StatusOK, timelinePageJSON("c1", "1", "2"))
+	defer client.Close()
+
+	collector, err := client.fetchUserTweets("42", "", 20)
+	if err != nil {
+		t.Fatalf("fetchUserTweets failed: %v", err)
+	}
+	for _, tweet := range collector.tweets() {
+		if tweet.Raw != nil {
+			t.Errorf("Expected no raw JSON by default, got %s", tweet.Raw)
+		}
+	}
+
+	WithRawJSON()(client)
+	collector, err = client.fetchUserTweets("42", "", 20)
+	if err != nil {
+		t.Fatalf("fetchUserTweets failed: %v", err)
+	}
+	tweets := collector.tweets()
+	if len(tweets) != 2 {
+		t.Fatalf("Expected 2 tweets, got %d", len(tweets))
+	}
+	for _, tweet := range tweets {
+		var raw struct {
+			RestID string `json:"rest_id"`
+			Legacy struct {
+				UserIDStr string `json:"user_id_str"`
+			} `json:"legacy"`
+		}
+		if err := json.Unmarshal(tweet.Raw, &raw); err != nil {
+			t.Fatalf("Raw JSON of tweet %s is invalid: %v", tweet.ID, err)
+		}
+		if raw.RestID != tweet.ID || raw.Legacy.UserIDStr != "42" {
+			t.Errorf("Unexpected raw JSON of tweet %s: %s", tweet.ID, tweet.Raw)
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	defer client.Close()
+	WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))(client)
+
+	if _, err := client.fetchUserTweets("42", "", 20); err != nil {
+		t.Fatalf("fetchUserTweets failed: %v", err)
+	}
+
+	logs := buf.String()
+	for _, expected := range []string{"guest token refreshed", "msg=request", "status=200", "guest_token=***7890"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("Expected %q in logs:\n%s", expected, logs)
+		}
+	}
+	if strings.Contains(logs, "1234567890") || strings.Contains(logs, BearerToken) {
+		t.Errorf("Tokens are not redacted in logs:\n%s", logs)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("https://api.x.com/1.1/statuses.json?guest_token=1234567890&id=1")
+	if redacted := redactURL(u); redacted != "https://api.x.com/1.1/statuses.json?guest_token=%2A%2A%2A7890&id=1" {
+		t.Errorf("Unexpected redacted URL: %s", redacted)
+	}
+	if redactToken("short") != "*****" || redactToken("") != "" {
+		t.Errorf("Unexpected redaction of short tokens")
+	}
+}
+
+func TestWithDumpDir(t *testing.T) {
+	dir := t.TempDir()
+	client := newTestClient(http.StatusOK, testTimelineJSON)
+	defer client.Close()
+	WithDumpDir(dir)(client)
+
+	if _, err := client.fetchUserTweets("42", "", 20); err != nil {
+		t.Fatalf("fetchUserTweets failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"0001-activate", "0002-UserTweets"}) {
+		t.Fatalf("Unexpected dump directories: %v", names)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "0002-UserTweets", "request.json"))
+	if err != nil {
+		t.Fatalf("Failed to read request dump: %v", err)
+	}
+	if strings.Contains(string(data), "1234567890") || strings.Contains(string(data), BearerToken) {
+		t.Errorf("Tokens are not redacted in dump:\n%s", data)
+	}
+	var dump struct {
+		Query struct {
+			Variables struct {
+				UserID string `json:"userId"`
+			} `json:"variables"`
+		} `json:"query"`
+		Status       int    `json:"status"`
+		ResponseBody string `json:"response_body"`
+	}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatalf("Invalid request dump: %v", err)
+	}
+	if dump.Query.Variables.UserID != "42" || dump.Status != http.StatusOK || dump.ResponseBody != "response.json" {
+		t.Errorf("Unexpected request dump:\n%s", data)
+	}
+
+	body, err := os.ReadFile(filepath.Join(dir, "0002-UserTweets", "response.json"))
+	if err != nil || string(body) != testTimelineJSON {
+		t.Errorf("Unexpected response dump: %v", err)
+	}
+}
+
+// fixedClock is a Clock stopped at the given time
+type fixedClock struct{ now time.Time }
+
+func (c *fixedClock) Now() time.Time                { return c.now }
+func (c *fixedClock) Ticker(d time.Duration) Ticker { return realClock{}.Ticker(d) }
+
+func TestRemoveExpiredCache(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := NewClient(WithClock(clock))
+	defer client.Close()
+
+	userIDCache.Store("fresh-user", &userIDCacheEntry{UserID: "1", Timestamp: clock.now})
+	userIDCache.Store("stale-user", &userIDCacheEntry{UserID: "2", Timestamp: clock.now.Add(-time.Hour)})
+	defer userIDCache.Delete("fresh-user")
+	defer userIDCache.Delete("stale-user")
+
+	clock.now = clock.now.Add(client.cacheTTL - time.Minute)
+	client.removeExpiredCache()
+	if _, ok := userIDCache.Load("stale-user"); ok {
+		t.Error("Expected stale entry to be removed")
+	}
+	if _, ok := userIDCache.Load("fresh-user"); !ok {
+		t.Error("Expected fresh entry to be kept")
+	}
+}
+
+func TestErrorTaxonomy(t *testing.T) {
+	reset := time.Unix(1700000000, 0)
+	client := newTestClient(http.StatusTooManyRequests, `{}`)
+	defer client.Close()
+	transport := client.transport
+	client.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := transport.RoundTrip(req)
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			resp.Header.Set("X-Rate-Limit-Reset", "1700000000")
+		}
+		return resp, err
+	})
+	client.buildTransportChain()
+
+	_, err := client.GetUserTweets("42")
+	if at, ok := IsRateLimited(err); !ok || !at.Equal(reset) || !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected rate limit resetting at %v, got %v, %v (%v)", reset, at, ok, err)
+	}
+
+	notFound := newTestClient(http.StatusOK, `{"data":{}}`)
+	defer notFound.Close()
+	if _, err := notFound.GetUserID("missing"); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	tests := []struct {
+		err         error
+		rateLimited bool
+		notFound    bool
+		blocked     bool
+	}{
+		{fmt.Errorf("wrapped: %w", NewRateLimitError(reset)), true, false, false},
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, true, false, false},
+		{NewNotFoundError("tweet", "1"), false, true, false},
+		{&StatusError{StatusCode: http.StatusForbidden}, false, false, true},
+		{&ChallengeError{StatusCode: http.StatusOK}, false, false, true},
+		{errors.New("rate limit exceeded"), false, false, false},
+		{nil, false, false, false},
+	}
+	for _, tt := range tests {
+		if _, ok := IsRateLimited(tt.err); ok != tt.rateLimited {
+			t.Errorf("IsRateLimited(%v) = %v, want %v", tt.err, ok, tt.rateLimited)
+		}
+		if IsNotFound(tt.err) != tt.notFound {
+			t.Errorf("IsNotFound(%v) = %v, want %v", tt.err, !tt.notFound, tt.notFound)
+		}
+		if IsAccessBlocked(tt.err) != tt.blocked {
+			t.Errorf("IsAccessBlocked(%v) = %v, want %v", tt.err, !tt.blocked, tt.blocked)
+		}
+	}
+	if err := NewNotFoundError("tweet", "1"); err.Error() != "tweet not found: 1" {
+		t.Errorf("Unexpected message: %v", err)
+	}
+}
+
+func TestFilterTweets(t *testing.T) {
+	tweets := []Tweet{
+		{ID: "1", Text: "Hello Go", Lang: "en", CreatedAt: "Mon Jan 01 00:00:00 +0000 2024", Likes: 5},
+		{ID: "2", Text: "Привет", Lang: "ru", CreatedAt: "Tue Jan 02 00:00:00 +0000 2024", Likes: 50, Images: []string{"https://pbs.twimg.com/media/1.jpg"}},
+		{ID: "3", Text: "Go release", Lang: "en", CreatedAt: "Wed Jan 03 00:00:00 +0000 2024", Likes: 100, IsRetweet: true,
+			Media: []Media{{Type: MediaVideo}}},
+		{ID: "4", Text: "Reply", Lang: "EN", CreatedAt: "invalid", IsReply: true},
+	}
+	jan2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{"HasMedia", HasMedia(), []string{"2", "3"}},
+		{"LangIs", LangIs("en"), []string{"1", "3", "4"}},
+		{"After", After(jan2), []string{"3"}},
+		{"Before", Before(jan2), []string{"1"}},
+		{"MatchesRegexp", MatchesRegexp(regexp.MustCompile(`\bGo\b`)), []string{"1", "3"}},
+		{"MinLikes", MinLikes(50), []string{"2", "3"}},
+		{"IsOriginal", IsOriginal(), []string{"1", "2"}},
+		{"And", And(LangIs("en"), MinLikes(10)), []string{"3"}},
+		{"Or", Or(LangIs("ru"), MinLikes(100)), []string{"2", "3"}},
+		{"Not", Not(HasMedia()), []string{"1", "4"}},
+		{"Nested", And(IsOriginal(), Or(HasMedia(), Not(LangIs("ru")))), []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		if ids := tweetIDs(FilterTweets(tweets, tt.filter)); !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids, tt.expected)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"This is the best thing you have seen in the world":       "en",
+		"Hoy es un día muy bueno para los amigos de la casa":      "es",
+		"Je pense que le monde est beau et que la vie est courte": "fr",
+		"Das ist nicht gut und ich weiß es auch":                  "de",
+		"Привет, как дела? Всё хорошо":                            "ru",
+		"Привіт, як справи? Все добре, дякую":                     "uk",
+		"今日はとても良い天気ですね":                                           "ja",
+		"今天天气很好":                          "zh",
+		"오늘 날씨가 좋네요":                      "ko",
+		"مرحبا بكم في العالم":             "ar",
+		"Καλημέρα κόσμε":                  "el",
+		"@user #hashtag https://t.co/abc": "",
+		"lol xd":                          "",
+		"😀🎉":                              "",
+	}
+	for text, expected := range tests {
+		if lang := detectLanguage(text); lang != expected {
+			t.Errorf("detectLanguage(%q) = %q, want %q", text, lang, expected)
+		}
+	}
+
+	data := `{"rest_id":"1","legacy":{"full_text":"Это очень хороший день","lang":"und"}}`
+	for _, detect := range []bool{false, true} {
+		var tweetResult TweetResult
+		if err := json.Unmarshal([]byte(data), &tweetResult); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		processTweetResult(&tweetResult, renderOptions{detectLanguage: detect})
+		tweet := convertTweetResult(&tweetResult)
+		if matched := LangIs("ru")(&tweet); matched != detect {
+			t.Errorf("Detection %v: DetectedLang %q, LangIs(ru) = %v", detect, tweet.DetectedLang, matched)
+		}
+	}
+}
+
+func TestLinkDomains(t *testing.T) {
+	tests := map[string]string{
+		"https://www.example.com/path":     "example.com",
+		"https://blog.news.Example.COM/":   "example.com",
+		"https://news.bbc.co.uk/article":   "bbc.co.uk",
+		"http://user.github.io/repo":       "user.github.io",
+		"https://go.dev":                   "go.dev",
+		"https://localhost:8080/":          "localhost",
+		"http://192.168.1.1/admin":         "192.168.1.1",
+		"https://example.com./trailing":    "example.com",
+		"not a url":                        "",
+		"https://shop.example.com.au/item": "example.com.au",
+	}
+	for link, expected := range tests {
+		if domain := RegistrableDomain(link); domain != expected {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", link, domain, expected)
+		}
+	}
+
+	tweet := Tweet{URLs: []URL{
+		{Expanded: "https://www.bbc.co.uk/news"},
+		{Expanded: "https://news.bbc.co.uk/sport"},
+		{Expanded: "https://github.com/golang/go"},
+	}}
+	if domains := tweet.LinkDomains(); !reflect.DeepEqual(domains, []string{"bbc.co.uk", "github.com"}) {
+		t.Errorf("Unexpected link domains: %v", domains)
+	}
+}
+
+// updateGolden regenerates golden files of parser corpus: go test -run TestCorpusGolden -update
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestCorpusGolden(t *testing.T) {
+	files, err := filepath.Glob("twittertest/testdata/corpus/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Corpus not found: %v", err)
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file, ".golden.json") {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			collector := &timelineCollector{}
+			if err := decodeTimeline(bytes.NewReader(data), collector.addEntry); err != nil {
+				t.Fatalf("decodeTimeline() failed: %v", err)
+			}
+			got, err := json.MarshalIndent(collector.tweets(), "", " ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			goldenFile := strings.TrimSuffix(file, ".json") + ".golden.json"
+			if *updateGolden {
+				if err := os.WriteFile(goldenFile, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatalf("Golden file missing, run with -update: %v", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("Parsed tweets differ from %s, run with -update if the change is intended:\n%s", goldenFile, got)
+			}
+		})
+	}
+}
+
+// addCorpusSeeds adds recorded timeline payloads to fuzz corpus
+func addCorpusSeeds(f *testing.F) {
+	files, _ := filepath.Glob("twittertest/testdata/corpus/*.json")
+	files = append(files, "twittertest/testdata/user_tweets.json")
+	for _, file := range files {
+		if strings.HasSuffix(file, ".golden.json") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte(testTimelineJSON))
+	// Malformed entries with missing nested objects
+	f.Add([]byte(`{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[
+{"entryId":"profile-conversation-1","content":{"entryType":"TimelineTimelineModule","items":[{"entryId":"tweet-1"},{"entryId":"tweet-2","item":null}]}},
+{"entryId":"tweet-3","content":{"itemContent":null}},{"entryId":"tweet-4","content":{"itemContent":{"tweet_results":null}}}]},
+{"type":"TimelinePinEntry","entry":null},{"type":"TimelinePinEntry","entry":{"entryId":"tweet-5","content":{}}}]}}}}}}`))
+}
+
+func FuzzExtractTweetsFromTimeline(f *testing.F) {
+	addCorpusSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var timelineResp TimelineResponse
+		if err := json.Unmarshal(data, &timelineResp); err == nil {
+			extractTweetsFromTimeline(&timelineResp)
+		}
+
+		collector := &timelineCollector{render: renderOptions{trimMediaLinks: true, detectLanguage: true}, newestFirst: true}
+		if err := decodeTimeline(bytes.NewReader(data), collector.addEntry); err == nil {
+			BuildThreads(collector.tweets())
+		}
+	})
+}
+
+func FuzzProcessTweetResult(f *testing.F) {
+	f.Add([]byte(`{"rest_id":"1","legacy":{"full_text":"Hello #go $GO @user https://t.co/x","entities":{"hashtags":[{"text":"go","indices":[6,9]}],"symbols":[{"text":"GO","indices":[10,13]}],"user_mentions":[{"screen_name":"user","indices":[14,19]}],"urls":[{"url":"https://t.co/x","expanded_url":"https://go.dev","display_url":"go.dev","indices":[20,34]}]}}}`))
+	f.Add([]byte(`{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"1","legacy":{"full_text":"a\nb","lang":"und"}},"limitedActionResults":{"limited_actions":[{"action":"Reply"}]}}`))
+	f.Add([]byte(`{"rest_id":"1","legacy":{"full_text":"Quote"},"quoted_status_result":{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"Deleted"}}}}}`))
+	f.Add([]byte(`{"rest_id":"1","legacy":{"full_text":"RT @a: x","retweeted_status_id_str":"2"},"retweeted_status_result":{"result":{"rest_id":"2","legacy":{"full_text":"x"}}}}`))
+	f.Add([]byte(`{"rest_id":"1","legacy":{"full_text":"Hello","entities":{"hashtags":[{"text":"go","indices":[-5,100]}],"urls":[{"url":"x","indices":[4,2]}]},"extended_entities":{"media":[{"url":"Hello","indices":[3,1],"video_info":{"variants":[]}}]}}}`))
+	f.Add([]byte(`{"__typename":"TweetWithVisibilityResults","tweet":null,"quoted_status_result":{"result":{"__typename":"TweetWithVisibilityResults"}}}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range []renderOptions{{}, {trimMediaLinks: true, unescapeText: true, lineBreaks: LineBreakParagraphs, detectLanguage: true}} {
+			var tweetResult TweetResult
+			if err := json.Unmarshal(data, &tweetResult); err != nil {
+				return
+			}
+			result := unwrapTweetResult(&tweetResult)
+			processTweetResult(result, opts)
+			tweet := convertTweetResult(result)
+			tweet.LinkDomains()
+		}
+	})
+}
+
+func TestGetUserTweetsIfChanged(t *testing.T) {
+	body := timelinePageJSON("c1", "10", "9")
+	client := newTestClient(http.StatusOK, "")
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	pageCount := 0
+	client.OnPage(func(TimelinePage) { pageCount++ })
+
+	tweets, etag, err := client.GetUserTweetsIfChanged("42", "")
+	if err != nil || len(tweets) != 2 || etag == "" {
+		t.Fatalf("Unexpected first fetch: %d tweets, etag %q, error %v", len(tweets), etag, err)
+	}
+
+	// New cursors do not change entries
+	body = timelinePageJSON("c2", "10", "9")
+	tweets, sameETag, err := client.GetUserTweetsIfChanged("42", etag)
+	if !errors.Is(err, ErrNotModified) || tweets != nil || sameETag != etag {
+		t.Errorf("Expected ErrNotModified, got %d tweets, etag %q, error %v", len(tweets), sameETag, err)
+	}
+	if pageCount != 1 {
+		t.Errorf("Expected hooks to be skipped for unchanged timeline, got %d pages", pageCount)
+	}
+
+	body = timelinePageJSON("c3", "11", "10", "9")
+	tweets, newETag, err := client.GetUserTweetsIfChanged("42", etag)
+	if err != nil || len(tweets) != 3 || newETag == etag {
+		t.Errorf("Unexpected fetch of changed timeline: %d tweets, etag %q, error %v", len(tweets), newETag, err)
+	}
+}
+
+func TestWithPageDelay(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := jitterDuration(time.Second, 0.25); d < 750*time.Millisecond || d > 1250*time.Millisecond {
+			t.Fatalf("Jittered delay %v out of range", d)
+		}
+	}
+
+	pages := map[string]string{
+		"":   timelinePageJSON("c1", "3"),
+		"c1": timelinePageJSON("c2", "2"),
+		"c2": timelinePageJSON("c2", "1"),
+	}
+	client := newTestClient(http.StatusOK, "")
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			var variables map[string]any
+			json.Unmarshal([]byte(req.URL.Query().Get("variables")), &variables)
+			cursor, _ := variables["cursor"].(string)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(pages[cursor])), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+	WithPageDelay(50*time.Millisecond, 0)(client)
+
+	start := time.Now()
+	tweets, err := client.GetAllUserTweets("42", 0)
+	if err != nil || len(tweets) != 3 {
+		t.Fatalf("GetAllUserTweets() returned %d tweets, %v", len(tweets), err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected 2 delays between 3 pages, took %v", elapsed)
+	}
+}
+
+func TestWithMaxInFlight(t *testing.T) {
+	var inFlight, maxSeen atomic.Int64
+	client := NewClient()
+	client.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		for {
+			seen := maxSeen.Load()
+			if n <= seen || maxSeen.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	})
+	WithMaxInFlight(2)(client)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.httpClient.Get("https://api.x.com/graphql/test")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if maxSeen.Load() != 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", maxSeen.Load())
+	}
+
+	// Waiting requests are served by priority, then in FIFO order
+	limiter := &requestLimiter{limit: 1}
+	limiter.acquire(priorityNormal, nil)
+	order := make(chan string, 3)
+	for _, w := range []struct {
+		name     string
+		priority int
+	}{{"first", priorityNormal}, {"second", priorityNormal}, {"token", priorityGuestToken}} {
+		w := w
+		queued := limiter.queueLen()
+		go func() {
+			limiter.acquire(w.priority, nil)
+			order <- w.name
+			limiter.release()
+		}()
+		for limiter.queueLen() == queued {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	limiter.release()
+	var got []string
+	for i := 0; i < 3; i++ {
+		got = append(got, <-order)
+	}
+	if strings.Join(got, ",") != "token,first,second" {
+		t.Errorf("Unexpected order: %v", got)
+	}
+
+	// Cancelled waiter leaves the queue
+	limiter.acquire(priorityNormal, nil)
+	done := make(chan struct{})
+	close(done)
+	if limiter.acquire(priorityNormal, done) || limiter.queueLen() != 0 {
+		t.Error("Expected cancelled acquire to fail and leave the queue")
+	}
+	limiter.release()
+	if limiter.inFlight != 0 {
+		t.Errorf("Expected no requests in flight, got %d", limiter.inFlight)
+	}
+}
+
+// queueLen returns number of waiting requests
+func (l *requestLimiter) queueLen() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queue.Len()
+}
+
+// instantClock is a Clock whose tickers fire immediately
+type instantClock struct{}
+
+func (instantClock) Now() time.Time { return time.Now() }
+
+func (instantClock) Ticker(d time.Duration) Ticker {
+	ticker := realClock{}.Ticker(time.Hour)
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return instantTicker{Ticker: ticker, ch: ch}
+}
+
+type instantTicker struct {
+	Ticker
+	ch chan time.Time
+}
+
+func (t instantTicker) Chan() <-chan time.Time { return t.ch }
+
+func TestWithRetryBudget(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	calls := 0
+	client := newTestClient(http.StatusOK, "", WithClock(instantClock{}), WithRetryBudget(4, 0.5))
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			calls++
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(testTimelineJSON)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	// 4 tokens: the first failure leaves 3 and is retried, the second leaves 2 and is not
+	if _, err := client.GetUserTweets("42"); err == nil || calls != 2 {
+		t.Errorf("Expected 2 attempts and error, got %d attempts, %v", calls, err)
+	}
+
+	// Exhausted budget stops retries
+	calls = 0
+	if _, err := client.GetUserTweets("42"); err == nil || calls != 1 {
+		t.Errorf("Expected single attempt with exhausted budget, got %d", calls)
+	}
+
+	// Successes refill the budget
+	status = http.StatusOK
+	for i := 0; i < 10; i++ {
+		if _, err := client.GetUserTweets("42"); err != nil {
+			t.Fatalf("GetUserTweets() failed: %v", err)
+		}
+	}
+	status = http.StatusServiceUnavailable
+	calls = 0
+	client.GetUserTweets("42")
+	if calls != 2 {
+		t.Errorf("Expected retry after budget is refilled, got %d attempts", calls)
+	}
+
+	// Client errors are not retried
+	status = http.StatusNotFound
+	calls = 0
+	client.GetUserTweets("42")
+	if calls != 1 {
+		t.Errorf("Expected no retries of client errors, got %d attempts", calls)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://x.com/i/api/graphql", Err: err}
+	}
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{&StatusError{StatusCode: http.StatusBadGateway}, true},
+		{&StatusError{StatusCode: http.StatusNotFound}, false},
+		{urlError(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}), true},
+		{urlError(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), true},
+		{urlError(io.ErrUnexpectedEOF), true},
+		{urlError(context.DeadlineExceeded), true},
+		{urlError(context.Canceled), false},
+		{urlError(&net.DNSError{Err: "no such host", Name: "x.com", IsNotFound: true}), false},
+		{urlError(errors.New("tls: failed to verify certificate")), false},
+		{errors.New("invalid response"), false},
+	}
+	for _, tt := range tests {
+		if transient := isTransientError(tt.err); transient != tt.transient {
+			t.Errorf("isTransientError(%v) = %v, want %v", tt.err, transient, tt.transient)
+		}
+	}
+}
+
+func TestWithHedging(t *testing.T) {
+	primaryCancelled := make(chan struct{})
+	client := NewClient(WithClock(instantClock{}))
+	client.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host != HedgeHost {
+			<-req.Context().Done()
+			close(primaryCancelled)
+			return nil, req.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("hedged")), Request: req}, nil
+	})
+	WithHedging(time.Millisecond)(client)
+
+	resp, err := client.httpClient.Get(BaseURL + "/graphql/test")
+	if err != nil {
+		t.Fatalf("Hedged request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hedged" || resp.Request.URL.Host != HedgeHost {
+		t.Errorf("Expected response of hedged request, got %q from %s", body, resp.Request.URL.Host)
+	}
+	select {
+	case <-primaryCancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected losing request to be cancelled")
+	}
+
+	// Other hosts are not hedged
+	calls := 0
+	client.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+	client.buildTransportChain()
+	resp, err = client.httpClient.Get("https://pbs.twimg.com/media/a.jpg")
+	if err != nil || calls != 1 {
+		t.Errorf("Expected single request to media host, got %d, %v", calls, err)
+	}
+	resp.Body.Close()
+}
+
+func TestWithResponseCache(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var calls atomic.Int64
+	release := make(chan struct{})
+	close(release)
+	client := newTestClient(http.StatusOK, "", WithClock(clock), WithResponseCache(2, time.Minute))
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			calls.Add(1)
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(testTimelineJSON)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	fetch := func(userID string) {
+		t.Helper()
+		if tweets, err := client.GetUserTweets(userID); err != nil || len(tweets) != 2 {
+			t.Fatalf("GetUserTweets(%s) returned %d tweets, %v", userID, len(tweets), err)
+		}
+	}
+
+	fetch("1")
+	fetch("1")
+	if calls.Load() != 1 {
+		t.Errorf("Expected cached response, got %d calls", calls.Load())
+	}
+
+	// Least recently used entry is evicted
+	fetch("2")
+	fetch("1")
+	fetch("3")
+	fetch("1")
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls.Load())
+	}
+	fetch("2")
+	if calls.Load() != 4 {
+		t.Errorf("Expected evicted entry to be fetched again, got %d calls", calls.Load())
+	}
+
+	// Expired entry is fetched again
+	clock.now = clock.now.Add(2 * time.Minute)
+	fetch("1")
+	if calls.Load() != 5 {
+		t.Errorf("Expected expired entry to be fetched again, got %d calls", calls.Load())
+	}
+
+	// Concurrent misses share a single request
+	calls.Store(0)
+	release = make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetUserTweets("4")
+		}()
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("Expected concurrent misses to share request, got %d calls", calls.Load())
+	}
+}
+
+func TestGetUserTweetsOrStale(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	status := http.StatusOK
+	client := newTestClient(http.StatusOK, "", WithClock(clock))
+	graphQLTransport := client.httpClient.Transport
+	client.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(testTimelineJSON)), Request: req}, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+
+	tweets, age, err := client.GetUserTweetsOrStale("42", time.Hour)
+	if err != nil || len(tweets) != 2 || age != 0 {
+		t.Fatalf("Unexpected fresh timeline: %d tweets, age %v, error %v", len(tweets), age, err)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	for _, status = range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		tweets, age, err = client.GetUserTweetsOrStale("42", time.Hour)
+		if err != nil || len(tweets) != 2 || age != 30*time.Second {
+			t.Errorf("Expected stale timeline on status %d, got %d tweets, age %v, error %v", status, len(tweets), age, err)
+		}
+	}
+
+	// Stale copy is not served for other errors, other users or beyond maxStale
+	status = http.StatusNotFound
+	if _, _, err = client.GetUserTweetsOrStale("42", time.Hour); err == nil {
+		t.Error("Expected error for client error status")
+	}
+	status = http.StatusServiceUnavailable
+	if _, _, err = client.GetUserTweetsOrStale("43", time.Hour); err == nil {
+		t.Error("Expected error without cached timeline")
+	}
+	if _, _, err = client.GetUserTweetsOrStale("42", 10*time.Second); err == nil {
+		t.Error("Expected error for timeline older than maxStale")
+	}
+}
+
+func TestStats(t *testing.T) {
+	status := http.StatusBadRequest
+	client := newTestClient(http.StatusOK, "")
+	graphQLTransport := client.transport
+	client.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/graphql/") {
+			body := testTimelineJSON
+			if status == http.StatusBadRequest {
+				body = `{"errors":[{"message":"The following features cannot be null: new_feature_enabled"}]}`
+			}
+			resp := &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Request: req}
+			status = http.StatusOK
+			return resp, nil
+		}
+		return graphQLTransport.RoundTrip(req)
+	})
+	client.buildTransportChain()
+
+	if _, err := client.GetUserTweets("42"); err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+
+	stats := client.Stats()
+	timeline := stats["UserTweets"]
+	if timeline.Calls != 2 || timeline.Errors != 1 || timeline.Retries != 1 {
+		t.Errorf("Unexpected UserTweets stats: %+v", timeline)
+	}
+	if timeline.Bytes < int64(len(testTimelineJSON)) {
+		t.Errorf("Expected at least %d bytes, got %d", len(testTimelineJSON), timeline.Bytes)
+	}
+	if guest := stats["/1.1/guest/activate.json"]; guest.Calls != 1 || guest.Errors != 0 {
+		t.Errorf("Unexpected guest token stats: %+v", guest)
+	}
 }
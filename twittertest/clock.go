@@ -0,0 +1,86 @@
+package twittertest
+
+import (
+	"sync"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Clock is a manually advanced twittertimeline.Clock for deterministic tests of cache expiration
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewClock creates a clock stopped at the given time
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Ticker creates a ticker firing when the clock is advanced past its period
+func (c *Clock) Ticker(d time.Duration) twittertimeline.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ticker := &fakeTicker{clock: c, ch: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Tickers returns number of active tickers, e.g. to wait until a background goroutine has started
+func (c *Clock) Tickers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tickers)
+}
+
+// Advance moves the clock forward and fires due tickers.
+// Like time.Ticker, a ticker drops ticks its reader is not ready for.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, ticker := range c.tickers {
+		for !ticker.next.After(c.now) {
+			select {
+			case ticker.ch <- ticker.next:
+			default:
+			}
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+}
+
+// fakeTicker is a ticker of Clock
+type fakeTicker struct {
+	clock  *Clock
+	ch     chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, ticker := range t.clock.tickers {
+		if ticker == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package twittertest provides an HTTP server emulating Twitter/X API with recorded fixtures,
+// so that code using twittertimeline can be tested offline and deterministically
+package twittertest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// Recorded fixtures served by default
+const (
+	UserID     = "42"   // ID of the fixture user
+	ScreenName = "test" // Screen name of the fixture user
+	GuestToken = "1234567890123456789"
+)
+
+//go:embed testdata/*.json
+var fixtures embed.FS
+
+// Fixture returns content of recorded fixture file from testdata, e.g. "user_tweets.json"
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("testdata/" + name)
+	if err != nil {
+		panic("twittertest: " + err.Error())
+	}
+	return data
+}
+
+// emptyTimeline is returned for users without timeline
+const emptyTimeline = `{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[]}}}}}}`
+
+// Server is an httptest server emulating guest activation, UserByScreenName, UserByRestId
+// and UserTweets endpoints. It serves the recorded fixture user by default.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	users     map[string][]byte // UserByScreenName responses by lowercase screen name
+	usersByID map[string][]byte // UserByRestId responses by user ID
+	timelines map[string][]byte // UserTweets responses by user ID
+	requests  []string
+}
+
+// NewServer starts server with the recorded fixture user. It should be closed when finished.
+func NewServer() *Server {
+	s := &Server{
+		users:     make(map[string][]byte),
+		usersByID: make(map[string][]byte),
+		timelines: make(map[string][]byte),
+	}
+	s.SetUser(ScreenName, UserID, Fixture("user_by_screen_name.json"))
+	s.SetTimeline(UserID, Fixture("user_tweets.json"))
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// SetUser sets user response returned by UserByScreenName and UserByRestId queries
+func (s *Server) SetUser(screenName, userID string, response []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[strings.ToLower(screenName)] = response
+	s.usersByID[userID] = response
+}
+
+// SetTimeline sets response returned by UserTweets query for user ID
+func (s *Server) SetTimeline(userID string, response []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timelines[userID] = response
+}
+
+// Requests returns paths of requests served so far, e.g. "/1.1/guest/activate.json" or "UserTweets"
+// for GraphQL queries
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Middleware redirects client requests of any API host to the server
+func (s *Server) Middleware() twittertimeline.Middleware {
+	serverURL, _ := url.Parse(s.URL)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.URL.Scheme = serverURL.Scheme
+			req.URL.Host = serverURL.Host
+			req.Host = serverURL.Host
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Client returns a new client sending all requests to the server
+func (s *Server) Client(opts ...twittertimeline.Option) *twittertimeline.Client {
+	// Local server needs no politeness delay between pages
+	opts = append([]twittertimeline.Option{twittertimeline.WithPageDelay(0, 0)}, opts...)
+	client := twittertimeline.NewClient(opts...)
+	client.Use(s.Middleware())
+	return client
+}
+
+// handle routes API requests to emulated endpoints
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/1.1/guest/activate.json" {
+		s.record(r.URL.Path)
+		writeJSON(w, []byte(`{"guest_token":"`+GuestToken+`"}`))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/graphql/") {
+		s.record(r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	operation := path.Base(r.URL.Path)
+	s.record(operation)
+
+	var variables map[string]any
+	json.Unmarshal([]byte(r.URL.Query().Get("variables")), &variables)
+	stringVar := func(name string) string {
+		value, _ := variables[name].(string)
+		return value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch operation {
+	case "UserByScreenName":
+		writeJSON(w, responseOr(s.users[strings.ToLower(stringVar("screen_name"))], `{"data":{}}`))
+	case "UserByRestId":
+		writeJSON(w, responseOr(s.usersByID[stringVar("userId")], `{"data":{}}`))
+	case "UserTweets":
+		// Following pages of recorded timeline are empty
+		if stringVar("cursor") != "" {
+			writeJSON(w, []byte(emptyTimeline))
+			return
+		}
+		writeJSON(w, responseOr(s.timelines[stringVar("userId")], emptyTimeline))
+	default:
+		http.Error(w, `{"errors":[{"message":"Unknown operation"}]}`, http.StatusNotFound)
+	}
+}
+
+// record stores path of served request
+func (s *Server) record(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, path)
+}
+
+// responseOr returns response or fallback if response is not set
+func responseOr(response []byte, fallback string) []byte {
+	if response == nil {
+		return []byte(fallback)
+	}
+	return response
+}
+
+// writeJSON writes JSON response
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,191 @@
+package twittertest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	user, err := client.GetUserProfile("@Test")
+	if err != nil {
+		t.Fatalf("GetUserProfile() failed: %v", err)
+	}
+	if user.ID != UserID || user.Username != ScreenName || user.Followers != 1000 {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	tweets, err := client.GetUserTweets(UserID)
+	if err != nil {
+		t.Fatalf("GetUserTweets() failed: %v", err)
+	}
+	if len(tweets) == 0 || !tweets[0].IsPinned {
+		t.Errorf("Expected recorded timeline with pinned tweet, got %d tweets", len(tweets))
+	}
+
+	all, err := client.GetAllUserTweets(UserID, 0)
+	if err != nil || len(all) != len(tweets) {
+		t.Errorf("GetAllUserTweets() returned %d tweets, %v; want %d", len(all), err, len(tweets))
+	}
+
+	if _, err := client.GetUserProfile("missing"); err == nil {
+		t.Error("Expected error for unknown user")
+	}
+	if tweets, err := client.GetUserTweets("1"); err != nil || len(tweets) != 0 {
+		t.Errorf("Expected empty timeline for unknown user, got %d tweets, %v", len(tweets), err)
+	}
+
+	requests := server.Requests()
+	if len(requests) == 0 || requests[0] != "/1.1/guest/activate.json" {
+		t.Errorf("Expected guest activation first, got %v", requests)
+	}
+
+	server.SetTimeline("7", []byte(`{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[
+{"entryId":"tweet-1","content":{"entryType":"TimelineTimelineItem","itemContent":{"tweet_results":{"result":{"rest_id":"1","legacy":{"full_text":"Custom"}}}}}}]}]}}}}}}`))
+	if tweets, err := client.GetUserTweets("7"); err != nil || len(tweets) != 1 || tweets[0].Text != "Custom" {
+		t.Errorf("Unexpected custom timeline: %+v, %v", tweets, err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	dir := t.TempDir()
+
+	// Record responses of the live (mock) server
+	recorder := NewRecorder(dir, ModeRecord)
+	client := twittertimeline.NewClient()
+	defer client.Close()
+	client.Use(recorder.Middleware(), server.Middleware())
+	recorded, err := client.GetUserTweets(UserID)
+	if err != nil {
+		t.Fatalf("GetUserTweets() while recording failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 recordings, got %v", files)
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "Set-Cookie") || strings.Contains(string(data), "Authorization") {
+			t.Errorf("Recording %s contains sensitive headers", file)
+		}
+	}
+
+	// Replay without server
+	server.Close()
+	replayer := NewRecorder(dir, ModeReplay)
+	replayClient := twittertimeline.NewClient()
+	defer replayClient.Close()
+	replayClient.Use(replayer.Middleware())
+	replayed, err := replayClient.GetUserTweets(UserID)
+	if err != nil {
+		t.Fatalf("GetUserTweets() while replaying failed: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("Replayed tweets differ from recorded ones")
+	}
+
+	if _, err := replayClient.GetUserTweets("1"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded for missing recording, got %v", err)
+	}
+}
+
+func TestRecorderRedactsTokens(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir, ModeRecordMissing)
+	live := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}, "Set-Cookie": {"gt=secret"}},
+			Body:       io.NopCloser(strings.NewReader(`{"guest_token":"secret"}`)),
+			Request:    req,
+		}, nil
+	})
+
+	transport := recorder.Middleware()(live)
+	req, _ := http.NewRequest("POST", "https://api.x.com/1.1/guest/activate.json", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() failed: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != `{"guest_token":"secret"}` {
+		t.Errorf("Live response altered: %s", body)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "activate-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected recording of guest activation, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("Recording contains token: %s", data)
+	}
+
+	// Recorded response is replayed without calling live transport
+	transport = recorder.Middleware()(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("Live transport called for recorded request")
+		return nil, errors.New("unexpected request")
+	}))
+	if resp, err := transport.RoundTrip(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Replay failed: %v", err)
+	}
+}
+
+func TestClock(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	clock := NewClock(time.Now())
+	client := server.Client(twittertimeline.WithClock(clock))
+	defer client.Close()
+
+	countLookups := func() int {
+		count := 0
+		for _, request := range server.Requests() {
+			if request == "UserByScreenName" {
+				count++
+			}
+		}
+		return count
+	}
+
+	for i := 0; i < 2; i++ {
+		if id, err := client.GetUserID(ScreenName); err != nil || id != UserID {
+			t.Fatalf("GetUserID() = %q, %v", id, err)
+		}
+	}
+	if count := countLookups(); count > 1 {
+		t.Fatalf("Expected cached user ID, got %d lookups", count)
+	}
+	lookups := countLookups()
+
+	// Cache cleanup runs in background goroutine started with the client
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.Tickers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(25 * time.Hour)
+	for countLookups() == lookups && time.Now().Before(deadline) {
+		if _, err := client.GetUserID(ScreenName); err != nil {
+			t.Fatalf("GetUserID() failed: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if countLookups() != lookups+1 {
+		t.Errorf("Expected expired user ID to be requested again")
+	}
+}
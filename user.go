@@ -0,0 +1,299 @@
+package twittertimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// avatarSizeRegex matches size suffix of profile image file name
+var avatarSizeRegex = regexp.MustCompile(`_(normal|bigger|mini|x96|\d+x\d+)(\.\w+)?$`)
+
+// Profile image sizes for User.AvatarURLSize
+const (
+	AvatarOriginal = ""        // Uploaded image
+	AvatarNormal   = "normal"  // 48x48
+	AvatarBigger   = "bigger"  // 73x73
+	Avatar200      = "200x200" // 200x200
+	Avatar400      = "400x400" // 400x400
+)
+
+// Profile banner sizes for User.BannerURLSize
+const (
+	BannerOriginal = ""         // Uploaded image
+	BannerSmall    = "300x100"  // 300x100
+	BannerMedium   = "600x200"  // 600x200
+	BannerWeb      = "1080x360" // 1080x360
+	BannerLarge    = "1500x500" // 1500x500
+)
+
+// User is a user profile
+type User struct {
+	// Identity
+	ID       string // RestID
+	Username string // Username (@username)
+	Name     string // Display name
+
+	// Profile
+	Bio       string // Description with links expanded
+	BioHTML   string // HTML version of description with links
+	BioURLs   []URL  // Links in description
+	Location  string // Free-form location
+	Website   string // Expanded website link
+	CreatedAt string // Join date
+	AvatarURL string // Profile image URL in original size
+	BannerURL string // Profile banner URL in original size
+
+	// Statistics
+	Followers int // FollowersCount
+	Following int // FriendsCount
+	Tweets    int // StatusesCount
+	Likes     int // FavouritesCount
+	Media     int // MediaCount
+	Listed    int // ListedCount
+
+	// Status
+	IsVerified     bool       // Legacy verification, granted before paid checkmarks
+	IsBlueVerified bool       // Paid checkmark (X Premium)
+	VerifiedType   string     // Organization checkmark type: VerifiedTypeBusiness or VerifiedTypeGovernment
+	Affiliate      *Affiliate // Badge of organization the user is affiliated with, nil if none
+	IsProtected    bool       // Tweets are protected
+}
+
+// Organization verification types
+const (
+	VerifiedTypeBusiness   = "Business"   // Gold checkmark
+	VerifiedTypeGovernment = "Government" // Grey checkmark
+)
+
+// Affiliate is a badge of organization shown next to the name of affiliated user
+type Affiliate struct {
+	Name      string // Organization name
+	URL       string // Link to organization profile
+	BadgeURL  string // Badge image URL
+	LabelType string // Label type, e.g. "BusinessLabel"
+}
+
+// GetUserProfile gets full profile of user by username
+func (c *Client) GetUserProfile(username string) (*User, error) {
+	username = strings.ToLower(strings.TrimPrefix(username, "@"))
+
+	userResp, err := c.GetUserByScreenName(username)
+	if err != nil {
+		return nil, err
+	}
+
+	user := convertUserResult(&userResp.Data.User.Result)
+	userIDCache.Store(username, &userIDCacheEntry{
+		UserID:    user.ID,
+		Timestamp: c.clock.Now(),
+	})
+
+	return user, nil
+}
+
+// GetUserByID gets full profile of user by numeric user ID, e.g. Tweet.UserID
+func (c *Client) GetUserByID(userID string) (*User, error) {
+	variables := map[string]any{
+		"userId":                   userID,
+		"withSafetyModeUserFields": true,
+	}
+
+	fieldToggles := map[string]any{
+		"withAuxiliaryUserLabels": true,
+	}
+
+	resp, err := c.makeAPICall(UserByRestIDPath, variables, userFeatures(), fieldToggles)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var userResp UserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&userResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	if userResp.Data.User.Result.RestID == "" {
+		return nil, &NotFoundError{Kind: "user", ID: userID, Reason: userResp.Data.User.Result.Reason}
+	}
+
+	user := convertUserResult(&userResp.Data.User.Result)
+	if user.Username != "" {
+		userIDCache.Store(strings.ToLower(user.Username), &userIDCacheEntry{
+			UserID:    user.ID,
+			Timestamp: c.clock.Now(),
+		})
+	}
+
+	return user, nil
+}
+
+// convertUserResult converts UserResult to public User structure,
+// preferring fields of newer response objects over legacy ones
+func convertUserResult(result *UserResult) *User {
+	legacy := &result.Legacy
+
+	user := &User{
+		ID:             result.RestID,
+		Username:       firstNonEmpty(result.Core.ScreenName, legacy.ScreenName),
+		Name:           firstNonEmpty(result.Core.Name, legacy.Name),
+		Location:       firstNonEmpty(result.Location.Location, legacy.Location),
+		Website:        legacy.URL,
+		CreatedAt:      firstNonEmpty(result.Core.CreatedAt, legacy.CreatedAt),
+		AvatarURL:      originalAvatarURL(firstNonEmpty(result.Avatar.ImageURL, legacy.ProfileImageURLHTTPS)),
+		BannerURL:      legacy.ProfileBannerURL,
+		Followers:      legacy.FollowersCount,
+		Following:      legacy.FriendsCount,
+		Tweets:         legacy.StatusesCount,
+		Likes:          legacy.FavouritesCount,
+		Media:          legacy.MediaCount,
+		Listed:         legacy.ListedCount,
+		IsVerified:     result.Verification.Verified || legacy.Verified,
+		IsBlueVerified: result.IsBlueVerified,
+		VerifiedType:   firstNonEmpty(result.Verification.VerifiedType, legacy.VerifiedType),
+		IsProtected:    result.Privacy.Protected || legacy.Protected,
+	}
+
+	if label := result.AffiliatesHighlightedLabel.Label; label != nil {
+		user.Affiliate = &Affiliate{
+			Name:      label.Description,
+			URL:       label.URL.URL,
+			BadgeURL:  label.Badge.URL,
+			LabelType: label.UserLabelType,
+		}
+	}
+
+	// Expand t.co links in description and website
+	bio := html.UnescapeString(legacy.Description)
+	for _, entity := range legacy.Entities.Description.Urls {
+		expandedURL := firstNonEmpty(entity.ExpandedURL, entity.URL)
+		bio = strings.ReplaceAll(bio, entity.URL, expandedURL)
+		user.BioURLs = append(user.BioURLs, URL{
+			Short:    entity.URL,
+			Expanded: entity.ExpandedURL,
+			Display:  entity.DisplayURL,
+		})
+	}
+	user.Bio = bio
+	user.BioHTML = renderBio(legacy.Description, legacy.Entities.Description.Urls)
+	if urls := legacy.Entities.URL.Urls; len(urls) > 0 && urls[0].ExpandedURL != "" {
+		user.Website = urls[0].ExpandedURL
+	}
+
+	return user
+}
+
+// renderBio generates HTML of user description with t.co links replaced by their destinations
+// and linked hashtags, cashtags and mentions, using the same rendering as tweet text
+func renderBio(description string, urls []URLEntity) string {
+	tweetResult := &TweetResult{}
+	tweetResult.Legacy.FullText = description
+	entities := &tweetResult.Legacy.Entities
+	entities.Urls = urls
+	entities.Hashtags, entities.Symbols, entities.UserMentions = bioEntities(description, urls)
+	processTweetResult(tweetResult, renderOptions{})
+	return tweetResult.HTML
+}
+
+// bioEntities finds hashtags, cashtags and mentions in API description with escaped &, < and >,
+// since API returns only its link entities. Matches inside links or preceded by a word character
+// (e.g. in emails) or "&" (numeric character references) are skipped.
+func bioEntities(text string, urls []URLEntity) (hashtags, symbols []HashtagEntity, mentions []MentionEntity) {
+	find := func(re *regexp.Regexp, add func(value string, indices [2]int)) {
+		for _, match := range re.FindAllStringSubmatchIndex(text, -1) {
+			if prev, _ := utf8.DecodeLastRuneInString(text[:match[0]]); prev == '_' || prev == '&' || unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+				continue
+			}
+			start := utf8.RuneCountInString(text[:match[0]])
+			indices := [2]int{start, start + utf8.RuneCountInString(text[match[0]:match[1]])}
+			inLink := false
+			for _, entity := range urls {
+				if indices[0] < entity.Indices[1] && entity.Indices[0] < indices[1] {
+					inLink = true
+					break
+				}
+			}
+			if !inLink {
+				add(text[match[2]:match[3]], indices)
+			}
+		}
+	}
+
+	find(hashtagRegex, func(value string, indices [2]int) {
+		hashtags = append(hashtags, HashtagEntity{Text: value, Indices: indices})
+	})
+	find(cashtagRegex, func(value string, indices [2]int) {
+		symbols = append(symbols, HashtagEntity{Text: value, Indices: indices})
+	})
+	find(mentionRegex, func(value string, indices [2]int) {
+		mentions = append(mentions, MentionEntity{ScreenName: value, Indices: indices})
+	})
+	return hashtags, symbols, mentions
+}
+
+// AvatarURLSize returns profile image URL of the given size (AvatarNormal, Avatar400 etc.)
+func (u *User) AvatarURLSize(size string) string {
+	if u.AvatarURL == "" || size == AvatarOriginal {
+		return u.AvatarURL
+	}
+	ext := path.Ext(u.AvatarURL)
+	return strings.TrimSuffix(u.AvatarURL, ext) + "_" + size + ext
+}
+
+// BannerURLSize returns profile banner URL of the given size (BannerSmall, BannerLarge etc.)
+func (u *User) BannerURLSize(size string) string {
+	if u.BannerURL == "" || size == BannerOriginal {
+		return u.BannerURL
+	}
+	return u.BannerURL + "/" + size
+}
+
+// originalAvatarURL strips size suffix (e.g. "_normal") from profile image URL
+func originalAvatarURL(avatarURL string) string {
+	return avatarSizeRegex.ReplaceAllString(avatarURL, "$2")
+}
+
+// DownloadMedia downloads image or other media by URL (e.g. User.AvatarURL, Tweet.Images) into w
+// and returns the number of bytes written
+func (c *Client) DownloadMedia(mediaURL string, w io.Writer) (int64, error) {
+	req, err := http.NewRequest("GET", mediaURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("error reading response: %w", err)
+	}
+	return n, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,216 @@
+// Package webhook defines a versioned JSON payload for delivering tweets to webhooks
+// and its HMAC-SHA256 signature, so that consumers can validate deliveries and evolve safely
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+// SchemaVersion is the version of payload schema. It is incremented on incompatible changes only,
+// new optional fields may be added within a version.
+const SchemaVersion = 1
+
+// HTTP headers of webhook delivery
+const (
+	SignatureHeader = "X-Timeline-Signature" // HMAC-SHA256 of request body, "sha256=<hex>"
+	EventHeader     = "X-Timeline-Event"     // Event type of payload
+	VersionHeader   = "X-Timeline-Schema"    // Schema version of payload
+)
+
+// Event types
+const (
+	EventTweet = "tweet" // New tweet
+	EventPing  = "ping"  // Test delivery without tweet
+)
+
+// Schema is JSON Schema of the current payload version
+//
+//go:embed schema.json
+var Schema []byte
+
+// ErrInvalidSignature is returned when payload signature does not match
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Payload is a webhook delivery
+type Payload struct {
+	SchemaVersion int       `json:"schema_version"`
+	Event         string    `json:"event"`
+	ID            string    `json:"id"` // Delivery ID, the same for redeliveries of the event
+	Timestamp     time.Time `json:"timestamp"`
+	Tweet         *Tweet    `json:"tweet,omitempty"`
+}
+
+// Tweet is tweet representation of payload
+type Tweet struct {
+	ID             string    `json:"id"`
+	URL            string    `json:"url"`
+	Text           string    `json:"text"`
+	HTML           string    `json:"html,omitempty"`
+	Lang           string    `json:"lang,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	Author         Author    `json:"author"`
+	Metrics        Metrics   `json:"metrics"`
+	IsRetweet      bool      `json:"is_retweet"`
+	IsReply        bool      `json:"is_reply"`
+	IsQuote        bool      `json:"is_quote"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	InReplyToID    string    `json:"in_reply_to_id,omitempty"`
+	QuotedTweetID  string    `json:"quoted_tweet_id,omitempty"`
+	Hashtags       []string  `json:"hashtags"`
+	Mentions       []string  `json:"mentions"`
+	URLs           []URL     `json:"urls"`
+	Media          []Media   `json:"media"`
+}
+
+// Author is tweet author
+type Author struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// Metrics are tweet counters at the time of delivery
+type Metrics struct {
+	Likes    int `json:"likes"`
+	Retweets int `json:"retweets"`
+	Replies  int `json:"replies"`
+	Views    int `json:"views"`
+}
+
+// URL is a link of tweet
+type URL struct {
+	URL      string `json:"url"`
+	Expanded string `json:"expanded"`
+	Display  string `json:"display"`
+}
+
+// Media is a media attachment of tweet
+type Media struct {
+	Type       string `json:"type"`
+	URL        string `json:"url"`
+	PreviewURL string `json:"preview_url,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+// NewPayload creates tweet event payload
+func NewPayload(tweet *twittertimeline.Tweet) *Payload {
+	return &Payload{
+		SchemaVersion: SchemaVersion,
+		Event:         EventTweet,
+		ID:            EventTweet + "-" + tweet.ID,
+		Timestamp:     time.Now().UTC(),
+		Tweet:         newTweet(tweet),
+	}
+}
+
+// newTweet converts tweet into payload representation
+func newTweet(tweet *twittertimeline.Tweet) *Tweet {
+	t := &Tweet{
+		ID:             tweet.ID,
+		URL:            tweet.PermanentURL,
+		Text:           tweet.Text,
+		HTML:           tweet.HTML,
+		Lang:           tweet.Lang,
+		Author:         Author{ID: tweet.UserID, Username: tweet.Username},
+		Metrics:        Metrics{Likes: tweet.Likes, Retweets: tweet.Retweets, Replies: tweet.Replies, Views: tweet.Views},
+		IsRetweet:      tweet.IsRetweet,
+		IsReply:        tweet.IsReply,
+		IsQuote:        tweet.IsQuoted,
+		ConversationID: tweet.ConversationID,
+		InReplyToID:    tweet.InReplyToID,
+		Hashtags:       nonNil(tweet.Hashtags),
+		Mentions:       nonNil(tweet.Mentions),
+		URLs:           make([]URL, 0, len(tweet.URLs)),
+		Media:          make([]Media, 0, len(tweet.Media)),
+	}
+	if t.Lang == "" || t.Lang == "und" {
+		t.Lang = tweet.DetectedLang
+	}
+	if createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt); err == nil {
+		t.CreatedAt = createdAt.UTC()
+	}
+	if tweet.QuotedTweet != nil {
+		t.QuotedTweetID = tweet.QuotedTweet.ID
+	}
+	for _, u := range tweet.URLs {
+		t.URLs = append(t.URLs, URL{URL: u.Short, Expanded: u.Expanded, Display: u.Display})
+	}
+	for _, media := range tweet.Media {
+		t.Media = append(t.Media, Media{
+			Type:       media.Type,
+			URL:        media.URL,
+			PreviewURL: media.PreviewURL,
+			DurationMs: media.Duration.Milliseconds(),
+		})
+	}
+	return t
+}
+
+// nonNil returns empty slice instead of nil, so that lists are encoded as [] instead of null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// Sign returns signature of payload body with the secret for SignatureHeader
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature of payload body in constant time
+func Verify(body []byte, secret, signature string) error {
+	if !strings.HasPrefix(signature, "sha256=") || !hmac.Equal([]byte(signature), []byte(Sign(body, secret))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// NewRequest creates signed POST request delivering payload to webhook URL
+func NewRequest(url, secret string, payload *Payload) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, payload.Event)
+	req.Header.Set(VersionHeader, fmt.Sprint(payload.SchemaVersion))
+	req.Header.Set(SignatureHeader, Sign(body, secret))
+	return req, nil
+}
+
+// Parse verifies signature of payload body and decodes it.
+// Payloads of newer incompatible schema versions are rejected.
+func Parse(body []byte, secret, signature string) (*Payload, error) {
+	if err := Verify(body, secret, signature); err != nil {
+		return nil, err
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("error decoding payload: %w", err)
+	}
+	if payload.SchemaVersion < 1 || payload.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("unsupported payload schema version: %d", payload.SchemaVersion)
+	}
+	return &payload, nil
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	twittertimeline "github.com/n0madic/twitter-timeline"
+)
+
+var testTweet = &twittertimeline.Tweet{
+	ID:           "2",
+	Text:         "Hello #go",
+	Lang:         "und",
+	DetectedLang: "en",
+	CreatedAt:    "Mon Jan 01 09:30:00 +0300 2024",
+	PermanentURL: "https://x.com/alice/status/2",
+	Username:     "alice",
+	UserID:       "42",
+	Likes:        5,
+	Views:        100,
+	Hashtags:     []string{"go"},
+	URLs:         []twittertimeline.URL{{Short: "https://t.co/a", Expanded: "https://go.dev", Display: "go.dev"}},
+	Media:        []twittertimeline.Media{{Type: twittertimeline.MediaVideo, URL: "https://video.twimg.com/1.mp4", Duration: 1500 * time.Millisecond}},
+	QuotedTweet:  &twittertimeline.QuotedTweet{Tweet: twittertimeline.Tweet{ID: "1"}},
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	req, err := NewRequest("https://hooks.example/tweets", "secret", NewPayload(testTweet))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	if req.Header.Get(EventHeader) != EventTweet || req.Header.Get(VersionHeader) != "1" {
+		t.Errorf("Unexpected headers: %v", req.Header)
+	}
+	body, _ := io.ReadAll(req.Body)
+
+	payload, err := Parse(body, "secret", req.Header.Get(SignatureHeader))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	tweet := payload.Tweet
+	if payload.ID != "tweet-2" || tweet.Lang != "en" || tweet.Author != (Author{ID: "42", Username: "alice"}) ||
+		tweet.Metrics != (Metrics{Likes: 5, Views: 100}) || tweet.QuotedTweetID != "1" ||
+		!tweet.CreatedAt.Equal(time.Date(2024, 1, 1, 6, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected payload: %+v, tweet %+v", payload, tweet)
+	}
+	if len(tweet.Media) != 1 || tweet.Media[0].DurationMs != 1500 || len(tweet.URLs) != 1 || tweet.URLs[0].URL != "https://t.co/a" {
+		t.Errorf("Unexpected media or links: %+v, %+v", tweet.Media, tweet.URLs)
+	}
+
+	if _, err := Parse(body, "other", req.Header.Get(SignatureHeader)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected invalid signature for wrong secret, got %v", err)
+	}
+	body[len(body)-2] = ' '
+	if _, err := Parse(body, "secret", req.Header.Get(SignatureHeader)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected invalid signature for modified body, got %v", err)
+	}
+}
+
+func TestParseRejectsUnknownVersion(t *testing.T) {
+	body := []byte(`{"schema_version":2,"event":"tweet","id":"x","timestamp":"2024-01-01T00:00:00Z"}`)
+	if _, err := Parse(body, "secret", Sign(body, "secret")); err == nil {
+		t.Error("Expected error for unsupported schema version")
+	}
+	if err := Verify(body, "secret", "deadbeef"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected invalid signature without prefix, got %v", err)
+	}
+}
+
+// schemaObject is a subset of JSON Schema used to check payload against Schema
+type schemaObject struct {
+	Required   []string                 `json:"required"`
+	Properties map[string]*schemaObject `json:"properties"`
+	Items      *schemaObject            `json:"items"`
+	Ref        string                   `json:"$ref"`
+	Defs       map[string]*schemaObject `json:"$defs"`
+}
+
+func TestSchemaMatchesPayload(t *testing.T) {
+	var schema schemaObject
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+
+	data, _ := json.Marshal(NewPayload(testTweet))
+	var payload any
+	json.Unmarshal(data, &payload)
+
+	var check func(path string, value any, s *schemaObject)
+	check = func(path string, value any, s *schemaObject) {
+		if s.Ref != "" {
+			s = schema.Defs[s.Ref[len("#/$defs/"):]]
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			for _, key := range s.Required {
+				if _, ok := value[key]; !ok {
+					t.Errorf("Required %s.%s is missing", path, key)
+				}
+			}
+			for key, field := range value {
+				property, ok := s.Properties[key]
+				if !ok {
+					t.Errorf("Field %s.%s is not described in schema", path, key)
+					continue
+				}
+				check(path+"."+key, field, property)
+			}
+		case []any:
+			for _, item := range value {
+				check(path+"[]", item, s.Items)
+			}
+		}
+	}
+	check("payload", payload, &schema)
+}